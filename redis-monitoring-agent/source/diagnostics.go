@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Netcracker/qubership-redis/redis-monitoring-agent/internal/redact"
+	"github.com/go-redis/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+//go:generate cp ../../redis-operator/dbaas/pkg/redis/redact/redact.go internal/redact/redact.go
+
+const slowlogEntries = 128
+
+var (
+	slowlogLabels = append(append([]string{}, instanceLabels...), "command")
+	latencyLabels = append(append([]string{}, instanceLabels...), "event")
+)
+
+// SlowlogEntry is a SLOWLOG GET entry with redacted arguments.
+type SlowlogEntry struct {
+	ID       int64
+	Time     time.Time
+	Duration time.Duration
+	Command  string
+	Args     []string
+	Client   string
+}
+
+// LatencyEvent is a LATENCY LATEST entry, latencies are in milliseconds.
+type LatencyEvent struct {
+	Event  string
+	Time   time.Time
+	Latest int64
+	Max    int64
+}
+
+// diagnosticsState deduplicates slowlog entries and latency events between scrapes,
+// it is kept when the instance client is recreated.
+type diagnosticsState struct {
+	mutex *sync.Mutex
+
+	lastSlowlogID   int64
+	slowlogCount    map[string]float64
+	slowlogDuration map[string]float64
+	latencySeen     map[string]time.Time
+	latencyCount    map[string]float64
+}
+
+func newDiagnosticsState() *diagnosticsState {
+	return &diagnosticsState{
+		mutex:           &sync.Mutex{},
+		lastSlowlogID:   -1,
+		slowlogCount:    map[string]float64{},
+		slowlogDuration: map[string]float64{},
+		latencySeen:     map[string]time.Time{},
+		latencyCount:    map[string]float64{},
+	}
+}
+
+// collectDiagnostics reports new slowlog entries and latency events as structured log events,
+// and their counts per command or event as metrics.
+func (r *RedisExporter) collectDiagnostics(target *exporterTarget, labels []string, ch chan<- prometheus.Metric) {
+	state := target.diagnostics
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	entries, err := slowlogGet(target.client, slowlogEntries)
+	if err != nil {
+		r.logger.Warn(fmt.Sprintf("Failed reading slowlog of %s, err: %v", target.instance.Name, err))
+	} else {
+		r.processSlowlog(target.instance, state, entries)
+	}
+	for command, count := range state.slowlogCount {
+		sendCounter(ch, "slowlog_entries_total", slowlogLabels, count, append(labels, command)...)
+		sendCounter(ch, "slowlog_duration_seconds_total", slowlogLabels, state.slowlogDuration[command], append(labels, command)...)
+	}
+
+	events, err := latencyLatest(target.client)
+	if err != nil {
+		r.logger.Warn(fmt.Sprintf("Failed reading latency events of %s, err: %v", target.instance.Name, err))
+		return
+	}
+	for _, event := range events {
+		if state.latencySeen[event.Event] != event.Time {
+			state.latencySeen[event.Event] = event.Time
+			state.latencyCount[event.Event]++
+			r.logger.Info("Redis latency event",
+				zap.String("type", "latency"),
+				zap.String("database", target.instance.Name),
+				zap.String("event", event.Event),
+				zap.Time("time", event.Time),
+				zap.Int64("latestMs", event.Latest),
+				zap.Int64("maxMs", event.Max))
+		}
+		sendGauge(ch, "latency_latest_milliseconds", latencyLabels, float64(event.Latest), append(labels, event.Event)...)
+		sendGauge(ch, "latency_max_milliseconds", latencyLabels, float64(event.Max), append(labels, event.Event)...)
+	}
+	for event, count := range state.latencyCount {
+		sendCounter(ch, "latency_events_total", latencyLabels, count, append(labels, event)...)
+	}
+}
+
+// processSlowlog handles the entries which were not seen before, entries are returned newest first.
+func (r *RedisExporter) processSlowlog(instance RedisInstance, state *diagnosticsState, entries []SlowlogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	//IDs start from zero again after Redis restart
+	if entries[0].ID < state.lastSlowlogID {
+		state.lastSlowlogID = -1
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.ID <= state.lastSlowlogID {
+			continue
+		}
+		state.slowlogCount[entry.Command]++
+		state.slowlogDuration[entry.Command] += entry.Duration.Seconds()
+		r.logger.Info("Redis slowlog entry",
+			zap.String("type", "slowlog"),
+			zap.String("database", instance.Name),
+			zap.Int64("id", entry.ID),
+			zap.Time("time", entry.Time),
+			zap.Duration("duration", entry.Duration),
+			zap.String("command", entry.Command),
+			zap.Strings("args", entry.Args),
+			zap.String("client", entry.Client))
+	}
+	state.lastSlowlogID = entries[0].ID
+}
+
+// slowlogGet parses SLOWLOG GET: id, timestamp, duration in microseconds, arguments, client address and name.
+func slowlogGet(redisClient *redis.Client, count int) ([]SlowlogEntry, error) {
+	result, err := redisClient.Do("SLOWLOG", "GET", count).Result()
+	if err != nil {
+		return nil, err
+	}
+	items, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected SLOWLOG GET reply %T", result)
+	}
+	entries := make([]SlowlogEntry, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		id, _ := fields[0].(int64)
+		timestamp, _ := fields[1].(int64)
+		duration, _ := fields[2].(int64)
+		var args []string
+		if rawArgs, ok := fields[3].([]interface{}); ok {
+			for _, arg := range rawArgs {
+				args = append(args, fmt.Sprint(arg))
+			}
+		}
+		entry := SlowlogEntry{
+			ID:       id,
+			Time:     time.Unix(timestamp, 0),
+			Duration: time.Duration(duration) * time.Microsecond,
+		}
+		entry.Command, entry.Args = redact.Args(args)
+		if len(fields) > 4 {
+			entry.Client, _ = fields[4].(string)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// latencyLatest parses LATENCY LATEST: event name, timestamp, latest and max latency in milliseconds.
+func latencyLatest(redisClient *redis.Client) ([]LatencyEvent, error) {
+	result, err := redisClient.Do("LATENCY", "LATEST").Result()
+	if err != nil {
+		return nil, err
+	}
+	items, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected LATENCY LATEST reply %T", result)
+	}
+	events := make([]LatencyEvent, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		event := LatencyEvent{}
+		event.Event, _ = fields[0].(string)
+		timestamp, _ := fields[1].(int64)
+		event.Time = time.Unix(timestamp, 0)
+		event.Latest, _ = fields[2].(int64)
+		event.Max, _ = fields[3].(int64)
+		events = append(events, event)
+	}
+	return events, nil
+}
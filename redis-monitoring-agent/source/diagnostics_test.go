@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestProcessSlowlog(t *testing.T) {
+	r := &RedisExporter{logger: zap.NewNop()}
+	state := newDiagnosticsState()
+	instance := RedisInstance{Name: "redisdb"}
+	//entries are returned newest first
+	entry := func(id int64, command string) SlowlogEntry {
+		return SlowlogEntry{ID: id, Command: command}
+	}
+	steps := []struct {
+		name    string
+		entries []SlowlogEntry
+		want    map[string]float64
+	}{
+		{
+			name:    "First scrape",
+			entries: []SlowlogEntry{entry(1, "SET"), entry(0, "GET")},
+			want:    map[string]float64{"GET": 1, "SET": 1},
+		},
+		{
+			name:    "Seen entries are not counted again",
+			entries: []SlowlogEntry{entry(1, "SET"), entry(0, "GET")},
+			want:    map[string]float64{"GET": 1, "SET": 1},
+		},
+		{
+			name:    "New entries",
+			entries: []SlowlogEntry{entry(3, "GET"), entry(2, "GET"), entry(1, "SET")},
+			want:    map[string]float64{"GET": 3, "SET": 1},
+		},
+		{
+			name:    "Redis restart",
+			entries: []SlowlogEntry{entry(0, "SET")},
+			want:    map[string]float64{"GET": 3, "SET": 2},
+		},
+		{
+			name: "No entries",
+			want: map[string]float64{"GET": 3, "SET": 2},
+		},
+	}
+	for _, step := range steps {
+		r.processSlowlog(instance, state, step.entries)
+		if len(state.slowlogCount) != len(step.want) {
+			t.Fatalf("%s: slowlog count = %v, want %v", step.name, state.slowlogCount, step.want)
+		}
+		for command, count := range step.want {
+			if state.slowlogCount[command] != count {
+				t.Fatalf("%s: slowlog count = %v, want %v", step.name, state.slowlogCount, step.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	v12 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	metricsNamespace = "redis"
+
+	latencyPings  = 10
+	monitoringKey = "monitoring_test"
+)
+
+var (
+	instanceLabels = []string{"host", "server", "port", "replication_role", "classifier_namespace", "microservice_name"}
+	keyspaceLabels = append(append([]string{}, instanceLabels...), "database")
+	scrapeLabels   = []string{"host", "server", "port", "tls", "classifier_namespace", "microservice_name"}
+
+	// INFO fields are renamed the same way Telegraf redis input does, so dashboards and alerts keep working
+	infoRenames = map[string]string{
+		"connected_clients": "clients",
+		"uptime_in_seconds": "uptime",
+	}
+)
+
+// RedisInstance is a logical database the exporter collects metrics from.
+// The classifier of the database identifies its owner and is added to all its metrics.
+type RedisInstance struct {
+	Name                string
+	Port                string
+	TLSEnabled          bool
+	CAFile              string
+	ClassifierNamespace string
+	MicroserviceName    string
+}
+
+func (i RedisInstance) server(namespace string) string {
+	return fmt.Sprintf("%s.%s.svc", i.Name, namespace)
+}
+
+type exporterTarget struct {
+	instance RedisInstance
+	client   *redis.Client
+	// err is set when the client can't be created, e.g. the credentials or the CA certificate can't be read
+	err         error
+	diagnostics *diagnosticsState
+}
+
+func (t *exporterTarget) close() {
+	if t.client != nil {
+		_ = t.client.Close()
+	}
+}
+
+// RedisExporter collects INFO, keyspace, client and memory stats of all Redis instances on every scrape.
+// Instances are scraped concurrently and can be added or removed at any time.
+type RedisExporter struct {
+	client        client.Client
+	logger        *zap.Logger
+	namespace     string
+	scrapeTimeout time.Duration
+	targets       map[string]*exporterTarget
+	mutex         *sync.RWMutex
+}
+
+func NewRedisExporter(client client.Client, logger *zap.Logger, namespace string, scrapeTimeout time.Duration) *RedisExporter {
+	return &RedisExporter{
+		client:        client,
+		logger:        logger,
+		namespace:     namespace,
+		scrapeTimeout: scrapeTimeout,
+		targets:       map[string]*exporterTarget{},
+		mutex:         &sync.RWMutex{},
+	}
+}
+
+// Add starts collecting metrics of the instance or replaces its connection settings.
+// An instance which can't be connected to is kept and reported as failed on every scrape.
+func (r *RedisExporter) Add(instance RedisInstance) error {
+	target := &exporterTarget{instance: instance, diagnostics: newDiagnosticsState()}
+	target.client, target.err = r.newClient(instance)
+
+	r.mutex.Lock()
+	previous := r.targets[instance.Name]
+	if previous != nil {
+		//slowlog entries and latency events seen with the previous client are not reported again
+		target.diagnostics = previous.diagnostics
+	}
+	r.targets[instance.Name] = target
+	r.mutex.Unlock()
+
+	if previous != nil {
+		previous.close()
+	}
+	if target.err != nil {
+		return target.err
+	}
+	r.logger.Info(fmt.Sprintf("Collecting metrics of %s, TLS enabled: %t", instance.Name, instance.TLSEnabled))
+	return nil
+}
+
+func (r *RedisExporter) newClient(instance RedisInstance) (*redis.Client, error) {
+	passSecret := v12.Secret{}
+	secretName := instance.Name + credentialsSuffix
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: r.namespace}, &passSecret); err != nil {
+		r.logger.Error("Failed reading " + secretName + " secret")
+		return nil, err
+	}
+
+	options := &redis.Options{
+		Addr:         fmt.Sprintf("%s:%s", instance.server(r.namespace), instance.Port),
+		Password:     string(passSecret.Data[Password]),
+		DialTimeout:  r.scrapeTimeout,
+		ReadTimeout:  r.scrapeTimeout,
+		WriteTimeout: r.scrapeTimeout,
+		PoolSize:     1,
+	}
+	if instance.TLSEnabled {
+		tlsConfig, err := tlsConfig(instance.CAFile, instance.server(r.namespace))
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("Failed preparing TLS configuration of %s", instance.Name))
+			return nil, err
+		}
+		options.TLSConfig = tlsConfig
+	}
+	return redis.NewClient(options), nil
+}
+
+// tlsConfig verifies the Redis certificate with the CA, the certificate is issued for the service host name.
+func tlsConfig(caFile string, serverName string) (*tls.Config, error) {
+	if caFile == "" {
+		return nil, fmt.Errorf("TLS is enabled, but CA certificate path is not set")
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no CA certificates found in %s", caFile)
+	}
+	return &tls.Config{
+		RootCAs:    pool,
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// Refresh reconnects to the monitored instance, e.g. when its password is changed.
+// It returns false if the instance is not monitored.
+func (r *RedisExporter) Refresh(name string) (bool, error) {
+	r.mutex.RLock()
+	target, ok := r.targets[name]
+	r.mutex.RUnlock()
+
+	if !ok {
+		return false, nil
+	}
+	return true, r.Add(target.instance)
+}
+
+// Remove stops collecting metrics of the instance.
+func (r *RedisExporter) Remove(name string) {
+	r.mutex.Lock()
+	target := r.targets[name]
+	delete(r.targets, name)
+	r.mutex.Unlock()
+
+	if target != nil {
+		target.close()
+		r.logger.Info(fmt.Sprintf("Stopped collecting metrics of %s", name))
+	}
+}
+
+// Describe sends no descriptors, the set of metrics depends on the instances and their INFO output.
+func (r *RedisExporter) Describe(chan<- *prometheus.Desc) {
+}
+
+func (r *RedisExporter) Collect(ch chan<- prometheus.Metric) {
+	r.mutex.RLock()
+	targets := make([]*exporterTarget, 0, len(r.targets))
+	for _, target := range r.targets {
+		targets = append(targets, target)
+	}
+	r.mutex.RUnlock()
+
+	wg := sync.WaitGroup{}
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target *exporterTarget) {
+			defer wg.Done()
+			r.collectInstance(target, ch)
+		}(target)
+	}
+	wg.Wait()
+}
+
+// collectInstance reports redis_scrape_success and redis_scrape_duration_seconds for every instance,
+// so an instance which can't be reached is visible in the metrics and not just missing.
+func (r *RedisExporter) collectInstance(target *exporterTarget, ch chan<- prometheus.Metric) {
+	server := target.instance.server(r.namespace)
+	instance := target.instance
+	labels := []string{r.namespace, server, instance.Port, "", instance.ClassifierNamespace, instance.MicroserviceName}
+	scrapeLabelValues := []string{r.namespace, server, instance.Port, strconv.FormatBool(instance.TLSEnabled),
+		instance.ClassifierNamespace, instance.MicroserviceName}
+
+	start := time.Now()
+	err := target.err
+	var info string
+	if err == nil {
+		info, err = target.client.Info().Result()
+	}
+	if err != nil {
+		r.logger.Warn(fmt.Sprintf("Failed collecting metrics of %s, err: %v", target.instance.Name, err))
+		sendGauge(ch, "scrape_success", scrapeLabels, 0, scrapeLabelValues...)
+		sendGauge(ch, "scrape_duration_seconds", scrapeLabels, time.Since(start).Seconds(), scrapeLabelValues...)
+		sendGauge(ch, "avg_latency", instanceLabels, -1, labels...)
+		return
+	}
+	defer func() {
+		sendGauge(ch, "scrape_success", scrapeLabels, 1, scrapeLabelValues...)
+		sendGauge(ch, "scrape_duration_seconds", scrapeLabels, time.Since(start).Seconds(), scrapeLabelValues...)
+	}()
+
+	fields, keyspace := parseInfo(info)
+	labels[3] = fields["role"]
+
+	for name, value := range fields {
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			if renamed, ok := infoRenames[name]; ok {
+				name = renamed
+			}
+			sendGauge(ch, name, instanceLabels, number, labels...)
+		}
+	}
+
+	hits, _ := strconv.ParseFloat(fields["keyspace_hits"], 64)
+	misses, _ := strconv.ParseFloat(fields["keyspace_misses"], 64)
+	if hits+misses > 0 {
+		sendGauge(ch, "keyspace_hitrate", instanceLabels, hits/(hits+misses), labels...)
+	}
+
+	for database, stats := range keyspace {
+		for name, value := range stats {
+			sendGauge(ch, "keyspace_"+name, keyspaceLabels, value, append(labels, database)...)
+		}
+	}
+
+	r.collectOperations(target.client, labels, ch)
+	r.collectDiagnostics(target, labels, ch)
+}
+
+// collectOperations measures the average PING latency in milliseconds and checks SET, GET and DEL commands.
+func (r *RedisExporter) collectOperations(redisClient *redis.Client, labels []string, ch chan<- prometheus.Metric) {
+	var total time.Duration
+	latency := float64(-1)
+	for i := 0; i < latencyPings; i++ {
+		start := time.Now()
+		if err := redisClient.Ping().Err(); err != nil {
+			total = -1
+			break
+		}
+		total += time.Since(start)
+	}
+	if total >= 0 {
+		latency = float64(total.Microseconds()) / 1000 / latencyPings
+	}
+	sendGauge(ch, "avg_latency", instanceLabels, latency, labels...)
+
+	setOp := redisClient.Set(monitoringKey, "1", 0).Err() == nil
+	getOp := redisClient.Get(monitoringKey).Val() == "1"
+	delOp := redisClient.Del(monitoringKey).Val() == 1
+	sendGauge(ch, "set_op", instanceLabels, boolToFloat(setOp), labels...)
+	sendGauge(ch, "get_op", instanceLabels, boolToFloat(getOp), labels...)
+	sendGauge(ch, "del_op", instanceLabels, boolToFloat(delOp), labels...)
+}
+
+// parseInfo returns INFO fields and keyspace stats per database, e.g. db0:keys=1,expires=0,avg_ttl=0
+func parseInfo(info string) (map[string]string, map[string]map[string]float64) {
+	fields := map[string]string{}
+	keyspace := map[string]map[string]float64{}
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		if strings.HasPrefix(name, "db") && strings.Contains(value, "keys=") {
+			stats := map[string]float64{}
+			for _, pair := range strings.Split(value, ",") {
+				key, number, _ := strings.Cut(pair, "=")
+				if parsed, err := strconv.ParseFloat(number, 64); err == nil {
+					stats[key] = parsed
+				}
+			}
+			keyspace[name] = stats
+			continue
+		}
+		fields[name] = value
+	}
+	return fields, keyspace
+}
+
+func sendGauge(ch chan<- prometheus.Metric, name string, labelNames []string, value float64, labelValues ...string) {
+	sendMetric(ch, prometheus.GaugeValue, name, labelNames, value, labelValues...)
+}
+
+func sendCounter(ch chan<- prometheus.Metric, name string, labelNames []string, value float64, labelValues ...string) {
+	sendMetric(ch, prometheus.CounterValue, name, labelNames, value, labelValues...)
+}
+
+func sendMetric(ch chan<- prometheus.Metric, valueType prometheus.ValueType, name string, labelNames []string, value float64, labelValues ...string) {
+	desc := prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", name), "Redis "+name, labelNames, nil)
+	metric, err := prometheus.NewConstMetric(desc, valueType, value, labelValues...)
+	if err == nil {
+		ch <- metric
+	}
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+// Package redact removes keys and values from the arguments of Redis commands, so the diagnostics don't expose
+// the data of tenants. The monitoring agent can't import the operator module, so it keeps a copy of this file made
+// by go generate in redis-monitoring-agent/source. The package must not import anything but the standard library.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces the redacted arguments.
+const Placeholder = "?"
+
+var (
+	// Redis replaces the tail of long commands with "... (N more arguments)", it contains no data
+	truncatedArgs = regexp.MustCompile(`^\.\.\. \(\d+ more arguments\)$`)
+
+	// the second argument of these commands is a subcommand, e.g. CONFIG SET, it is kept in the redacted command
+	subcommands = map[string]bool{
+		"ACL": true, "CLIENT": true, "CLUSTER": true, "COMMAND": true, "CONFIG": true, "DEBUG": true,
+		"FUNCTION": true, "LATENCY": true, "MEMORY": true, "MODULE": true, "OBJECT": true, "PUBSUB": true,
+		"SCRIPT": true, "SLOWLOG": true, "XGROUP": true, "XINFO": true,
+	}
+)
+
+// Args returns the command name, with the subcommand if the command has one, and the arguments
+// with keys and values replaced by Placeholder.
+func Args(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	command := strings.ToUpper(args[0])
+	redacted := []string{command}
+	for i, arg := range args[1:] {
+		switch {
+		case i == 0 && subcommands[command]:
+			command = command + " " + strings.ToUpper(arg)
+			redacted = append(redacted, strings.ToUpper(arg))
+		case truncatedArgs.MatchString(arg):
+			redacted = append(redacted, arg)
+		default:
+			redacted = append(redacted, Placeholder)
+		}
+	}
+	return command, redacted
+}
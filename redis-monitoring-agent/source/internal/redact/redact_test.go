@@ -0,0 +1,68 @@
+package redact
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// operatorRedact is the original of redact.go, the copy is made by go generate in redis-monitoring-agent/source.
+const operatorRedact = "../../../../redis-operator/dbaas/pkg/redis/redact/redact.go"
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantCommand string
+		wantArgs    []string
+	}{
+		{name: "No arguments"},
+		{name: "Command only", args: []string{"ping"}, wantCommand: "PING", wantArgs: []string{"PING"}},
+		{
+			name:        "Key and value",
+			args:        []string{"SET", "user:1", "secret", "EX", "10"},
+			wantCommand: "SET",
+			wantArgs:    []string{"SET", "?", "?", "?", "?"},
+		},
+		{
+			name:        "Subcommand",
+			args:        []string{"config", "set", "requirepass", "secret"},
+			wantCommand: "CONFIG SET",
+			wantArgs:    []string{"CONFIG", "SET", "?", "?"},
+		},
+		{
+			name:        "Truncated arguments",
+			args:        []string{"MSET", "a", "1", "... (30 more arguments)"},
+			wantCommand: "MSET",
+			wantArgs:    []string{"MSET", "?", "?", "... (30 more arguments)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, args := Args(tt.args)
+			if command != tt.wantCommand || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args() = %s, %v, want %s, %v", command, args, tt.wantCommand, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// TestOperatorCopy fails when redact.go is changed here instead of the operator or is not regenerated after
+// the change of the operator.
+func TestOperatorCopy(t *testing.T) {
+	original, err := os.ReadFile(operatorRedact)
+	if os.IsNotExist(err) {
+		t.Skip("the operator sources are not available")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied, err := os.ReadFile("redact.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original, copied) {
+		t.Errorf("redact.go differs from redis-operator/dbaas/pkg/redis/redact/redact.go, run go generate in redis-monitoring-agent/source")
+	}
+}
@@ -2,9 +2,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	v13 "k8s.io/api/apps/v1"
 	v12 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,19 +32,49 @@ func main() {
 	//cmdMonitoringCommand := getEnv("CMD_MONITORING_COMMAND", "/sbin/tini -- telegraf")
 	cmdMonitoringCommand := getEnv("CMD_MONITORING_COMMAND", "env && ls && echo")
 	redisPort := getEnv("REDIS_PORT", "6379")
-	if namespace == "" || telegrafConfConfigMapName == "" {
+	//CA certificate mounted to the agent, Redis deployments refer to the path in their own pods
+	caFile := getEnv("TLS_ROOTCERT", "")
+	metricsPort := getEnv("METRICS_PORT", "9121")
+	scrapeTimeout := time.Duration(getEnvAsInt("SCRAPE_TIMEOUT_SECONDS", 10)) * time.Second
+	telegrafEnabled := getEnvAsBool("TELEGRAF_ENABLED", false)
+	if namespace == "" || (telegrafEnabled && telegrafConfConfigMapName == "") {
 		panic("NAMESPACE or TELEGRAF_CONF_CONFIGMAP variable not set")
 	}
 
 	clientSet, client := GetClient(logger)
 
-	telegrafConfConfigMap := v12.ConfigMap{}
-	getTelegrafConfigMapErr := client.Get(context.TODO(), types.NamespacedName{Name: telegrafConfConfigMapName, Namespace: namespace}, &telegrafConfConfigMap)
-	core.PanicError(getTelegrafConfigMapErr, logger.Error, "Telegraf configuration map not found: "+telegrafConfConfigMapName)
+	exporter := NewRedisExporter(client, logger, namespace, scrapeTimeout)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporter)
 
-	logger.Info("Start watching...")
+	//Telegraf is an optional output, it is started only to send metrics to InfluxDB
+	var monitoringService *TelegrafMonitoringService
+	if telegrafEnabled {
+		telegrafConfConfigMap := v12.ConfigMap{}
+		getTelegrafConfigMapErr := client.Get(context.TODO(), types.NamespacedName{Name: telegrafConfConfigMapName, Namespace: namespace}, &telegrafConfConfigMap)
+		core.PanicError(getTelegrafConfigMapErr, logger.Error, "Telegraf configuration map not found: "+telegrafConfConfigMapName)
+
+		monitoringService = NewTelegrafProcessService(cmdMonitoringCommand, client, logger, namespace, &telegrafConfConfigMap)
+		registry.MustRegister(
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name: "redis_monitoring_telegraf_restarts_total",
+				Help: "Restarts of the crashed Telegraf process",
+			}, func() float64 { return float64(monitoringService.Health().Restarts) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "redis_monitoring_telegraf_running",
+				Help: "Whether the Telegraf process is running",
+			}, func() float64 { return boolToFloat(monitoringService.Health().Running) }),
+		)
+	}
 
-	monitoringService := NewTelegrafProcessService(cmdMonitoringCommand, client, logger, namespace, &telegrafConfConfigMap)
+	http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	http.HandleFunc("/health", healthHandler(monitoringService))
+	go func() {
+		logger.Info("Serving metrics and health on :" + metricsPort)
+		core.PanicError(http.ListenAndServe(":"+metricsPort, nil), logger.Error, "Metrics server failed")
+	}()
+
+	logger.Info("Start watching...")
 
 	//Watch redis DBs list events and update telegraf configuration
 	stopper := make(chan struct{})
@@ -46,41 +84,173 @@ func main() {
 		informers.WithNamespace(namespace),
 		informers.WithTweakListOptions(func(ls *v1.ListOptions) { ls.LabelSelector = redisInstancesLabelSelector }))
 	informer := factory.Apps().V1().Deployments().Informer()
+	startMonitoring := func(instance RedisInstance) {
+		addErr := exporter.Add(instance)
+		HandleError(addErr, logger.Error, "Failed to start collecting metrics", true)
+
+		if monitoringService != nil {
+			startErr := monitoringService.Start(instance)
+			HandleError(startErr, logger.Error, "Failed to start monitoring process", true)
+		}
+	}
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			logger.Info("Redis DB is added. Starting monitoring...")
-			instance := obj.(*v13.Deployment)
-			redisEnv := instance.Spec.Template.Spec.Containers[0].Env
-			var caFile string
-			var tlsEnabled bool
-			for _, env := range redisEnv {
-				if env.Name == "REDIS_PORT" {
-					redisPort = env.Value
-				}
-				if env.Name == "TLS_ENABLED" {
-					tlsEnabled, _ = strconv.ParseBool(env.Value)
-				}
-				if env.Name == "TLS_ROOTCERT" {
-					caFile = env.Value
-				}
+			instance := redisInstance(obj.(*v13.Deployment), redisPort, caFile)
+			logger.Info(fmt.Sprintf("Redis DB %s is added. Starting monitoring...", instance.Name))
+			startMonitoring(instance)
+		},
+		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+			oldInstance := redisInstance(oldObj.(*v13.Deployment), redisPort, caFile)
+			newInstance := redisInstance(newObj.(*v13.Deployment), redisPort, caFile)
+			//Deployment status is updated on every pod change, monitoring is refreshed only if connection settings are changed
+			if oldInstance == newInstance {
+				return
 			}
-			startErr := monitoringService.Start(instance.Name, redisPort, tlsEnabled, caFile)
-			HandleError(startErr, logger.Error, "Failed to start monitoring process", true)
+			logger.Info(fmt.Sprintf("Redis DB %s is updated. Refreshing monitoring...", newInstance.Name))
+			startMonitoring(newInstance)
 		},
-		//UpdateFunc: func(oldObj interface{}, newObj interface{}) {
-		//	logger.Info("Redis DB is updated. Refreshing monitoring...")
-		//	instance := newObj.(*v13.Deployment)
-		//
-		//	startErr := monitoringService.Start(instance.Name)
-		//	HandleError(startErr, logger.Error, "Failed to start monitoring process", true)
-		//},
 		DeleteFunc: func(obj interface{}) {
-			logger.Info("Redis DB is deleted. Stopping monitoring...")
-			instance := obj.(*v13.Deployment)
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			instance, ok := obj.(*v13.Deployment)
+			if !ok {
+				return
+			}
+			logger.Info(fmt.Sprintf("Redis DB %s is deleted. Stopping monitoring...", instance.Name))
+
+			exporter.Remove(instance.Name)
 
-			stopErr := monitoringService.Stop(instance.Name)
-			HandleError(stopErr, logger.Error, "Failed to stop monitoring process", true)
+			if monitoringService != nil {
+				stopErr := monitoringService.Stop(instance.Name)
+				HandleError(stopErr, logger.Error, "Failed to stop monitoring process", true)
+			}
 		},
 	})
+
+	//Watch credentials of the monitored DBs and the telegraf configuration, refresh only affected instances.
+	//Only the secrets of the logical databases are watched, the DBaaS adapter labels them with the database name
+	debouncer := NewDebouncer(time.Second * duration)
+	watchFactory := informers.NewSharedInformerFactoryWithOptions(clientSet,
+		0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(ls *v1.ListOptions) { ls.LabelSelector = logicalDatabaseLabel }))
+	secretChanged := func(secret *v12.Secret) {
+		name, found := strings.CutSuffix(secret.Name, credentialsSuffix)
+		if !found || secret.Labels[logicalDatabaseLabel] != name {
+			return
+		}
+		debouncer.Trigger("secret/"+name, func() {
+			refreshed, refreshErr := exporter.Refresh(name)
+			HandleError(refreshErr, logger.Error, "Failed to refresh collecting metrics", true)
+			if refreshed {
+				logger.Info(fmt.Sprintf("Credentials of Redis DB %s are changed. Monitoring is refreshed", name))
+			}
+			if monitoringService != nil {
+				monitoringService.Reload(name)
+			}
+		})
+	}
+	watchFactory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			secretChanged(obj.(*v12.Secret))
+		},
+		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+			if !reflect.DeepEqual(oldObj.(*v12.Secret).Data, newObj.(*v12.Secret).Data) {
+				secretChanged(newObj.(*v12.Secret))
+			}
+		},
+		//the stale credentials are dropped, the instance is reported as failed until the secret is created again
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*v12.Secret); ok {
+				secretChanged(secret)
+			}
+		},
+	})
+	watchFactory.Start(stopper)
+
+	if monitoringService != nil {
+		configMapFactory := informers.NewSharedInformerFactoryWithOptions(clientSet,
+			0,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(ls *v1.ListOptions) { ls.FieldSelector = "metadata.name=" + telegrafConfConfigMapName }))
+		configMapFactory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+				configMap := newObj.(*v12.ConfigMap)
+				if reflect.DeepEqual(oldObj.(*v12.ConfigMap).Data, configMap.Data) {
+					return
+				}
+				logger.Info("Telegraf configuration is changed. Refreshing monitoring...")
+				monitoringService.UpdateConfigMap(configMap.DeepCopy())
+			},
+		})
+		configMapFactory.Start(stopper)
+	}
+
 	informer.Run(stopper)
 }
+
+// redisInstance reads connection settings of the Redis DB from its deployment.
+// The default port is used when the deployment does not set REDIS_PORT, REDIS_TLS_PORT is used instead
+// of it when TLS is enabled,
+// the agent CA certificate, if it is mounted, is used instead of TLS_ROOTCERT of the deployment.
+// The classifier is read from the labels set by the DBaaS adapter.
+func redisInstance(deployment *v13.Deployment, defaultPort string, caFile string) RedisInstance {
+	instance := RedisInstance{
+		Name:                deployment.Name,
+		Port:                defaultPort,
+		ClassifierNamespace: deployment.Labels[classifierNamespaceLabel],
+		MicroserviceName:    deployment.Labels[microserviceNameLabel],
+	}
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return instance
+	}
+	var tlsPort string
+	for _, env := range deployment.Spec.Template.Spec.Containers[0].Env {
+		switch env.Name {
+		case "REDIS_PORT":
+			instance.Port = env.Value
+		case "REDIS_TLS_PORT":
+			tlsPort = env.Value
+		case "TLS_ENABLED":
+			instance.TLSEnabled, _ = strconv.ParseBool(env.Value)
+		case "TLS_ROOTCERT":
+			instance.CAFile = env.Value
+		}
+	}
+	if instance.TLSEnabled && tlsPort != "" {
+		instance.Port = tlsPort
+	}
+	if caFile != "" {
+		instance.CAFile = caFile
+	}
+	return instance
+}
+
+// healthHandler is used by readiness and liveness probes. The agent is unhealthy when Telegraf keeps crashing,
+// the restart of the pod is the last resort after the restarts with backoff did not help.
+func healthHandler(monitoringService *TelegrafMonitoringService) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		health := struct {
+			Status   string         `json:"status"`
+			Telegraf *ProcessHealth `json:"telegraf,omitempty"`
+		}{Status: "UP"}
+
+		if monitoringService != nil {
+			telegrafHealth := monitoringService.Health()
+			health.Telegraf = &telegrafHealth
+			if !telegrafHealth.Healthy() {
+				health.Status = "DOWN"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if health.Status != "UP" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(health)
+	}
+}
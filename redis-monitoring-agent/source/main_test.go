@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	v13 "k8s.io/api/apps/v1"
+	v12 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRedisInstance(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    []v12.EnvVar
+		caFile string
+		want   RedisInstance
+	}{
+		{
+			name: "Default port",
+			want: RedisInstance{Name: "redis-db", Port: "6379"},
+		},
+		{
+			name: "Port of the deployment",
+			env:  []v12.EnvVar{{Name: "REDIS_PORT", Value: "6380"}},
+			want: RedisInstance{Name: "redis-db", Port: "6380"},
+		},
+		{
+			name: "TLS port",
+			env: []v12.EnvVar{
+				{Name: "REDIS_PORT", Value: "6379"},
+				{Name: "TLS_ENABLED", Value: "true"},
+				{Name: "TLS_ROOTCERT", Value: "/usr/ssl/ca.crt"},
+				{Name: "REDIS_TLS_PORT", Value: "6390"},
+			},
+			want: RedisInstance{Name: "redis-db", Port: "6390", TLSEnabled: true, CAFile: "/usr/ssl/ca.crt"},
+		},
+		{
+			name: "TLS port of the disabled TLS",
+			env: []v12.EnvVar{
+				{Name: "REDIS_PORT", Value: "6379"},
+				{Name: "TLS_ENABLED", Value: "false"},
+				{Name: "REDIS_TLS_PORT", Value: "6390"},
+			},
+			want: RedisInstance{Name: "redis-db", Port: "6379"},
+		},
+		{
+			name:   "TLS without the TLS port",
+			env:    []v12.EnvVar{{Name: "REDIS_PORT", Value: "6379"}, {Name: "TLS_ENABLED", Value: "true"}},
+			caFile: "/tls/ca.crt",
+			want:   RedisInstance{Name: "redis-db", Port: "6379", TLSEnabled: true, CAFile: "/tls/ca.crt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &v13.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "redis-db"},
+				Spec: v13.DeploymentSpec{Template: v12.PodTemplateSpec{Spec: v12.PodSpec{
+					Containers: []v12.Container{{Name: "redis", Env: tt.env}},
+				}}},
+			}
+			if got := redisInstance(deployment, "6379", tt.caFile); got != tt.want {
+				t.Errorf("redisInstance() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
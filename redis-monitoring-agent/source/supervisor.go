@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	minRestartBackoff = time.Second
+	maxRestartBackoff = 5 * time.Minute
+	// a process which works longer than that is considered healthy, its crashes in a row are reset
+	// and the next crash restarts it with the minimal backoff
+	stableRunDuration = time.Minute
+	// the supervisor is reported unhealthy after that many crashes in a row
+	maxCrashesInRow = 5
+)
+
+// ProcessHealth is the state of the supervised process reported by the health endpoint.
+type ProcessHealth struct {
+	Enabled       bool      `json:"enabled"`
+	Running       bool      `json:"running"`
+	Restarts      int       `json:"restarts"`
+	CrashesInRow  int       `json:"crashesInRow"`
+	LastExitError string    `json:"lastExitError,omitempty"`
+	LastExitTime  time.Time `json:"lastExitTime,omitempty"`
+}
+
+// Healthy reports false when the process should run, but keeps crashing.
+func (h ProcessHealth) Healthy() bool {
+	return !h.Enabled || h.CrashesInRow < maxCrashesInRow
+}
+
+// ProcessSupervisor keeps the process running and restarts it with exponential backoff when it exits.
+type ProcessSupervisor struct {
+	processMaster *ProcessMaster
+	workerName    string
+	logger        *zap.Logger
+	mutex         *sync.Mutex
+
+	cmd        string
+	args       []string
+	enabled    bool
+	generation int
+	startTime  time.Time
+	backoff    time.Duration
+	health     ProcessHealth
+
+	// now and startWorker are replaced by the tests
+	now         func() time.Time
+	startWorker func(generation int) error
+}
+
+func NewProcessSupervisor(processMaster *ProcessMaster, workerName string, logger *zap.Logger) *ProcessSupervisor {
+	s := &ProcessSupervisor{
+		processMaster: processMaster,
+		workerName:    workerName,
+		logger:        logger,
+		mutex:         &sync.Mutex{},
+		backoff:       minRestartBackoff,
+		now:           time.Now,
+	}
+	s.startWorker = s.startProcessWorker
+	return s
+}
+
+// Start runs the process and keeps it running until Stop is called. The failed start is returned
+// and retried with backoff the same way as the exit of the process.
+func (s *ProcessSupervisor) Start(cmd string, args ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.cmd = cmd
+	s.args = args
+	s.enabled = true
+	s.health.Enabled = true
+	s.health.CrashesInRow = 0
+	s.backoff = minRestartBackoff
+	s.generation++
+	err := s.start(s.generation)
+	if err != nil {
+		go s.exited(s.generation, err)
+	}
+	return err
+}
+
+// Stop stops the process, it is not restarted any more.
+func (s *ProcessSupervisor) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.enabled = false
+	s.health = ProcessHealth{Restarts: s.health.Restarts, LastExitError: s.health.LastExitError, LastExitTime: s.health.LastExitTime}
+	//the exit of the stopped process belongs to the previous generation and does not cause a restart
+	s.generation++
+	return s.processMaster.DelWorker(s.workerName)
+}
+
+func (s *ProcessSupervisor) Health() ProcessHealth {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	//the process recovered after crashes, it is not waited to exit to become healthy
+	if s.health.Running && s.health.CrashesInRow > 0 && s.now().Sub(s.startTime) >= stableRunDuration {
+		s.health.CrashesInRow = 0
+		s.backoff = minRestartBackoff
+	}
+	return s.health
+}
+
+func (s *ProcessSupervisor) start(generation int) error {
+	s.startTime = s.now()
+	err := s.startWorker(generation)
+	s.health.Running = err == nil
+	return err
+}
+
+func (s *ProcessSupervisor) startProcessWorker(generation int) error {
+	return s.processMaster.
+		GetWorker(s.workerName).
+		Start(
+			s.cmd,
+			s.processMaster.running,
+			func(id string, err error) error {
+				//handled asynchronously, the callback can be called while the worker is deleted under the lock
+				go s.exited(generation, err)
+				return s.processMaster.finish(id, err)
+			},
+			s.args...)
+}
+
+func (s *ProcessSupervisor) exited(generation int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if generation != s.generation || !s.enabled {
+		return
+	}
+
+	s.health.Running = false
+	s.health.LastExitTime = s.now()
+	s.health.LastExitError = "exited without error"
+	if err != nil {
+		s.health.LastExitError = err.Error()
+	}
+	if s.now().Sub(s.startTime) >= stableRunDuration {
+		s.backoff = minRestartBackoff
+		s.health.CrashesInRow = 0
+	}
+	s.health.CrashesInRow++
+
+	delay := s.backoff
+	s.backoff = min(s.backoff*2, maxRestartBackoff)
+	s.logger.Warn(fmt.Sprintf("Process %s exited: %s. Restarting in %s", s.workerName, s.health.LastExitError, delay))
+
+	time.AfterFunc(delay, func() {
+		s.restart(generation)
+	})
+}
+
+func (s *ProcessSupervisor) restart(generation int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if generation != s.generation || !s.enabled {
+		return
+	}
+	s.health.Restarts++
+	s.logger.Info(fmt.Sprintf("Restarting process %s, restart count: %d", s.workerName, s.health.Restarts))
+	if startErr := s.start(generation); startErr != nil {
+		s.logger.Error(fmt.Sprintf("Failed restarting process %s, err: %v", s.workerName, startErr))
+		//the failed start is handled as an exit to keep retrying with backoff
+		go s.exited(generation, startErr)
+	}
+}
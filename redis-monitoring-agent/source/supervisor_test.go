@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestSupervisor(start func() error) (*ProcessSupervisor, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	s := NewProcessSupervisor(nil, "test", zap.NewNop())
+	s.now = clock.Now
+	s.startWorker = func(int) error { return start() }
+	return s, clock
+}
+
+func TestProcessSupervisorRecovers(t *testing.T) {
+	s, clock := newTestSupervisor(func() error { return nil })
+	if err := s.Start("telegraf"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	generation := s.generation
+	for i := 0; i < maxCrashesInRow; i++ {
+		s.exited(generation, errors.New("crashed"))
+	}
+	if health := s.Health(); health.Healthy() || health.Running {
+		t.Fatalf("Health() = %+v after %d crashes, want unhealthy and not running", health, maxCrashesInRow)
+	}
+
+	s.restart(generation)
+	if health := s.Health(); health.Healthy() || !health.Running {
+		t.Fatalf("Health() = %+v right after the restart, want unhealthy and running", health)
+	}
+
+	clock.Advance(stableRunDuration)
+	health := s.Health()
+	if !health.Healthy() || !health.Running || health.CrashesInRow != 0 {
+		t.Errorf("Health() = %+v after a stable run, want healthy and running", health)
+	}
+	if s.backoff != minRestartBackoff {
+		t.Errorf("backoff = %s after a stable run, want %s", s.backoff, minRestartBackoff)
+	}
+}
+
+func TestProcessSupervisorCrashesInRow(t *testing.T) {
+	tests := []struct {
+		name        string
+		runDuration time.Duration
+		want        int
+	}{
+		{name: "Crash right after the start is counted", runDuration: time.Second, want: 2},
+		{name: "Crash after a stable run starts a new count", runDuration: stableRunDuration, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, clock := newTestSupervisor(func() error { return nil })
+			if err := s.Start("telegraf"); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			generation := s.generation
+			s.exited(generation, errors.New("crashed"))
+			s.restart(generation)
+			clock.Advance(tt.runDuration)
+			s.exited(generation, errors.New("crashed"))
+			if got := s.Health().CrashesInRow; got != tt.want {
+				t.Errorf("CrashesInRow = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessSupervisorRetriesFailedStart(t *testing.T) {
+	starts := make(chan struct{}, 2)
+	failures := 1
+	var mutex sync.Mutex
+	s, _ := newTestSupervisor(func() error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		starts <- struct{}{}
+		if failures > 0 {
+			failures--
+			return errors.New("no such file")
+		}
+		return nil
+	})
+	if err := s.Start("telegraf"); err == nil {
+		t.Fatal("Start() error = nil, want the start error")
+	}
+	<-starts
+	select {
+	case <-starts:
+	case <-time.After(minRestartBackoff + 5*time.Second):
+		t.Fatal("the failed start is not retried")
+	}
+	health := s.Health()
+	if !health.Running || health.Restarts != 1 || health.CrashesInRow != 1 {
+		t.Errorf("Health() = %+v after the retried start, want running with 1 restart", health)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/adwpc/pagent"
 	"go.uber.org/zap"
 	v12 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,17 +25,49 @@ const (
 	redisTelegrafConfigMapOutputsKey = "telegraf-outputs"
 	redisTelegrafConfigMapInputsKey  = "telegraf-inputs"
 
-	envCredentialsPrefix  = "TELEGRAF_REDIS_PREFIX_"
-	envDBName             = envCredentialsPrefix + "DBNAME"
-	envDBService          = envCredentialsPrefix + "DBSERVICE"
-	envDBPort             = envCredentialsPrefix + "DBPORT"
-	envDBPass             = envCredentialsPrefix + "DBPASS"
+	envCredentialsPrefix = "TELEGRAF_REDIS_PREFIX_"
+	envDBName            = envCredentialsPrefix + "DBNAME"
+	envDBService         = envCredentialsPrefix + "DBSERVICE"
+	envDBPort            = envCredentialsPrefix + "DBPORT"
+	envDBPass            = envCredentialsPrefix + "DBPASS"
+	envDBTLSCA           = envCredentialsPrefix + "DBTLSCA"
+	envDBTLSEnabled      = envCredentialsPrefix + "DBTLSENABLED"
+	envDBClassifierNS    = envCredentialsPrefix + "DBCLASSIFIERNAMESPACE"
+	envDBMicroservice    = envCredentialsPrefix + "DBMICROSERVICENAME"
 
-	Password = "password"
+	//labels set by the DBaaS adapter on the objects of the logical database
+	classifierNamespaceLabel = "dbaas.netcracker.com/namespace"
+	microserviceNameLabel    = "dbaas.netcracker.com/microservice-name"
+	logicalDatabaseLabel     = "dbaas.netcracker.com/logical-database"
+
+	Password          = "password"
+	credentialsSuffix = "-credentials"
 
 	duration = time.Duration(5)
 )
 
+// shellQuote quotes the value for sh, single quotes inside the value are closed, escaped and opened again
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// writePrivateFile replaces the content of the file, the file is created with owner-only permissions
+func writePrivateFile(path string, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err = f.Chmod(0600); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err = f.WriteString(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 // Simple helper function to read an environment or return a default value
 func getEnv(key string, defaultVal string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -80,6 +113,17 @@ func PanicError(err error, log func(msg string, fields ...zap.Field), message st
 	}
 }
 
+// removeLinesWith drops the template lines which contain the variable, e.g. tls_ca of a plain text instance
+func removeLinesWith(template string, variable string) string {
+	var lines []string
+	for _, line := range strings.Split(template, "\n") {
+		if !strings.Contains(line, variable) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func GetClient(logger *zap.Logger) (kubernetes.Interface, client.Client) {
 	restConfig := config.GetConfigOrDie()
 
@@ -92,6 +136,36 @@ func GetClient(logger *zap.Logger) (kubernetes.Interface, client.Client) {
 	return clientset, cl
 }
 
+// Debouncer runs the function once per key after the events for the key stop coming for the delay
+type Debouncer struct {
+	delay  time.Duration
+	timers map[string]*time.Timer
+	mutex  *sync.Mutex
+}
+
+func NewDebouncer(delay time.Duration) *Debouncer {
+	return &Debouncer{
+		delay:  delay,
+		timers: map[string]*time.Timer{},
+		mutex:  &sync.Mutex{},
+	}
+}
+
+func (d *Debouncer) Trigger(key string, f func()) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if timer, ok := d.timers[key]; ok {
+		timer.Stop()
+	}
+	d.timers[key] = time.AfterFunc(d.delay, func() {
+		d.mutex.Lock()
+		delete(d.timers, key)
+		d.mutex.Unlock()
+		f()
+	})
+}
+
 type ProcessMaster struct {
 	pagent.Master
 	logger *zap.Logger
@@ -119,9 +193,9 @@ func NewTelegrafProcessService(cmd string, client client.Client, logger *zap.Log
 		TelegrafProcessArgs:    cmd,
 		client:                 client,
 		logger:                 logger,
-		processMaster:          NewProcessMaster(logger),
-		workerName:             "monitoring",
+		supervisor:             NewProcessSupervisor(NewProcessMaster(logger), "monitoring", logger),
 		confFullPath:           "/tmp/telegraf.conf",
+		envFullPath:            "/tmp/telegraf.env",
 		namespace:              namespace,
 		redisConf:              map[string]RedisConf{},
 		redisTelegrafConfigMap: rtcm,
@@ -137,18 +211,20 @@ func NewTelegrafProcessService(cmd string, client client.Client, logger *zap.Log
 }
 
 type RedisConf struct {
-	port       string
-	tlsEnabled bool
-	caFile     string
+	port                string
+	tlsEnabled          bool
+	caFile              string
+	classifierNamespace string
+	microserviceName    string
 }
 
 type TelegrafMonitoringService struct {
 	TelegrafProcessArgs    string
 	client                 client.Client
 	logger                 *zap.Logger
-	processMaster          *ProcessMaster
-	workerName             string
+	supervisor             *ProcessSupervisor
 	confFullPath           string
+	envFullPath            string
 	namespace              string
 	timer                  *time.Timer
 	redisConf              map[string]RedisConf
@@ -158,20 +234,19 @@ type TelegrafMonitoringService struct {
 
 func (r *TelegrafMonitoringService) startProcess() error {
 	cmd := "sh"
-	resultStr := r.TelegrafProcessArgs + " -config " + r.confFullPath
+	//the passwords are exported only to the shell and Telegraf, the agent environment is not changed
+	resultStr := ". " + r.envFullPath + " && " + r.TelegrafProcessArgs + " -config " + r.confFullPath
 	r.logger.Info(fmt.Sprintf("Starting process: %s -c %s", cmd, resultStr))
-	return r.processMaster.
-		GetWorker(r.workerName).
-		Start(
-			cmd,
-			r.processMaster.running,
-			r.processMaster.finish,
-			"-c", resultStr)
+	return r.supervisor.Start(cmd, "-c", resultStr)
 }
 
 func (r *TelegrafMonitoringService) stopProcess() error {
-	return r.processMaster.
-		DelWorker(r.workerName)
+	return r.supervisor.Stop()
+}
+
+// Health returns the state of the Telegraf process
+func (r *TelegrafMonitoringService) Health() ProcessHealth {
+	return r.supervisor.Health()
 }
 
 func (r *TelegrafMonitoringService) updateTelegrafConfAndEnvironmentVariables(redisTelegrafConfConfigMap *v12.ConfigMap, redisConfMap map[string]RedisConf) error {
@@ -191,31 +266,44 @@ func (r *TelegrafMonitoringService) updateTelegrafConfAndEnvironmentVariables(re
 
 	r.logger.Debug(fmt.Sprintf("Found %v redis instances", len(redisConfMap)))
 
+	passwords := strings.Builder{}
 	for redisInstance, redisConf := range redisConfMap {
 
 		passSecret := v12.Secret{}
-		secretGetErr := r.client.Get(context.TODO(), types.NamespacedName{Name: redisInstance + "-credentials", Namespace: r.namespace}, &passSecret)
+		secretGetErr := r.client.Get(context.TODO(), types.NamespacedName{Name: redisInstance + credentialsSuffix, Namespace: r.namespace}, &passSecret)
+		if errors.IsNotFound(secretGetErr) {
+			//the secret is deleted, the instance is skipped instead of keeping its stale credentials
+			r.logger.Warn("Secret " + redisInstance + credentialsSuffix + " is not found, Redis DB " + redisInstance + " is not monitored by Telegraf")
+			continue
+		}
 		if secretGetErr != nil {
-			r.logger.Error("Failed reading " + redisInstance + "-credentials" + " secret")
+			r.logger.Error("Failed reading " + redisInstance + credentialsSuffix + " secret")
 			return secretGetErr
 		}
 		dbPassEnvName := envCredentialsPrefix + strings.ReplaceAll(strings.ToUpper(redisInstance), "-", "_")
-		setEnvErr := os.Setenv(dbPassEnvName, string(passSecret.Data[Password]))
-		if setEnvErr != nil {
-			r.logger.Error("Failed setting " + dbPassEnvName + " environment variable")
-			return setEnvErr
-		}
-
-		r.logger.Debug("Variable " + dbPassEnvName + " is added to envrionment")
+		passwords.WriteString("export " + dbPassEnvName + "=" + shellQuote(string(passSecret.Data[Password])) + "\n")
 
 		variablesReplaceMap := map[string]string{
-			envDBName:             redisInstance,
-			envDBService:          fmt.Sprintf("%s.%s.svc", redisInstance, r.namespace),
-			envDBPort:             redisConf.port,
-			envDBPass:             "$" + dbPassEnvName + "",
+			envDBName:       redisInstance,
+			envDBService:    fmt.Sprintf("%s.%s.svc", redisInstance, r.namespace),
+			envDBPort:       redisConf.port,
+			envDBPass:       "$" + dbPassEnvName + "",
+			envDBTLSCA:      redisConf.caFile,
+			envDBTLSEnabled: strconv.FormatBool(redisConf.tlsEnabled),
 		}
 
 		inputs := redisTelegrafConfConfigMap.Data[redisTelegrafConfigMapInputsKey]
+		//Telegraf connects over TLS when tls_ca is set, so the line is kept only for TLS instances
+		if !redisConf.tlsEnabled {
+			inputs = removeLinesWith(inputs, envDBTLSCA)
+		}
+		//Telegraf does not accept empty tags, they are dropped for databases created without a classifier
+		for key, value := range map[string]string{envDBClassifierNS: redisConf.classifierNamespace, envDBMicroservice: redisConf.microserviceName} {
+			if value == "" {
+				inputs = removeLinesWith(inputs, key)
+			}
+			variablesReplaceMap[key] = value
+		}
 		for key, value := range variablesReplaceMap {
 			inputs = strings.ReplaceAll(inputs, key, value)
 		}
@@ -223,10 +311,20 @@ func (r *TelegrafMonitoringService) updateTelegrafConfAndEnvironmentVariables(re
 		resultRaw = append(resultRaw, inputs)
 	}
 
+	if len(resultRaw) == 2 {
+		return &core.ExecutionError{Msg: "Credentials of Redis instances not found"}
+	}
+
 	result := strings.Join(resultRaw, "\n")
 
 	r.logger.Debug(fmt.Sprintln("Telegraf configuration result: \n" + result))
 
+	//the passwords are saved apart from the config, the file is readable only by the agent user
+	if saveEnvErr := writePrivateFile(r.envFullPath, passwords.String()); saveEnvErr != nil {
+		r.logger.Error("Unable to write to a file " + r.envFullPath)
+		return saveEnvErr
+	}
+
 	//save config to file
 	f, saveCfgErr := os.Create(r.confFullPath)
 	if saveCfgErr != nil {
@@ -250,16 +348,18 @@ func (r *TelegrafMonitoringService) updateTelegrafConfAndEnvironmentVariables(re
 	return nil
 }
 
-func (r *TelegrafMonitoringService) Start(redisInstance, redisPort string, tlsEnabled bool, caFile string) error {
+func (r *TelegrafMonitoringService) Start(instance RedisInstance) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	r.timer.Reset(time.Second * duration)
 
-	r.redisConf[redisInstance] = RedisConf{
-		port:       redisPort,
-		caFile:     caFile,
-		tlsEnabled: tlsEnabled,
+	r.redisConf[instance.Name] = RedisConf{
+		port:                instance.Port,
+		caFile:              instance.CAFile,
+		tlsEnabled:          instance.TLSEnabled,
+		classifierNamespace: instance.ClassifierNamespace,
+		microserviceName:    instance.MicroserviceName,
 	}
 
 	return nil
@@ -275,19 +375,48 @@ func (r *TelegrafMonitoringService) Stop(redisInstance string) error {
 	return nil
 }
 
-//Starts new or restarts existed process
+// UpdateConfigMap replaces the telegraf configuration templates, the process is refreshed with a delay
+func (r *TelegrafMonitoringService) UpdateConfigMap(redisTelegrafConfigMap *v12.ConfigMap) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.redisTelegrafConfigMap = redisTelegrafConfigMap
+	r.timer.Reset(time.Second * duration)
+}
+
+// Reload refreshes the process with a delay if the instance is monitored, e.g. when its password is changed
+func (r *TelegrafMonitoringService) Reload(redisInstance string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.redisConf[redisInstance]; ok {
+		r.timer.Reset(time.Second * duration)
+	}
+}
+
+// Starts new or restarts existed process
 func (r *TelegrafMonitoringService) Refresh() error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	r.logger.Info("Stopping monitoring process...")
 	core.HandleError(r.stopProcess(), r.logger.Warn, "Failed stopping monitoring process")
-	if len(r.redisConf) > 0 {
-		r.logger.Debug("Building new telegraf.conf....")
-		updConfErr := r.updateTelegrafConfAndEnvironmentVariables(r.redisTelegrafConfigMap, r.redisConf)
-		if updConfErr != nil {
-			return updConfErr
+	if len(r.redisConf) == 0 {
+		//Telegraf can't work without inputs, it is started again when a redis instance is added
+		r.logger.Info("No active redis instances, monitoring process is not started")
+		for _, path := range []string{r.confFullPath, r.envFullPath} {
+			removeErr := os.Remove(path)
+			if removeErr != nil && !os.IsNotExist(removeErr) {
+				return removeErr
+			}
 		}
+		return nil
+	}
+
+	r.logger.Debug("Building new telegraf.conf....")
+	updConfErr := r.updateTelegrafConfAndEnvironmentVariables(r.redisTelegrafConfigMap, r.redisConf)
+	if updConfErr != nil {
+		return updConfErr
 	}
 	r.logger.Info("Starting monitoring process....")
 	return r.startProcess()
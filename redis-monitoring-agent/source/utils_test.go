@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	v12 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateTelegrafConfPasswords(t *testing.T) {
+	password := `pa'ss "$word\`
+	dir := t.TempDir()
+	service := &TelegrafMonitoringService{
+		client: fake.NewClientBuilder().WithObjects(&v12.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis-db" + credentialsSuffix, Namespace: "redis"},
+			Data:       map[string][]byte{Password: []byte(password)},
+		}).Build(),
+		logger:       zap.NewNop(),
+		confFullPath: filepath.Join(dir, "telegraf.conf"),
+		envFullPath:  filepath.Join(dir, "telegraf.env"),
+		namespace:    "redis",
+		mutex:        &sync.Mutex{},
+	}
+	configMap := &v12.ConfigMap{Data: map[string]string{
+		redisTelegrafConfigMapInputsKey: `password = "` + envDBPass + `"`,
+	}}
+	redisConf := map[string]RedisConf{"redis-db": {port: "6379"}, "deleted-db": {port: "6379"}}
+
+	if err := service.updateTelegrafConfAndEnvironmentVariables(configMap, redisConf); err != nil {
+		t.Fatal(err)
+	}
+	envName := envCredentialsPrefix + "REDIS_DB"
+	if _, ok := os.LookupEnv(envName); ok {
+		t.Errorf("%s is set in the agent environment", envName)
+	}
+	config, err := os.ReadFile(service.confFullPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(config), password) || !strings.Contains(string(config), `password = "$`+envName+`"`) {
+		t.Errorf("Telegraf configuration = %s, want the password variable instead of the password", config)
+	}
+	info, err := os.Stat(service.envFullPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("%s permissions = %v, want 0600", service.envFullPath, info.Mode().Perm())
+	}
+	output, err := exec.Command("sh", "-c", `. `+service.envFullPath+` && printf %s "$`+envName+`"`).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != password {
+		t.Errorf("%s in the Telegraf environment = %s, want %s", envName, output, password)
+	}
+}
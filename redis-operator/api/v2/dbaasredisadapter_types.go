@@ -19,17 +19,117 @@ package v2
 import (
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Components reported in DbaasRedisAdapterStatus.Components
+const (
+	ComponentAdapter    = "adapter"
+	ComponentRedis      = "redis"
+	ComponentMonitoring = "monitoring"
+	ComponentRobotTests = "robotTests"
+)
+
+// Monitoring.MetricCollector values
+const (
+	MetricCollectorPrometheus = "prometheus"
+	MetricCollectorInfluxDB   = "influxdb"
+)
+
+// Monitoring.MonitorKind values
+const (
+	MonitorKindServiceMonitor = "ServiceMonitor"
+	MonitorKindPodMonitor     = "PodMonitor"
+)
+
 // DbaasRedisAdapterStatus defines the observed state of DbaasRedisAdapter
 type DbaasRedisAdapterStatus struct {
 	Conditions []types.ServiceStatusCondition `json:"conditions,omitempty"`
+	// Spec generation processed by the last reconcile
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Result of the last deployment of every managed component
+	Components map[string]types.ServiceStatusCondition `json:"components,omitempty"`
+	// Inventory of logical databases created by the adapter
+	Databases *DatabasesStatus `json:"databases,omitempty"`
+	// State of the adapter registration in DBaaS aggregator
+	Dbaas *DbaasStatus `json:"dbaas,omitempty"`
+	// Objects of logical databases found by the orphan collector
+	Orphans *OrphansStatus `json:"orphans,omitempty"`
+	// Usage of the quotas of logical databases
+	Quotas *QuotasStatus `json:"quotas,omitempty"`
+}
+
+type DatabasesStatus struct {
+	Total          int         `json:"total"`
+	Ready          int         `json:"ready"`
+	NotReady       []string    `json:"notReady,omitempty"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+type DbaasStatus struct {
+	// API version negotiated with DBaaS aggregator
+	ApiVersion string `json:"apiVersion,omitempty"`
+	// Physical database registration health (UNKNOWN, OK, WARNING or PROBLEM)
+	Registration   string      `json:"registration,omitempty"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+type OrphansStatus struct {
+	Resources []OrphanResource `json:"resources,omitempty"`
+	// DryRun is true if the orphaned objects are not deleted
+	DryRun         bool        `json:"dryRun,omitempty"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+type OrphanResource struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Database string `json:"database"`
+	// Reason is NoDeployment or NoCredentials
+	Reason string `json:"reason"`
+	// Since is the time when the object was found orphaned first
+	Since metav1.Time `json:"since"`
+}
+
+type QuotasStatus struct {
+	Usage          []QuotaUsage `json:"usage,omitempty"`
+	LastUpdateTime metav1.Time  `json:"lastUpdateTime,omitempty"`
+}
+
+// QuotaUsage is the usage of the quota of the adapter, of a classifier namespace or of a classifier microservice
+type QuotaUsage struct {
+	// Scope is adapter, namespace or microservice
+	Scope        string `json:"scope"`
+	Namespace    string `json:"namespace,omitempty"`
+	Microservice string `json:"microservice,omitempty"`
+	Databases    int    `json:"databases"`
+	// Memory and CPU are the sums of the limits of the databases
+	Memory resource.Quantity `json:"memory"`
+	CPU    resource.Quantity `json:"cpu"`
+	Quota  Quota             `json:"quota"`
+}
+
+// SetComponentCondition stores the condition of the given component
+func (in *DbaasRedisAdapterStatus) SetComponentCondition(component string, condition types.ServiceStatusCondition) {
+	if in.Components == nil {
+		in.Components = map[string]types.ServiceStatusCondition{}
+	}
+	in.Components[component] = condition
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[0].type`
+//+kubebuilder:printcolumn:name="Redis",type=string,JSONPath=`.status.components.redis.type`,priority=1
+//+kubebuilder:printcolumn:name="Adapter",type=string,JSONPath=`.status.components.adapter.type`,priority=1
+//+kubebuilder:printcolumn:name="Monitoring",type=string,JSONPath=`.status.components.monitoring.type`,priority=1
+//+kubebuilder:printcolumn:name="API",type=string,JSONPath=`.status.dbaas.apiVersion`
+//+kubebuilder:printcolumn:name="Registration",type=string,JSONPath=`.status.dbaas.registration`
+//+kubebuilder:printcolumn:name="Databases",type=integer,JSONPath=`.status.databases.total`
+//+kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.databases.ready`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // DbaasRedisAdapter is the Schema for the dbaasredisadapters API
 type DbaasRedisAdapter struct {
@@ -77,11 +177,116 @@ type DbaasRedisAdapterSpec struct {
 }
 
 type DbaasAdapter struct {
-	Username          string          `json:"username,omitempty"`
-	SecretName        string          `json:"secretName,omitempty"`
-	SupportedFeatures map[string]bool `json:"supportedFeatures,omitempty"`
-	ApiVersion        string          `json:"apiVersion,omitempty"`
-	CreateDBTimeout   int             `json:"createDBTimeout,omitempty"`
+	Username          string           `json:"username,omitempty"`
+	SecretName        string           `json:"secretName,omitempty"`
+	SupportedFeatures map[string]bool  `json:"supportedFeatures,omitempty"`
+	ApiVersion        string           `json:"apiVersion,omitempty"`
+	CreateDBTimeout   int              `json:"createDBTimeout,omitempty"`
+	PasswordPolicy    *PasswordPolicy  `json:"passwordPolicy,omitempty"`
+	OrphanCollector   *OrphanCollector `json:"orphanCollector,omitempty"`
+	SoftDelete        *SoftDelete      `json:"softDelete,omitempty"`
+	// Profiles of logical databases which create requests select by the profile setting
+	Profiles []SizeProfile `json:"profiles,omitempty"`
+	// DefaultProfile is used by the create requests without the profile setting
+	DefaultProfile string `json:"defaultProfile,omitempty"`
+	// Quotas limit the logical databases created by the adapter
+	Quotas *Quotas `json:"quotas,omitempty"`
+	// RedisVersions are the Redis images which create requests can pin by the redisVersion or redisImage setting,
+	// the databases can be upgraded to the newer ones
+	RedisVersions []RedisVersion `json:"redisVersions,omitempty"`
+}
+
+// Quotas limit the number and the size of logical databases, a database is created only within all the quotas it is counted in
+type Quotas struct {
+	// Adapter is the quota of all the databases of the adapter
+	Adapter *Quota `json:"adapter,omitempty"`
+	// Namespace is the quota of the databases of every classifier namespace
+	Namespace *Quota `json:"namespace,omitempty"`
+	// Microservice is the quota of the databases of every classifier microservice in its namespace
+	Microservice *Quota `json:"microservice,omitempty"`
+	// Overrides replace the namespace or the microservice quota for the given namespaces and microservices
+	Overrides []QuotaOverride `json:"overrides,omitempty"`
+}
+
+// Quota limits the databases, the limits which are not set are not checked
+type Quota struct {
+	MaxDatabases *int `json:"maxDatabases,omitempty"`
+	// Memory is the maximal sum of the memory limits of the databases
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// CPU is the maximal sum of the CPU limits of the databases
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+}
+
+// QuotaOverride is the quota of the classifier namespace, or of the microservice in it if Microservice is set
+type QuotaOverride struct {
+	Namespace    string `json:"namespace"`
+	Microservice string `json:"microservice,omitempty"`
+	Quota        `json:",inline"`
+}
+
+// RedisVersion is an allowed Redis image of logical databases
+type RedisVersion struct {
+	// Version of Redis in the image, e.g. 7.2.4
+	Version string `json:"version"`
+	Image   string `json:"image"`
+}
+
+// SizeProfile is a named set of settings of logical databases, the settings of a create request override its fields
+type SizeProfile struct {
+	Name string `json:"name"`
+	// Resources are merged with the default resources of logical databases
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// Maxmemory directive of Redis, e.g. 512mb
+	Maxmemory string `json:"maxmemory,omitempty"`
+	// MaxmemoryPolicy is the eviction policy, e.g. allkeys-lru
+	MaxmemoryPolicy string `json:"maxmemoryPolicy,omitempty"`
+	// Persistence of the data: rdb, aof, rdb-aof or none
+	// +kubebuilder:validation:Enum=rdb;aof;rdb-aof;none
+	Persistence string `json:"persistence,omitempty"`
+	// NodeSelector is merged with the node labels of logical databases
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations are added to the tolerations of logical databases
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// Settings are other Redis configuration directives
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// SoftDelete keeps a dropped logical database scaled to zero for the retention period, it can be restored until it expires.
+// The data is kept only by the databases created with soft delete enabled, their data volume is a PersistentVolumeClaim.
+type SoftDelete struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Retention of the dropped databases, 72h by default
+	Retention string `json:"retention,omitempty"`
+	// StorageClass of the data volume claims, the default storage class is used if it is empty
+	StorageClass string `json:"storageClass,omitempty"`
+	// StorageSize of the data volume claims, 1Gi by default
+	StorageSize string `json:"storageSize,omitempty"`
+}
+
+// OrphanCollector finds the objects of logical databases left without their Deployment
+// and the Deployments left without their credentials Secret
+type OrphanCollector struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval between the sweeps, 10m by default
+	Interval string `json:"interval,omitempty"`
+	// Retention is how long an object is reported as orphaned before it is deleted, 24h by default
+	Retention string `json:"retention,omitempty"`
+	// DryRun only reports the orphaned objects and never deletes them
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// PasswordPolicy is applied to the generated passwords of logical databases and to the passwords given in requests
+type PasswordPolicy struct {
+	// Length of the generated passwords
+	Length int `json:"length,omitempty"`
+	// MinLength of the passwords given in requests
+	MinLength        int  `json:"minLength,omitempty"`
+	RequireUppercase bool `json:"requireUppercase,omitempty"`
+	RequireLowercase bool `json:"requireLowercase,omitempty"`
+	RequireDigits    bool `json:"requireDigits,omitempty"`
+	RequireSpecial   bool `json:"requireSpecial,omitempty"`
+	// MinEntropyBits is the minimal strength: length multiplied by log2 of the used character classes size
+	MinEntropyBits int `json:"minEntropyBits,omitempty"`
 }
 
 type DbaasAggregator struct {
@@ -110,6 +315,9 @@ type Redis struct {
 	NodeLabels        map[string]string        `json:"nodeLabels,omitempty"`
 	TLS               TLS                      `json:"tls,omitempty" common:"true"`
 	PriorityClassName string                   `json:"priorityClassName,omitempty"`
+	// MaxmemOverhead is the part of the memory limit which is not given to maxmemory when Maxmem is not set,
+	// a percentage like 25% or a quantity like 64Mi
+	MaxmemOverhead string `json:"maxmemOverhead,omitempty"`
 }
 
 type InfluxSettings struct {
@@ -128,6 +336,16 @@ type Monitoring struct {
 	InfluxDB          *InfluxSettings          `json:"influxDB,omitempty"`
 	MetricCollector   string                   `json:"metricCollector,omitempty"`
 	PriorityClassName string                   `json:"priorityClassName,omitempty"`
+	// Kind of the agent monitor, ServiceMonitor or PodMonitor
+	MonitorKind string `json:"monitorKind,omitempty"`
+	// Scrape interval of the agent
+	MonitoringInterval string `json:"monitoringInterval,omitempty"`
+}
+
+// UsesInfluxDB reports whether Telegraf sends metrics to InfluxDB, otherwise Prometheus scrapes the agent.
+// Any collector except prometheus means InfluxDB, the same way as the chart renders the outputs.
+func (in Monitoring) UsesInfluxDB() bool {
+	return in.MetricCollector != "" && in.MetricCollector != MetricCollectorPrometheus
 }
 
 type RobotTests struct {
@@ -39,20 +39,15 @@ func (r *AdapterBuilder) Build(ctx core.ExecutionContext) core.Executable {
 	compound := AdapterCompound{}
 	compound.ServiceName = ServiceName
 	compound.CalcDeployType = func(ctx core.ExecutionContext) (deployType core.MicroServiceDeployType, err error) {
-		return core.CleanDeploy, nil
+		return core.Update, nil
 	}
 
 	compound.AddStep(&utils.SimpleCtxExecutable{
 		StepName: "Adapter Service",
 		ExecuteFunc: func(ctx core.ExecutionContext, cr *netcrackerv1.DbaasRedisAdapter, log *zap.Logger) error {
-			kubeClient := ctx.Get(constants.ContextClient).(client.Client)
 			template := Service(cr)
 
-			core.DeleteRuntimeObject(kubeClient, &corev1.Service{
-				ObjectMeta: template.ObjectMeta,
-			})
-
-			err := utils.CreateRuntimeObjectContextWrapper(ctx, template, template.ObjectMeta)
+			err := utils.ApplyRuntimeObject(ctx, template)
 			core.PanicError(err, log.Error, "Adapter service creation failed")
 
 			return nil
@@ -69,6 +64,10 @@ func (r *AdapterBuilder) Build(ctx core.ExecutionContext) core.Executable {
 	return &compound
 }
 
+func (r *AdapterCompound) Execute(ctx core.ExecutionContext) error {
+	return utils.ExecuteComponent(ctx, netcrackerv1.ComponentAdapter, r.MicroServiceCompound.Execute)
+}
+
 func Service(cr *netcrackerv1.DbaasRedisAdapter) *corev1.Service {
 	tlsEnabled := utils.IsTLSEnableForDBAAS(cr.Spec.Dbaas.Aggregator.DbaasAggregatorRegistrationAddress, cr.Spec.Redis.TLS.TLS.Enabled)
 	port := utils.GetHTTPPort(tlsEnabled)
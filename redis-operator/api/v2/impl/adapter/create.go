@@ -0,0 +1,46 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	coreService "github.com/Netcracker/qubership-dbaas-adapter-core/pkg/service"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerCreateDatabaseHandler replaces the handler of POST <root>/<appPath>/databases of the DBaaS adapter core,
+// which answers all the errors with 400, so the typed errors of the creation get their own statuses. It must be
+// called after the core handlers are built, so the request passes the core middleware and authentication.
+func registerCreateDatabaseHandler(app *fiber.App, appPath string,
+	admService coreService.CoreAdministrationServiceIface) error {
+	path := fmt.Sprintf("/api/%s/dbaas/adapter%s/databases", admService.GetVersion(), appPath)
+
+	return replaceRouteHandler(app, fiber.MethodPost, path, func(c *fiber.Ctx) error {
+		request := admService.GetDefaultCreateRequest()
+		//the body is parsed as JSON regardless of the content type, like in the core handlers
+		if len(c.Body()) > 0 {
+			if err := json.Unmarshal(c.Body(), &request); err != nil {
+				return customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid create request: %v", err))
+			}
+		}
+		response, err := admService.CreateDatabase(requestContext(c), request)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(response)
+	})
+}
+
+// replaceRouteHandler sets the handler of the route registered by the core, the middleware before the route is kept.
+func replaceRouteHandler(app *fiber.App, method string, path string, handler fiber.Handler) error {
+	for _, routes := range app.Stack() {
+		for _, route := range routes {
+			if route.Method == method && route.Path == path && len(route.Handlers) > 0 {
+				route.Handlers[len(route.Handlers)-1] = handler
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("route %s %s is not registered", method, path)
+}
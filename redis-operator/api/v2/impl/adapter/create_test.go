@@ -0,0 +1,113 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	fiber2 "github.com/Netcracker/qubership-dbaas-adapter-core/pkg/impl/fiber"
+	coreService "github.com/Netcracker/qubership-dbaas-adapter-core/pkg/service"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestAdapterApp builds the core handlers and registers the adapter ones, like RunDBaaSServer does.
+func newTestAdapterApp(t *testing.T, objects ...client.Object) *fiber.App {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	adminService, err := service.NewAdministrationService(service.AdministrationServiceOptions{
+		ApiVersion: "v1",
+		Logger:     zap.NewNop(),
+		KubeClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Namespace:  "redis",
+		RedisLabel: "redis",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admService := coreService.NewCoreAdministrationService("redis", 8080, adminService, zap.NewNop(), false, nil, "")
+	//the registration loop stops at once with the cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	physicalService := coreService.NewPhysicalRegistrationService("redis", zap.NewNop(), "redis", "", dao.BasicAuth{},
+		nil, nil, 1000, 1000, 1000, admService, ctx)
+
+	app := fiber.New()
+	app.Use(errorHandler(zap.NewNop()))
+	fiber2.BuildFiberDBaaSAdapterHandlers(app, "user", "pass", "/redis", admService, physicalService, nil, map[string]bool{}, zap.NewNop(), false, "")
+	if err = registerAdapterHandlers(app, "/redis", admService, adminService); err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+// testRequest sends the authenticated request and decodes the JSON body of the response into the result.
+func testRequest(t *testing.T, app *fiber.App, method string, path string, body string, result interface{}) int {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.SetBasicAuth("user", "pass")
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != nil {
+		if err = json.NewDecoder(response.Body).Decode(result); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+	return response.StatusCode
+}
+
+func TestReplaceRouteHandlerFailsWithoutRoute(t *testing.T) {
+	app := fiber.New()
+	app.Get("/databases", func(c *fiber.Ctx) error { return nil })
+
+	if err := replaceRouteHandler(app, fiber.MethodPost, "/databases", func(c *fiber.Ctx) error { return nil }); err == nil {
+		t.Errorf("replaceRouteHandler of a not registered route succeeded")
+	}
+}
+
+func TestCreateDatabaseHandlerAfterCoreMiddleware(t *testing.T) {
+	app := newTestAdapterApp(t)
+
+	request := httptest.NewRequest(fiber.MethodPost, "/api/v1/dbaas/adapter/redis/databases", strings.NewReader("{"))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMETextPlain)
+	request.SetBasicAuth("user", "pass")
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := map[string]string{}
+	if err = json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != fiber.StatusBadRequest || body["code"] != InvalidArgumentCode {
+		t.Errorf("POST /databases = %d %v, want %d %s from the adapter handler", response.StatusCode, body, fiber.StatusBadRequest, InvalidArgumentCode)
+	}
+
+	request = httptest.NewRequest(fiber.MethodPost, "/api/v1/dbaas/adapter/redis/databases", strings.NewReader("{}"))
+	if response, err = app.Test(request); err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("POST /databases without credentials = %d, want %d", response.StatusCode, fiber.StatusUnauthorized)
+	}
+
+	if response, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil)); err != nil {
+		t.Fatal(err)
+	}
+	metrics, _ := io.ReadAll(response.Body)
+	if !strings.Contains(string(metrics), `path="/api/v1/dbaas/adapter/redis/databases"`) {
+		t.Errorf("POST /databases is not counted by the core prometheus middleware")
+	}
+}
@@ -0,0 +1,40 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerDatabasesHandlers replaces the handlers of GET <root>/<appPath>/databases and
+// PUT <root>/<appPath>/databases/:dbName/metadata of the DBaaS adapter core, whose operations can't return an error,
+// so the typed errors of listing the databases and of updating the metadata get their own statuses.
+// It must be called after the core handlers are built, like registerCreateDatabaseHandler.
+func registerDatabasesHandlers(app *fiber.App, appPath string, adminService *service.AdministrationService) error {
+	path := fmt.Sprintf("/api/%s/dbaas/adapter%s/databases", adminService.GetVersion(), appPath)
+
+	err := replaceRouteHandler(app, fiber.MethodGet, path, func(c *fiber.Ctx) error {
+		databases, err := adminService.ListDatabases(requestContext(c))
+		if err != nil {
+			return err
+		}
+		return c.JSON(databases)
+	})
+	if err != nil {
+		return err
+	}
+
+	return replaceRouteHandler(app, fiber.MethodPut, path+"/:dbName/metadata", func(c *fiber.Ctx) error {
+		var metadata map[string]interface{}
+		if err := json.Unmarshal(c.Body(), &metadata); err != nil {
+			return customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid metadata: %v", err))
+		}
+		if err := adminService.UpdateDatabaseMetadata(requestContext(c), metadata, c.Params("dbName")); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+}
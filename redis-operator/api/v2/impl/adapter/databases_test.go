@@ -0,0 +1,43 @@
+package adapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDatabasesHandlers(t *testing.T) {
+	app := newTestAdapterApp(t,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db1", Namespace: "redis", Labels: map[string]string{"redis": "redis"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "redis"}})
+
+	var databases []string
+	if status := testRequest(t, app, fiber.MethodGet, "/api/v1/dbaas/adapter/redis/databases", "", &databases); status != fiber.StatusOK {
+		t.Errorf("GET /databases = %d, want %d", status, fiber.StatusOK)
+	}
+	if !reflect.DeepEqual(databases, []string{"db1"}) {
+		t.Errorf("GET /databases = %v, want [db1]", databases)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{name: "invalid metadata", body: "[", status: fiber.StatusBadRequest, code: InvalidArgumentCode},
+		{name: "database without credentials", body: `{"owner":"test"}`, status: fiber.StatusNotFound, code: NotFoundCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]string{}
+			status := testRequest(t, app, fiber.MethodPut, "/api/v1/dbaas/adapter/redis/databases/db1/metadata", tt.body, &body)
+			if status != tt.status || body["code"] != tt.code {
+				t.Errorf("PUT /metadata = %d %v, want %d %s", status, body, tt.status, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerDescribeDatabasesHandler replaces the handler of POST <root>/<appPath>/describe/databases of the DBaaS
+// adapter core, whose operation can't return an error, so the typed errors of the description get their own statuses.
+// With the runtime query parameter the databases are described with the runtime section read from INFO and pod status.
+// It must be called after the core handlers are built, like registerCreateDatabaseHandler.
+func registerDescribeDatabasesHandler(app *fiber.App, appPath string, adminService *service.AdministrationService) error {
+	path := fmt.Sprintf("/api/%s/dbaas/adapter%s/describe/databases", adminService.GetVersion(), appPath)
+
+	return replaceRouteHandler(app, fiber.MethodPost, path, func(c *fiber.Ctx) error {
+		ctx := requestContext(c)
+		var databases []string
+		if len(c.Body()) > 0 {
+			if err := json.Unmarshal(c.Body(), &databases); err != nil {
+				return customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid list of databases: %v", err))
+			}
+		}
+		//all the databases are described if the list is empty, like in the core
+		if len(databases) == 0 {
+			var err error
+			if databases, err = adminService.ListDatabases(ctx); err != nil {
+				return err
+			}
+		}
+
+		if queryFlag(c, "runtime") {
+			described, err := adminService.DescribeDatabasesWithRuntime(ctx, databases,
+				queryFlag(c, "resources"), queryFlag(c, "connectionProperties"))
+			if err != nil {
+				return err
+			}
+			return c.JSON(described)
+		}
+
+		described, err := adminService.DescribeLogicalDatabases(ctx, databases, queryFlag(c, "resources"), queryFlag(c, "connectionProperties"))
+		if err != nil {
+			return err
+		}
+		if adminService.GetVersion() != "v1" {
+			return c.JSON(described)
+		}
+		//API v1 has the only connection properties of the database, like in the core
+		response := make(map[string]dao.LogicalDatabaseDescribedSingle, len(described))
+		for name, database := range described {
+			single := dao.LogicalDatabaseDescribedSingle{Resources: database.Resources}
+			if len(database.ConnectionProperties) > 0 {
+				single.ConnectionProperties = database.ConnectionProperties[0]
+			}
+			response[name] = single
+		}
+		return c.JSON(response)
+	})
+}
+
+// queryFlag is true if the query parameter is set without a value or with a true value, like in the core handlers.
+func queryFlag(c *fiber.Ctx, name string) bool {
+	args := c.Request().URI().QueryArgs()
+	if !args.Has(name) {
+		return false
+	}
+	if len(args.Peek(name)) == 0 {
+		return true
+	}
+	value, _ := strconv.ParseBool(c.Query(name))
+	return value
+}
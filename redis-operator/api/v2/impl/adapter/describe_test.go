@@ -0,0 +1,76 @@
+package adapter
+
+import (
+	"strings"
+	"testing"
+
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDescribeDatabasesHandler(t *testing.T) {
+	app := newTestAdapterApp(t,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db1", Namespace: "redis", Labels: map[string]string{"redis": "redis"}}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db1-credentials", Namespace: "redis"}, Data: map[string][]byte{"password": []byte("secret")}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db2", Namespace: "redis", Labels: map[string]string{"redis": "redis"}}})
+
+	tests := []struct {
+		name      string
+		query     string
+		body      string
+		status    int
+		databases []string
+		password  string
+	}{
+		{name: "all databases", query: "?resources", status: fiber.StatusOK, databases: []string{"db1", "db2"}},
+		{name: "connection properties", query: "?connectionProperties", body: `["db1"]`, status: fiber.StatusOK,
+			databases: []string{"db1"}, password: "secret"},
+		{name: "connection properties without credentials", query: "?connectionProperties", body: `["db2"]`, status: fiber.StatusNotFound},
+		{name: "invalid list", body: "{", status: fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]interface{}{}
+			status := testRequest(t, app, fiber.MethodPost, "/api/v1/dbaas/adapter/redis/describe/databases"+tt.query, tt.body, &body)
+			if status != tt.status {
+				t.Fatalf("POST /describe/databases = %d %v, want %d", status, body, tt.status)
+			}
+			if tt.status != fiber.StatusOK {
+				return
+			}
+			if len(body) != len(tt.databases) {
+				t.Errorf("POST /describe/databases = %v, want %v", body, tt.databases)
+			}
+			for _, name := range tt.databases {
+				database, ok := body[name].(map[string]interface{})
+				if !ok {
+					t.Fatalf("%s is not described in %v", name, body)
+				}
+				//API v1 has the only connection properties of the database
+				connection, _ := database["connectionProperties"].(map[string]interface{})
+				if tt.password != "" && connection["password"] != tt.password {
+					t.Errorf("connection properties of %s = %v, want password %s", name, database["connectionProperties"], tt.password)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeDatabasesHandlerWithRuntime(t *testing.T) {
+	app := newTestAdapterApp(t,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db2", Namespace: "redis", Labels: map[string]string{"redis": "redis"}}})
+
+	body := map[string]service.LogicalDatabaseRuntimeDescribed{}
+	status := testRequest(t, app, fiber.MethodPost, "/api/v1/dbaas/adapter/redis/describe/databases?runtime", "", &body)
+	if status != fiber.StatusOK {
+		t.Fatalf("POST /describe/databases?runtime = %d, want %d", status, fiber.StatusOK)
+	}
+	runtime := body["db2"].Runtime
+	//the database has no pods and no credentials to read INFO
+	if runtime == nil || runtime.Healthy || !strings.Contains(runtime.LastError, "is not found") {
+		t.Errorf("runtime of db2 = %+v, want not healthy with the error of the credentials", runtime)
+	}
+}
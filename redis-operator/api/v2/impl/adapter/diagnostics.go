@@ -0,0 +1,37 @@
+package adapter
+
+import (
+	"fmt"
+	"strconv"
+
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerDiagnosticsHandler adds GET <root>/<appPath>/databases/:dbName/diagnostics which returns the current
+// slowlog with redacted arguments and the latency events and histogram of the logical database.
+// The count query parameter limits the number of the slowlog entries.
+func registerDiagnosticsHandler(app *fiber.App, appPath string,
+	adminService *service.AdministrationService) {
+	path := databasePath(adminService, appPath) + "/diagnostics"
+
+	app.Get(path, func(c *fiber.Ctx) error {
+		dbName := c.Params("dbName")
+		count, err := strconv.ParseInt(c.Query("count", strconv.Itoa(service.DefaultSlowLogCount)), 10, 64)
+		if err != nil || count < 1 || count > service.MaxSlowLogCount {
+			return customEntity.NewInvalidArgumentError(fmt.Sprintf("count must be a number from 1 to %d", service.MaxSlowLogCount))
+		}
+
+		diagnostics, err := adminService.GetDiagnostics(requestContext(c), dbName, count)
+		if err != nil {
+			return err
+		}
+		return c.JSON(diagnostics)
+	})
+}
+
+// databasePath is the path of a logical database in the adapter API, the same as the paths of DBaaS adapter core.
+func databasePath(adminService *service.AdministrationService, appPath string) string {
+	return fmt.Sprintf("/api/%s/dbaas/adapter%s/databases/:dbName", adminService.GetVersion(), appPath)
+}
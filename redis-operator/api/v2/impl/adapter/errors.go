@@ -0,0 +1,101 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Machine-readable codes of the adapter API errors, they are returned with the message in the error body.
+const (
+	InvalidArgumentCode    = "INVALID_ARGUMENT"
+	NotFoundCode           = "NOT_FOUND"
+	AlreadyExistsCode      = "ALREADY_EXISTS"
+	PreconditionFailedCode = "PRECONDITION_FAILED"
+	QuotaExceededCode      = "QUOTA_EXCEEDED"
+	TooManyRequestsCode    = "TOO_MANY_REQUESTS"
+	BackendUnavailableCode = "BACKEND_UNAVAILABLE"
+	TimeoutCode            = "TIMEOUT"
+	InternalErrorCode      = "INTERNAL_ERROR"
+)
+
+// errorStatus returns the HTTP status and the code of the typed error, other errors are internal.
+func errorStatus(err error) (int, string) {
+	var invalidArgument *customEntity.InvalidArgumentError
+	var coreInvalidArgument *dao.InvalidArgumentError
+	var notFound *customEntity.NotFoundError
+	var alreadyExists *customEntity.ResourceAlreadyExistsError
+	var coreAlreadyExists *dao.ResourceAlreadyExistsError
+	var preconditionFailed *customEntity.PreconditionFailedError
+	var quotaExceeded *customEntity.QuotaExceededError
+	var tooManyRequests *customEntity.TooManyRequestsError
+	var backendUnavailable *customEntity.BackendUnavailableError
+	var timeout *customEntity.TimeoutError
+	switch {
+	case errors.As(err, &invalidArgument), errors.As(err, &coreInvalidArgument):
+		return fiber.StatusBadRequest, InvalidArgumentCode
+	case errors.As(err, &notFound), apierrors.IsNotFound(err):
+		return fiber.StatusNotFound, NotFoundCode
+	case errors.As(err, &alreadyExists), errors.As(err, &coreAlreadyExists):
+		return fiber.StatusConflict, AlreadyExistsCode
+	case errors.As(err, &preconditionFailed):
+		return fiber.StatusPreconditionFailed, PreconditionFailedCode
+	case errors.As(err, &quotaExceeded):
+		return fiber.StatusForbidden, QuotaExceededCode
+	case errors.As(err, &tooManyRequests):
+		return fiber.StatusTooManyRequests, TooManyRequestsCode
+	case errors.As(err, &backendUnavailable):
+		return fiber.StatusServiceUnavailable, BackendUnavailableCode
+	case errors.As(err, &timeout):
+		return fiber.StatusGatewayTimeout, TimeoutCode
+	}
+	return fiber.StatusInternalServerError, InternalErrorCode
+}
+
+// errorResponse writes the error body with the code and the message, the server errors are logged.
+func errorResponse(c *fiber.Ctx, err error, log *zap.Logger) error {
+	status, code := errorStatus(err)
+	if status >= fiber.StatusInternalServerError {
+		log.Error(fmt.Sprintf("%s %s failed", c.Method(), c.Path()), zap.Error(err))
+	}
+	return c.Status(status).JSON(fiber.Map{"code": code, "message": err.Error()})
+}
+
+// errorHandler is registered before all the handlers, it maps the errors returned by them and the unexpected panics
+// to the error response. Errors of fiber keep their status.
+func errorHandler(log *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				recoveredErr, ok := recovered.(error)
+				if !ok {
+					recoveredErr = fmt.Errorf("%v", recovered)
+				}
+				err = errorResponse(c, recoveredErr, log)
+			}
+		}()
+		err = c.Next()
+		var fiberErr *fiber.Error
+		if err == nil || errors.As(err, &fiberErr) {
+			return err
+		}
+		return errorResponse(c, err, log)
+	}
+}
+
+// requestContext passes the request id to the logger of the operation, the same as the DBaaS adapter core does.
+func requestContext(c *fiber.Ctx) context.Context {
+	requestId := c.Get("X-Request-ID")
+	if requestId == "" {
+		requestId = uuid.New().String()
+		c.Set("X-Request-ID", requestId)
+	}
+	return context.WithValue(context.Background(), "request_id", requestId)
+}
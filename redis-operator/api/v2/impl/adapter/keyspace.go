@@ -0,0 +1,42 @@
+package adapter
+
+import (
+	"fmt"
+
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerKeyspaceAnalysisHandlers adds the key space analysis of a logical database:
+// POST <root>/<appPath>/databases/:dbName/keyspace-analysis starts the analysis with the options from the body,
+// GET of the same path returns the state and the result of the last analysis.
+func registerKeyspaceAnalysisHandlers(app *fiber.App, appPath string,
+	adminService *service.AdministrationService) {
+	path := databasePath(adminService, appPath) + "/keyspace-analysis"
+
+	app.Post(path, func(c *fiber.Ctx) error {
+		dbName := c.Params("dbName")
+		request := service.KeyspaceAnalysisRequest{}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&request); err != nil {
+				return customEntity.NewInvalidArgumentError(err.Error())
+			}
+		}
+
+		analysis, err := adminService.StartKeyspaceAnalysis(requestContext(c), dbName, request)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusAccepted).JSON(analysis)
+	})
+
+	app.Get(path, func(c *fiber.Ctx) error {
+		dbName := c.Params("dbName")
+		analysis := adminService.GetKeyspaceAnalysis(dbName)
+		if analysis == nil {
+			return customEntity.NewNotFoundError(fmt.Sprintf("Key space analysis of %s was not started", dbName))
+		}
+		return c.JSON(analysis)
+	})
+}
@@ -0,0 +1,21 @@
+package adapter
+
+import (
+	"fmt"
+
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerQuotasHandler adds GET <root>/<appPath>/quotas which returns the usage of the quotas of logical databases.
+func registerQuotasHandler(app *fiber.App, appPath string, adminService *service.AdministrationService) {
+	quotasPath := fmt.Sprintf("/api/%s/dbaas/adapter%s/quotas", adminService.GetVersion(), appPath)
+
+	app.Get(quotasPath, func(c *fiber.Ctx) error {
+		usage, err := adminService.GetQuotaUsage(requestContext(c))
+		if err != nil {
+			return err
+		}
+		return c.JSON(usage)
+	})
+}
@@ -43,7 +43,7 @@ func RunDBaaSServer(spec *v2.DbaasRedisAdapter, redisClient redis.RedisClientInt
 	regPass := string(aggregatorCredentialsSecret.Data[constants.Password])
 
 	supports := dao.SupportsBase{
-		Users:             false,
+		Users:             true,
 		Settings:          false,
 		DescribeDatabases: true,
 		AdditionalKeys: dao.Supports{
@@ -68,7 +68,11 @@ func RunDBaaSServer(spec *v2.DbaasRedisAdapter, redisClient redis.RedisClientInt
 		apiVersion = "v1"
 	}
 
-	adminService := PrepareAdminService(spec, redisClient, kubeClient, runtimeScheme, log, namespace, apiVersion)
+	statusReporter := NewStatusReporter(kubeClient, spec, apiVersion, log.Named("Status Reporter"))
+	adminService, err := PrepareAdminService(spec, redisClient, kubeClient, runtimeScheme, log, statusReporter, namespace, apiVersion)
+	if err != nil {
+		return err
+	}
 
 	port := utils.GetHTTPPort(spec.Spec.Redis.TLS.TLS.Enabled)
 	admService := coreService.NewCoreAdministrationService(
@@ -82,33 +86,53 @@ func RunDBaaSServer(spec *v2.DbaasRedisAdapter, redisClient redis.RedisClientInt
 	)
 
 	app := func(app *fiber.App, ctx context.Context) error {
+		physicalService := coreService.NewPhysicalRegistrationService(
+			appName,
+			log,
+			spec.Spec.Dbaas.Aggregator.PhysicalDatabaseIdentifier,
+			fmt.Sprintf("%s://dbaas-redis-adapter.%v:%d", utils.GetHTTPProtocol(tlsEnabled), namespace, utils.GetHTTPPort(tlsEnabled)),
+			dao.BasicAuth{
+				Username: spec.Spec.Dbaas.Adapter.Username,
+				Password: apiPass,
+			},
+			spec.Spec.Aggregator.PhysicalDatabaseLabels,
+			dbaasClient,
+			150000,
+			60000,
+			5000,
+			admService,
+			ctx,
+		)
+		//registered before the core handlers to map the errors of all the handlers to their statuses
+		app.Use(errorHandler(log))
 		fiber2.BuildFiberDBaaSAdapterHandlers(
 			app,
 			spec.Spec.Dbaas.Adapter.Username,
 			apiPass,
 			appPath,
 			admService,
-			coreService.NewPhysicalRegistrationService(
-				appName,
-				log,
-				spec.Spec.Dbaas.Aggregator.PhysicalDatabaseIdentifier,
-				fmt.Sprintf("%s://dbaas-redis-adapter.%v:%d", utils.GetHTTPProtocol(tlsEnabled), namespace, utils.GetHTTPPort(tlsEnabled)),
-				dao.BasicAuth{
-					Username: spec.Spec.Dbaas.Adapter.Username,
-					Password: apiPass,
-				},
-				spec.Spec.Aggregator.PhysicalDatabaseLabels,
-				dbaasClient,
-				150000,
-				60000,
-				5000,
-				admService,
-				ctx,
-			),
+			physicalService,
 			nil,
 			supports.ToMap(),
 			log,
 			false, "")
+		if err := registerAdapterHandlers(app, appPath, admService, adminService); err != nil {
+			return err
+		}
+		adminService.RunSoftDeletePurge(ctx)
+		adminService.RunVersionLabelSync(ctx)
+		orphanCollector, err := service.NewOrphanCollector(adminService, spec.Spec.Dbaas.Adapter.OrphanCollector, log.Named("Orphan Collector"))
+		if err != nil {
+			return err
+		}
+		var orphans func() *v2.OrphansStatus
+		if orphanCollector != nil {
+			orphanCollector.Run(ctx)
+			orphans = orphanCollector.Status
+		}
+		statusReporter.Run(ctx, func() string {
+			return physicalService.Health.Status
+		}, orphans, adminService.QuotasStatus)
 		return nil
 	}
 
@@ -124,8 +148,32 @@ func RunDBaaSServer(spec *v2.DbaasRedisAdapter, redisClient redis.RedisClientInt
 
 }
 
+// registerAdapterHandlers must be called after the core handlers are built. The core handlers of the operations
+// returning the typed errors are replaced, the server is not started if the core has no such route. The adapter
+// handlers are added under the path of the core database API, so all of them pass the same core middleware
+// and basic authentication.
+func registerAdapterHandlers(app *fiber.App, appPath string, admService coreService.CoreAdministrationServiceIface,
+	adminService *service.AdministrationService) error {
+	if err := registerCreateDatabaseHandler(app, appPath, admService); err != nil {
+		return err
+	}
+	if err := registerDatabasesHandlers(app, appPath, adminService); err != nil {
+		return err
+	}
+	if err := registerDescribeDatabasesHandler(app, appPath, adminService); err != nil {
+		return err
+	}
+	registerDiagnosticsHandler(app, appPath, adminService)
+	registerKeyspaceAnalysisHandlers(app, appPath, adminService)
+	registerSoftDeleteHandlers(app, appPath, adminService)
+	registerQuotasHandler(app, appPath, adminService)
+	registerUpgradeHandler(app, appPath, adminService)
+	return nil
+}
+
+// PrepareAdminService returns the error if the configuration of the logical databases in the spec is invalid.
 func PrepareAdminService(spec *v2.DbaasRedisAdapter, redisClient redis.RedisClientInterface, kubeClient client.Client, runtimeScheme *runtime.Scheme,
-	log *zap.Logger, namespace string, apiVersion string) *service.AdministrationService {
+	log *zap.Logger, inventoryObserver service.InventoryObserver, namespace string, apiVersion string) (*service.AdministrationService, error) {
 	redisSpec := spec.Spec.Redis
 	redisPort := 6379
 
@@ -134,28 +182,38 @@ func PrepareAdminService(spec *v2.DbaasRedisAdapter, redisClient redis.RedisClie
 		tolerations = spec.Spec.Policies.Tolerations
 	}
 
-	return service.NewAdministrationService(
-		redisClient,
-		spec.Spec.Adapter.SupportedFeatures,
-		apiVersion,
-		log.Named("DBaaS Adapter"),
-		kubeClient,
-		runtimeScheme,
-		namespace,
-		redisPort,
-		*redisSpec.Resources,
-		redisSpec.DockerImage,
-		redisSpec.Args,
-		redisSpec.Label,
-		"redis",
-		spec.Spec.Adapter.CreateDBTimeout,
-		redisSpec.NodeLabels,
-		*spec.Spec.PodSecurityContext,
-		spec.Spec.ServiceAccountName,
-		tolerations,
-		spec.Spec.ImagePullPolicy,
-		spec.Spec.Redis.TLS,
-		spec.Spec.Redis.PriorityClassName,
-		spec.Spec.PartOf, spec.Spec.ManagedBy,
-	)
+	return service.NewAdministrationService(service.AdministrationServiceOptions{
+		RedisClient:             redisClient,
+		SupportedFeatures:       spec.Spec.Adapter.SupportedFeatures,
+		ApiVersion:              apiVersion,
+		Logger:                  log.Named("DBaaS Adapter"),
+		KubeClient:              kubeClient,
+		RuntimeScheme:           runtimeScheme,
+		Namespace:               namespace,
+		RedisServicePort:        redisPort,
+		RedisResources:          *redisSpec.Resources,
+		RedisImage:              redisSpec.DockerImage,
+		RedisArgs:               redisSpec.Args,
+		RedisLabel:              redisSpec.Label,
+		DefaultRedisPassword:    "redis",
+		DefaultRedisDbStartWait: spec.Spec.Adapter.CreateDBTimeout,
+		NodeSelector:            redisSpec.NodeLabels,
+		SecurityContext:         *spec.Spec.PodSecurityContext,
+		ServiceAccountName:      spec.Spec.ServiceAccountName,
+		Tolerations:             tolerations,
+		RedisImagePullPolicy:    spec.Spec.ImagePullPolicy,
+		TLS:                     spec.Spec.Redis.TLS,
+		PriorityClassName:       spec.Spec.Redis.PriorityClassName,
+		PartOf:                  spec.Spec.PartOf,
+		ManagedBy:               spec.Spec.ManagedBy,
+		InventoryObserver:       inventoryObserver,
+		PasswordPolicy:          spec.Spec.Dbaas.Adapter.PasswordPolicy,
+		SoftDelete:              spec.Spec.Dbaas.Adapter.SoftDelete,
+		Profiles:                spec.Spec.Dbaas.Adapter.Profiles,
+		DefaultProfile:          spec.Spec.Dbaas.Adapter.DefaultProfile,
+		Maxmem:                  redisSpec.Maxmem,
+		MaxmemOverhead:          redisSpec.MaxmemOverhead,
+		Quotas:                  spec.Spec.Dbaas.Adapter.Quotas,
+		RedisVersions:           spec.Spec.Dbaas.Adapter.RedisVersions,
+	})
 }
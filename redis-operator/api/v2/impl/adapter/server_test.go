@@ -0,0 +1,49 @@
+package adapter
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdapterHandlersBehindCoreAuthentication(t *testing.T) {
+	app := newTestAdapterApp(t)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: fiber.MethodPost, path: "/api/v1/dbaas/adapter/redis/databases"},
+		{method: fiber.MethodGet, path: "/api/v1/dbaas/adapter/redis/databases"},
+		{method: fiber.MethodPut, path: "/api/v1/dbaas/adapter/redis/databases/db1/metadata"},
+		{method: fiber.MethodPost, path: "/api/v1/dbaas/adapter/redis/describe/databases?runtime"},
+		{method: fiber.MethodGet, path: "/api/v1/dbaas/adapter/redis/databases/db1/diagnostics"},
+		{method: fiber.MethodPost, path: "/api/v1/dbaas/adapter/redis/databases/db1/keyspace-analysis"},
+		{method: fiber.MethodGet, path: "/api/v1/dbaas/adapter/redis/databases/db1/keyspace-analysis"},
+		{method: fiber.MethodGet, path: "/api/v1/dbaas/adapter/redis/deleted-databases"},
+		{method: fiber.MethodPost, path: "/api/v1/dbaas/adapter/redis/databases/db1/restore"},
+		{method: fiber.MethodGet, path: "/api/v1/dbaas/adapter/redis/quotas"},
+		{method: fiber.MethodPost, path: "/api/v1/dbaas/adapter/redis/databases/db1/upgrade"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			response, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != fiber.StatusUnauthorized {
+				t.Errorf("%s %s without credentials = %d, want %d", tt.method, tt.path, response.StatusCode, fiber.StatusUnauthorized)
+			}
+
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			request.SetBasicAuth("user", "wrong")
+			if response, err = app.Test(request); err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != fiber.StatusUnauthorized {
+				t.Errorf("%s %s with a wrong password = %d, want %d", tt.method, tt.path, response.StatusCode, fiber.StatusUnauthorized)
+			}
+		})
+	}
+}
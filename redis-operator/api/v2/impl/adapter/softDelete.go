@@ -0,0 +1,32 @@
+package adapter
+
+import (
+	"fmt"
+
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerSoftDeleteHandlers adds the restore of the soft deleted databases:
+// GET <root>/<appPath>/deleted-databases returns the databases which can be restored,
+// POST <root>/<appPath>/databases/:dbName/restore restores the database and returns its connection properties.
+func registerSoftDeleteHandlers(app *fiber.App, appPath string,
+	adminService *service.AdministrationService) {
+	deletedPath := fmt.Sprintf("/api/%s/dbaas/adapter%s/deleted-databases", adminService.GetVersion(), appPath)
+
+	app.Get(deletedPath, func(c *fiber.Ctx) error {
+		deleted, err := adminService.GetDeletedDatabases(requestContext(c))
+		if err != nil {
+			return err
+		}
+		return c.JSON(deleted)
+	})
+
+	app.Post(databasePath(adminService, appPath)+"/restore", func(c *fiber.Ctx) error {
+		restored, err := adminService.RestoreDatabase(requestContext(c), c.Params("dbName"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(restored)
+	})
+}
@@ -0,0 +1,158 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const statusRefreshPeriod = time.Minute
+
+// StatusReporter keeps the logical databases inventory and the DBaaS registration state in the CR status.
+// The refresh runs periodically and right after the adapter creates or drops a database.
+type StatusReporter struct {
+	kubeClient   client.Client
+	name         string
+	namespace    string
+	redisLabel   string
+	apiVersion   string
+	registration func() string
+	orphans      func() *v2.OrphansStatus
+	quotas       func(ctx context.Context) (*v2.QuotasStatus, error)
+	logger       *zap.Logger
+	trigger      chan struct{}
+	lastReported v2.DbaasRedisAdapterStatus
+}
+
+var _ service.InventoryObserver = &StatusReporter{}
+
+func NewStatusReporter(kubeClient client.Client, spec *v2.DbaasRedisAdapter, apiVersion string, logger *zap.Logger) *StatusReporter {
+	return &StatusReporter{
+		kubeClient: kubeClient,
+		name:       spec.Name,
+		namespace:  spec.Namespace,
+		redisLabel: spec.Spec.Redis.Label,
+		apiVersion: apiVersion,
+		logger:     logger,
+		trigger:    make(chan struct{}, 1),
+	}
+}
+
+// InventoryChanged requests a status refresh without waiting for the next period.
+func (r *StatusReporter) InventoryChanged() {
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts the refresh loop which lives until the adapter server context is done.
+// The orphaned objects are reported if orphans is set.
+func (r *StatusReporter) Run(ctx context.Context, registration func() string, orphans func() *v2.OrphansStatus,
+	quotas func(ctx context.Context) (*v2.QuotasStatus, error)) {
+	r.registration = registration
+	r.orphans = orphans
+	r.quotas = quotas
+	go func() {
+		ticker := time.NewTicker(statusRefreshPeriod)
+		defer ticker.Stop()
+		for {
+			r.refresh(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-r.trigger:
+			}
+		}
+	}()
+}
+
+func (r *StatusReporter) refresh(ctx context.Context) {
+	databases, err := r.databasesStatus(ctx)
+	if err != nil {
+		r.logger.Warn(fmt.Sprintf("Failed to collect logical databases for status, err: %v", err))
+		return
+	}
+	quotas, err := r.quotas(ctx)
+	if err != nil {
+		r.logger.Warn(fmt.Sprintf("Failed to collect the usage of quotas for status, err: %v", err))
+		return
+	}
+
+	status := v2.DbaasRedisAdapterStatus{
+		Databases: databases,
+		Dbaas: &v2.DbaasStatus{
+			ApiVersion:   r.apiVersion,
+			Registration: r.registration(),
+		},
+		Quotas: quotas,
+	}
+	if r.orphans != nil {
+		status.Orphans = r.orphans()
+	}
+	if reflect.DeepEqual(status, r.lastReported) {
+		return
+	}
+	reported := *status.DeepCopy()
+
+	now := metav1.Now()
+	status.Databases.LastUpdateTime = now
+	status.Dbaas.LastUpdateTime = now
+	if status.Orphans != nil {
+		status.Orphans.LastUpdateTime = now
+	}
+	if status.Quotas != nil {
+		status.Quotas.LastUpdateTime = now
+	}
+
+	//the patch is computed against the live CR, so the cleared fields, e.g. the empty not ready list, are removed
+	cr := &v2.DbaasRedisAdapter{}
+	if err := r.kubeClient.Get(ctx, types.NamespacedName{Name: r.name, Namespace: r.namespace}, cr); err != nil {
+		r.logger.Warn(fmt.Sprintf("Failed to read %s to update its status, err: %v", r.name, err))
+		return
+	}
+	patched := cr.DeepCopy()
+	patched.Status.Databases = status.Databases
+	patched.Status.Dbaas = status.Dbaas
+	patched.Status.Orphans = status.Orphans
+	patched.Status.Quotas = status.Quotas
+	if err := r.kubeClient.Status().Patch(ctx, patched, client.MergeFrom(cr)); err != nil {
+		r.logger.Warn(fmt.Sprintf("Failed to update %s status, err: %v", r.name, err))
+		return
+	}
+	r.lastReported = reported
+}
+
+func (r *StatusReporter) databasesStatus(ctx context.Context) (*v2.DatabasesStatus, error) {
+	deployments := &appsv1.DeploymentList{}
+	err := r.kubeClient.List(ctx, deployments,
+		client.InNamespace(r.namespace),
+		client.MatchingLabels{r.redisLabel: r.redisLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	databases := &v2.DatabasesStatus{Total: len(deployments.Items)}
+	for _, deployment := range deployments.Items {
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if deployment.Status.ReadyReplicas >= replicas {
+			databases.Ready++
+		} else {
+			databases.NotReady = append(databases.NotReady, deployment.Name)
+		}
+	}
+	return databases, nil
+}
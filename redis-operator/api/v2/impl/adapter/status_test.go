@@ -0,0 +1,48 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStatusReporterClearsNotReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cr := &v2.DbaasRedisAdapter{ObjectMeta: metav1.ObjectMeta{Name: "dbaas-redis-adapter", Namespace: "redis"}}
+	cr.Spec.Redis.Label = "redis"
+	cr.Status.Databases = &v2.DatabasesStatus{Total: 1, NotReady: []string{"pref-redisdb"}}
+	cr.Status.Quotas = &v2.QuotasStatus{Usage: []v2.QuotaUsage{{Scope: "adapter", Databases: 1}}}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).WithStatusSubresource(cr).Build()
+
+	reporter := NewStatusReporter(kubeClient, cr, "v2", zap.NewNop())
+	reporter.registration = func() string { return "registered" }
+	reporter.quotas = func(ctx context.Context) (*v2.QuotasStatus, error) { return nil, nil }
+	reporter.refresh(context.Background())
+
+	got := &v2.DbaasRedisAdapter{}
+	if err := kubeClient.Get(context.Background(), client.ObjectKeyFromObject(cr), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Databases == nil || got.Status.Databases.Total != 0 || len(got.Status.Databases.NotReady) != 0 {
+		t.Errorf("status.databases = %+v, want no databases", got.Status.Databases)
+	}
+	if got.Status.Quotas != nil {
+		t.Errorf("status.quotas = %+v, want it removed", got.Status.Quotas)
+	}
+	if got.Status.Dbaas == nil || got.Status.Dbaas.Registration != "registered" {
+		t.Errorf("status.dbaas = %+v, want the registration", got.Status.Dbaas)
+	}
+}
@@ -0,0 +1,25 @@
+package adapter
+
+import (
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerUpgradeHandler adds the upgrade of a logical database to a newer allowed Redis version:
+// POST <root>/<appPath>/databases/:dbName/upgrade upgrades the database to the version from the body
+// and returns the running version once the database is ready.
+func registerUpgradeHandler(app *fiber.App, appPath string,
+	adminService *service.AdministrationService) {
+	app.Post(databasePath(adminService, appPath)+"/upgrade", func(c *fiber.Ctx) error {
+		request := service.UpgradeRequest{}
+		if err := c.BodyParser(&request); err != nil {
+			return customEntity.NewInvalidArgumentError(err.Error())
+		}
+		upgraded, err := adminService.UpgradeDatabase(requestContext(c), c.Params("dbName"), request)
+		if err != nil {
+			return err
+		}
+		return c.JSON(upgraded)
+	})
+}
@@ -0,0 +1,142 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	netcrackerv1 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	prometheusRuleName = serviceName + "-rules"
+	prometheusGroup    = "monitoring.coreos.com"
+	prometheusVersion  = "v1"
+	prometheusRuleKind = "PrometheusRule"
+	defaultInterval    = "30s"
+)
+
+// monitorKind returns the Prometheus Operator resource used to scrape the agent.
+func monitorKind(spec netcrackerv1.Monitoring) string {
+	if spec.MonitorKind == netcrackerv1.MonitorKindPodMonitor {
+		return netcrackerv1.MonitorKindPodMonitor
+	}
+	return netcrackerv1.MonitorKindServiceMonitor
+}
+
+// prometheusKindInstalled reports whether the Prometheus Operator CRD of the kind is present in the cluster.
+func prometheusKindInstalled(kubeClient client.Client, kind string) (bool, error) {
+	_, err := kubeClient.RESTMapper().RESTMapping(schema.GroupKind{Group: prometheusGroup, Kind: kind}, prometheusVersion)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// deletePrometheusObject removes the Prometheus Operator resource if it exists,
+// e.g. the ServiceMonitor when the metric collector is switched to InfluxDB.
+func deletePrometheusObject(kubeClient client.Client, kind string, name string, namespace string) error {
+	installed, err := prometheusKindInstalled(kubeClient, kind)
+	if err != nil || !installed {
+		return err
+	}
+	object := newPrometheusObject(kind, name, namespace, nil)
+	err = kubeClient.Delete(context.TODO(), object)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func newPrometheusObject(kind string, name string, namespace string, spec map[string]interface{}) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	object.SetGroupVersionKind(schema.GroupVersionKind{Group: prometheusGroup, Version: prometheusVersion, Kind: kind})
+	object.SetName(name)
+	object.SetNamespace(namespace)
+	object.SetLabels(map[string]string{
+		"app.kubernetes.io/component":  "monitoring",
+		"app.kubernetes.io/managed-by": "monitoring-operator",
+		"app.kubernetes.io/name":       name,
+		"k8s-app":                      name,
+		"name":                         serviceName,
+	})
+	return object
+}
+
+// Monitor returns the ServiceMonitor or PodMonitor which scrapes /metrics of the agent.
+func Monitor(cr *netcrackerv1.DbaasRedisAdapter) *unstructured.Unstructured {
+	interval := cr.Spec.Monitoring.MonitoringInterval
+	if interval == "" {
+		interval = defaultInterval
+	}
+	endpoint := map[string]interface{}{
+		"interval": interval,
+		"port":     metricsPortName,
+		"path":     "/metrics",
+	}
+	spec := map[string]interface{}{
+		"jobLabel": "k8s-app",
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{cr.Namespace},
+		},
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": serviceName},
+		},
+	}
+
+	kind := monitorKind(cr.Spec.Monitoring)
+	if kind == netcrackerv1.MonitorKindPodMonitor {
+		spec["podMetricsEndpoints"] = []interface{}{endpoint}
+	} else {
+		spec["endpoints"] = []interface{}{endpoint}
+	}
+	return newPrometheusObject(kind, serviceName, cr.Namespace, spec)
+}
+
+// PrometheusRule returns the default alerts on the metrics of the agent.
+func PrometheusRule(cr *netcrackerv1.DbaasRedisAdapter) *unstructured.Unstructured {
+	selector := fmt.Sprintf(`namespace="%s"`, cr.Namespace)
+	rules := []interface{}{
+		alert("RedisInstanceDown", "high", "1m",
+			fmt.Sprintf(`redis_scrape_success{%s} == 0`, selector),
+			"Redis instance {{ $labels.server }} can't be scraped"),
+		alert("RedisMemoryPressure", "warning", "5m",
+			fmt.Sprintf(`redis_used_memory{%[1]s} / (redis_maxmemory{%[1]s} > 0) > 0.9`, selector),
+			"Redis instance {{ $labels.server }} uses more than 90% of maxmemory"),
+		alert("RedisKeysEvicted", "warning", "5m",
+			fmt.Sprintf(`increase(redis_evicted_keys{%s}[5m]) > 0`, selector),
+			"Redis instance {{ $labels.server }} evicts keys because of maxmemory"),
+		alert("RedisRejectedConnections", "warning", "5m",
+			fmt.Sprintf(`increase(redis_rejected_connections{%s}[5m]) > 0`, selector),
+			"Redis instance {{ $labels.server }} rejects connections because of maxclients"),
+	}
+	spec := map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  fmt.Sprintf("%s-%s", cr.Namespace, serviceName),
+				"rules": rules,
+			},
+		},
+	}
+	return newPrometheusObject(prometheusRuleKind, prometheusRuleName, cr.Namespace, spec)
+}
+
+func alert(name string, severity string, duration string, expr string, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": name,
+		"expr":  expr,
+		"for":   duration,
+		"labels": map[string]interface{}{
+			"severity": severity,
+			"service":  serviceName,
+		},
+		"annotations": map[string]interface{}{
+			"summary":     description,
+			"description": description,
+		},
+	}
+}
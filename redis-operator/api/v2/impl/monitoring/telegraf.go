@@ -0,0 +1,87 @@
+package monitoring
+
+import (
+	"context"
+	"strings"
+
+	utils2 "github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
+	netcrackerv1 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const telegrafOutputsKey = "telegraf-outputs"
+
+// TelegrafOutputs renders the outputs section of telegraf.conf for the metric collector.
+// InfluxDB credentials are not rendered, Telegraf reads them from the environment set by influxDBEnvs.
+func TelegrafOutputs(spec netcrackerv1.Monitoring) string {
+	lines := []string{"[outputs]", ""}
+	if !spec.UsesInfluxDB() {
+		lines = append(lines,
+			"[[ outputs.prometheus_client ]]",
+			`  listen = ":9273"`)
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines,
+		"[[outputs.influxdb]]",
+		`  urls = ["$INFLUXDB_HOST"]`,
+		`  database = "$INFLUXDB_DATABASE"`,
+		`  precision = "s"`,
+		`  write_consistency = "any"`,
+		`  timeout = "20s"`)
+	if spec.InfluxDB != nil && spec.InfluxDB.RetentionPolicy != "" {
+		lines = append(lines, `  retention_policy = "$INFLUXDB_RETENTION_POLICY"`)
+	}
+	if spec.InfluxDB != nil && (spec.InfluxDB.SecretName != "" || spec.InfluxDB.User != "") {
+		lines = append(lines, `  username = "$INFLUXDB_USER"`)
+	}
+	if spec.InfluxDB != nil && spec.InfluxDB.SecretName != "" {
+		lines = append(lines, `  password = "$INFLUXDB_PASSWORD"`)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// influxDBEnvs returns the InfluxDB connection settings for the outputs rendered by TelegrafOutputs.
+// The user and the password are taken from the secret, the user from the spec is used if there is no secret.
+func influxDBEnvs(spec netcrackerv1.Monitoring) []corev1.EnvVar {
+	if !spec.UsesInfluxDB() || spec.InfluxDB == nil {
+		return nil
+	}
+	settings := spec.InfluxDB
+	envs := []corev1.EnvVar{
+		utils2.GetPlainTextEnvVar("INFLUXDB_HOST", settings.Host),
+		utils2.GetPlainTextEnvVar("INFLUXDB_DATABASE", settings.Database),
+		utils2.GetPlainTextEnvVar("INFLUXDB_RETENTION_POLICY", settings.RetentionPolicy),
+	}
+	if settings.SecretName != "" {
+		envs = append(envs,
+			utils2.GetSecretEnvVar("INFLUXDB_USER", settings.SecretName, "username"),
+			utils2.GetSecretEnvVar("INFLUXDB_PASSWORD", settings.SecretName, "password"))
+	} else if settings.User != "" {
+		envs = append(envs, utils2.GetPlainTextEnvVar("INFLUXDB_USER", settings.User))
+	}
+	return envs
+}
+
+// updateTelegrafOutputs sets the outputs section in the agent ConfigMap, other sections are managed by the chart.
+// It returns false if the ConfigMap does not exist.
+func updateTelegrafOutputs(kubeClient client.Client, cr *netcrackerv1.DbaasRedisAdapter) (bool, error) {
+	configMap := &corev1.ConfigMap{}
+	err := kubeClient.Get(context.TODO(), types.NamespacedName{Name: configMapName, Namespace: cr.Namespace}, configMap)
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	outputs := TelegrafOutputs(cr.Spec.Monitoring)
+	if configMap.Data[telegrafOutputsKey] == outputs {
+		return true, nil
+	}
+	patch := client.MergeFrom(configMap.DeepCopy())
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[telegrafOutputsKey] = outputs
+	return true, kubeClient.Patch(context.TODO(), configMap, patch)
+}
@@ -1,6 +1,9 @@
 package monitoring
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	utils2 "github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
@@ -12,18 +15,19 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v13 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
-	serviceName   = "redis-monitoring-agent"
-	configMapName = serviceName + "-config"
+	serviceName     = "redis-monitoring-agent"
+	configMapName   = serviceName + "-config"
+	metricsPort     = 9121
+	metricsPortName = "metrics"
 )
 
-var execCommand = []string{"/bin/sh", "-c", "/health.sh"}
-
 type MonitoringCompound struct {
 	core.MicroServiceCompound
 }
@@ -37,25 +41,35 @@ func (r *MonitoringBuilder) Build(ctx core.ExecutionContext) core.Executable {
 	compound := MonitoringCompound{}
 	compound.ServiceName = serviceName
 	compound.CalcDeployType = func(ctx core.ExecutionContext) (deployType core.MicroServiceDeployType, err error) {
-		return core.CleanDeploy, nil
+		return core.Update, nil
 	}
 	compound.AddStep(&utils.SimpleCtxExecutable{
 		StepName: "Monitoring Service",
 		ExecuteFunc: func(ctx core.ExecutionContext, cr *netcrackerv1.DbaasRedisAdapter, log *zap.Logger) error {
-			kubeClient := ctx.Get(constants.ContextClient).(client.Client)
 			template := Service(cr)
 
-			core.DeleteRuntimeObject(kubeClient, &corev1.Service{
-				ObjectMeta: template.ObjectMeta,
-			})
-
-			err := utils.CreateRuntimeObjectContextWrapper(ctx, template, template.ObjectMeta)
+			err := utils.ApplyRuntimeObject(ctx, template)
 			core.PanicError(err, log.Error, "Monitoring service creation failed")
 
 			return nil
 		},
 	})
 
+	compound.AddStep(&utils.SimpleCtxExecutable{
+		StepName: "Monitoring Telegraf Outputs",
+		ExecuteFunc: func(ctx core.ExecutionContext, cr *netcrackerv1.DbaasRedisAdapter, log *zap.Logger) error {
+			kubeClient := ctx.Get(constants.ContextClient).(client.Client)
+
+			found, err := updateTelegrafOutputs(kubeClient, cr)
+			core.PanicError(err, log.Error, "Monitoring Telegraf outputs update failed")
+			if !found {
+				log.Warn(fmt.Sprintf("ConfigMap %s is not found, Telegraf outputs are not updated", configMapName))
+			}
+
+			return nil
+		},
+	})
+
 	compound.AddStep(&utils.SimpleCtxExecutable{
 		StepName: "Monitoring Deployment",
 		ExecuteFunc: func(ctx core.ExecutionContext, cr *netcrackerv1.DbaasRedisAdapter, log *zap.Logger) error {
@@ -64,11 +78,11 @@ func (r *MonitoringBuilder) Build(ctx core.ExecutionContext) core.Executable {
 
 			deployment := MonitoringDeployment(cr)
 
-			delErr := helperImpl.DeleteDeploymentAndPods(deployment.Name, request.Namespace, cr.Spec.WaitTimeout)
-			core.PanicError(delErr, log.Error, "Deletion failed")
-
-			err := utils.CreateRuntimeObjectContextWrapper(ctx, deployment, deployment.ObjectMeta)
+			recreate, err := utils.ApplyDeployment(ctx, deployment)
 			core.PanicError(err, log.Error, "Monitoring deployment creation failed")
+			if !recreate {
+				return nil
+			}
 
 			log.Debug("Waiting for monitoring is ready")
 			err = helperImpl.WaitForPodsReady(
@@ -82,9 +96,47 @@ func (r *MonitoringBuilder) Build(ctx core.ExecutionContext) core.Executable {
 		},
 	})
 
+	compound.AddStep(&utils.SimpleCtxExecutable{
+		StepName: "Monitoring Prometheus Resources",
+		ExecuteFunc: func(ctx core.ExecutionContext, cr *netcrackerv1.DbaasRedisAdapter, log *zap.Logger) error {
+			kubeClient := ctx.Get(constants.ContextClient).(client.Client)
+			kind := monitorKind(cr.Spec.Monitoring)
+
+			//Monitors of the not used kind are removed, e.g. after switching to PodMonitor or InfluxDB
+			for _, monitor := range []string{netcrackerv1.MonitorKindServiceMonitor, netcrackerv1.MonitorKindPodMonitor} {
+				if monitor != kind || cr.Spec.Monitoring.UsesInfluxDB() {
+					err := deletePrometheusObject(kubeClient, monitor, serviceName, cr.Namespace)
+					core.PanicError(err, log.Error, fmt.Sprintf("Monitoring %s deletion failed", monitor))
+				}
+			}
+			if cr.Spec.Monitoring.UsesInfluxDB() {
+				err := deletePrometheusObject(kubeClient, prometheusRuleKind, prometheusRuleName, cr.Namespace)
+				core.PanicError(err, log.Error, "Monitoring PrometheusRule deletion failed")
+				return nil
+			}
+
+			for _, object := range []*unstructured.Unstructured{Monitor(cr), PrometheusRule(cr)} {
+				installed, err := prometheusKindInstalled(kubeClient, object.GetKind())
+				core.PanicError(err, log.Error, fmt.Sprintf("Failed checking %s CRD", object.GetKind()))
+				if !installed {
+					log.Info(fmt.Sprintf("%s CRD is not installed, %s is not created", object.GetKind(), object.GetName()))
+					continue
+				}
+				err = utils.ApplyRuntimeObject(ctx, object)
+				core.PanicError(err, log.Error, fmt.Sprintf("Monitoring %s creation failed", object.GetKind()))
+			}
+
+			return nil
+		},
+	})
+
 	return &compound
 }
 
+func (r *MonitoringCompound) Execute(ctx core.ExecutionContext) error {
+	return utils.ExecuteComponent(ctx, netcrackerv1.ComponentMonitoring, r.MicroServiceCompound.Execute)
+}
+
 func (r *MonitoringCompound) Condition(ctx core.ExecutionContext) (bool, error) {
 	return true, nil
 }
@@ -97,12 +149,15 @@ func MonitoringDeployment(cr *netcrackerv1.DbaasRedisAdapter) *appsv1.Deployment
 
 	var envs []corev1.EnvVar
 
-	readinessProbe := &v13.Probe{
-		ProbeHandler: v13.ProbeHandler{
-			Exec: &v13.ExecAction{
-				Command: execCommand,
-			},
+	healthProbeHandler := v13.ProbeHandler{
+		HTTPGet: &v13.HTTPGetAction{
+			Path: "/health",
+			Port: intstr.FromInt32(metricsPort),
 		},
+	}
+
+	readinessProbe := &v13.Probe{
+		ProbeHandler:        healthProbeHandler,
 		InitialDelaySeconds: 10,
 		TimeoutSeconds:      10,
 		PeriodSeconds:       10,
@@ -111,11 +166,7 @@ func MonitoringDeployment(cr *netcrackerv1.DbaasRedisAdapter) *appsv1.Deployment
 	}
 
 	livenessProbe := &v13.Probe{
-		ProbeHandler: v13.ProbeHandler{
-			Exec: &v13.ExecAction{
-				Command: execCommand,
-			},
-		},
+		ProbeHandler:        healthProbeHandler,
 		InitialDelaySeconds: 20,
 		TimeoutSeconds:      10,
 		PeriodSeconds:       10,
@@ -130,7 +181,11 @@ func MonitoringDeployment(cr *netcrackerv1.DbaasRedisAdapter) *appsv1.Deployment
 		utils2.GetPlainTextEnvVar("REDIS_INSTANCES_LABEL_SELECTOR", cr.Spec.Redis.Parameters.Label),
 		utils2.GetPlainTextEnvVar("TELEGRAF_CONF_CONFIGMAP", "redis-monitoring-agent-config"),
 		utils2.GetPlainTextEnvVar("CMD_MONITORING_COMMAND", telegrafProcessCmd),
+		utils2.GetPlainTextEnvVar("METRICS_PORT", strconv.Itoa(metricsPort)),
+		// Prometheus scrapes the agent directly, Telegraf is needed only to send metrics to InfluxDB
+		utils2.GetPlainTextEnvVar("TELEGRAF_ENABLED", strconv.FormatBool(spec.UsesInfluxDB())),
 	)
+	envs = append(envs, influxDBEnvs(spec)...)
 
 	var tolerations []corev1.Toleration
 	if cr.Spec.Policies != nil {
@@ -205,6 +260,11 @@ func MonitoringDeployment(cr *netcrackerv1.DbaasRedisAdapter) *appsv1.Deployment
 									ContainerPort: 9273,
 									Protocol:      "TCP",
 								},
+								{
+									Name:          metricsPortName,
+									ContainerPort: metricsPort,
+									Protocol:      "TCP",
+								},
 							},
 							ReadinessProbe: readinessProbe,
 							LivenessProbe:  livenessProbe,
@@ -280,6 +340,14 @@ func Service(cr *netcrackerv1.DbaasRedisAdapter) *corev1.Service {
 						IntVal: 9273,
 					},
 				},
+				{
+					Name:     metricsPortName,
+					Port:     metricsPort,
+					Protocol: "TCP",
+					TargetPort: intstr.IntOrString{
+						IntVal: metricsPort,
+					},
+				},
 			},
 			Selector: map[string]string{"app": serviceName},
 			Type:     corev1.ServiceTypeClusterIP,
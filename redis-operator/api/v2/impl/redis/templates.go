@@ -1,6 +1,9 @@
 package redis
 
 import (
+	"crypto/sha256"
+	"fmt"
+
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	utils2 "github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
@@ -36,23 +39,21 @@ func (r *RedisBuilder) Build(ctx core.ExecutionContext) core.Executable {
 	compound := RedisCompound{}
 	compound.ServiceName = serviceName
 	compound.CalcDeployType = func(ctx core.ExecutionContext) (deployType core.MicroServiceDeployType, err error) {
-		return core.CleanDeploy, nil
+		return core.Update, nil
 	}
 
+	// Redis reads the config on start only, so its hash is a part of the pod template
+	var configString string
+
 	compound.AddStep(&utils.SimpleCtxExecutable{
 		StepName: "Redis Service",
 		ExecuteFunc: func(ctx core.ExecutionContext, cr *netcrackerv1.DbaasRedisAdapter, log *zap.Logger) error {
-			kubeClient := ctx.Get(constants.ContextClient).(client.Client)
 			request := ctx.Get(constants.ContextRequest).(reconcile.Request)
 			template := templates.GetRedisServiceTemplate(
 				core2.Redis,
 				request.Namespace, spec.Spec.PartOf, spec.Spec.ManagedBy)
 
-			core.DeleteRuntimeObject(kubeClient, &corev1.Service{
-				ObjectMeta: template.ObjectMeta,
-			})
-
-			err := utils.CreateRuntimeObjectContextWrapper(ctx, template, template.ObjectMeta)
+			err := utils.ApplyRuntimeObject(ctx, template)
 			core.PanicError(err, log.Error, "Redis service creation failed")
 
 			return nil
@@ -65,17 +66,21 @@ func (r *RedisBuilder) Build(ctx core.ExecutionContext) core.Executable {
 			request := ctx.Get(constants.ContextRequest).(reconcile.Request)
 			client := ctx.Get(constants.ContextClient).(client.Client)
 
-			redisConfig := service.GetRedisDefaultConfigMap(client, request.Namespace, log)
-			configString := service.RedisMapConfigToString(redisConfig)
+			redisConfig, err := service.GetRedisDefaultConfig(client, request.Namespace)
+			core.PanicError(err, log.Error, "Redis default config reading failed")
+			maxmemory, err := service.RedisMaxmemory(cr.Spec.Redis.Maxmem, cr.Spec.Redis.MaxmemOverhead, *cr.Spec.Redis.Resources)
+			core.PanicError(err, log.Error, "Redis maxmemory calculation failed")
+			if maxmemory != "" {
+				err = redisConfig.Set("maxmemory", maxmemory)
+				core.PanicError(err, log.Error, "Redis maxmemory setting failed")
+			}
+			configString = redisConfig.String()
 			template := templates.GetRedisConfigTemplate(
 				core2.Redis,
 				request.Namespace,
 				configString)
 
-			core.DeleteRuntimeObjectWithCheck(client, &corev1.ConfigMap{
-				ObjectMeta: template.ObjectMeta}, 60)
-
-			err := utils.CreateRuntimeObjectContextWrapper(ctx, template, template.ObjectMeta)
+			err = utils.ApplyRuntimeObject(ctx, template)
 			core.PanicError(err, log.Error, "Redis ConfigMap creation failed")
 
 			return nil
@@ -94,7 +99,7 @@ func (r *RedisBuilder) Build(ctx core.ExecutionContext) core.Executable {
 				tolerations = cr.Spec.Policies.Tolerations
 			}
 
-			envs := common.GetRedisEnvs(redisSpec.TLS.TLS)
+			envs := common.GetRedisEnvs(redisSpec.TLS)
 			envs = append(envs, utils2.GetSecretEnvVar("REDIS_PASSWORD", redisSpec.SecretName, constants.Password))
 			deployment := templates.GetRedisDeploymentTemplate(
 				core2.Redis,
@@ -113,11 +118,15 @@ func (r *RedisBuilder) Build(ctx core.ExecutionContext) core.Executable {
 				spec.Spec.Redis.PriorityClassName, spec.Spec.PartOf, spec.Spec.ManagedBy,
 			)
 
-			delErr := helperImpl.DeleteDeploymentAndPods(deployment.Name, request.Namespace, cr.Spec.WaitTimeout)
-			core.PanicError(delErr, log.Error, "Deletion failed")
+			deployment.Spec.Template.Annotations = map[string]string{
+				service.ConfigHashAnnotation: fmt.Sprintf("%x", sha256.Sum256([]byte(configString))),
+			}
 
-			err := utils.CreateRuntimeObjectContextWrapper(ctx, deployment, deployment.ObjectMeta)
+			recreate, err := utils.ApplyDeployment(ctx, deployment)
 			core.PanicError(err, log.Error, "Redis deployment creation failed")
+			if !recreate {
+				return nil
+			}
 
 			log.Debug("Waiting for Redis is ready")
 			err = helperImpl.WaitForPodsReady(
@@ -133,3 +142,7 @@ func (r *RedisBuilder) Build(ctx core.ExecutionContext) core.Executable {
 
 	return &compound
 }
+
+func (r *RedisCompound) Execute(ctx core.ExecutionContext) error {
+	return utils.ExecuteComponent(ctx, netcrackerv1.ComponentRedis, r.MicroServiceCompound.Execute)
+}
@@ -66,6 +66,10 @@ func (r *RobotBuilder) Build(ctx core.ExecutionContext) core.Executable {
 	return &compound
 }
 
+func (r *RobotTestsCompound) Execute(ctx core.ExecutionContext) error {
+	return utils.ExecuteComponent(ctx, netcrackerv1.ComponentRobotTests, r.MicroServiceCompound.Execute)
+}
+
 func RobotDeployment(cr *netcrackerv1.DbaasRedisAdapter) *v1.Deployment {
 	spec := cr.Spec.RobotTests
 	image := spec.DockerImage
@@ -12,7 +12,9 @@ import (
 	"github.com/Netcracker/qubership-redis/redis-operator/api/v2/impl/robotTests"
 	"github.com/Netcracker/qubership-redis/redis-operator/api/v2/impl/utils"
 	"github.com/Netcracker/qubership-redis/redis-operator/common"
+	core2 "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/core"
 	rc "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
 	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/apps/v1"
@@ -65,7 +67,7 @@ func (r *RedisServiceBuilder) Build(ctx core.ExecutionContext) core.Executable {
 			dcs := &v1.DeploymentList{}
 			opts := []client.ListOption{
 				client.InNamespace(request.Namespace),
-				client.MatchingLabelsSelector{labels.SelectorFromSet(map[string]string{spec.Spec.Redis.Label: spec.Spec.Redis.Label})},
+				client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(map[string]string{spec.Spec.Redis.Label: spec.Spec.Redis.Label})},
 			}
 
 			errList := kubeClient.List(context.TODO(), dcs, opts...)
@@ -73,14 +75,24 @@ func (r *RedisServiceBuilder) Build(ctx core.ExecutionContext) core.Executable {
 
 			for _, dc := range dcs.Items {
 				redisName := dc.ObjectMeta.Name
+				if !spec.Spec.Dbaas.Install && redisName == core2.Redis {
+					// Standalone Redis is already updated by its own component
+					continue
+				}
 				envs := dc.Spec.Template.Spec.Containers[0].Env
-				envs = common.MergeEnvs(envs, common.GetRedisEnvs(spec.Spec.Redis.TLS.TLS))
+				envs = common.MergeEnvs(envs, common.GetRedisEnvs(spec.Spec.Redis.TLS))
 				var tolerations []corev1.Toleration
 				if cr.Spec.Policies != nil {
 					tolerations = cr.Spec.Policies.Tolerations
 				}
 
-				redisDC := templates.GetRedisDeploymentTemplate(redisName, request.Namespace, spec.Spec.Redis.DockerImage,
+				// the database pinned to a Redis version keeps its image
+				image := spec.Spec.Redis.DockerImage
+				if pinned, ok := service.PinnedRedisImage(spec.Spec.Dbaas.Adapter.RedisVersions, &dc); ok {
+					image = pinned
+				}
+
+				redisDC := templates.GetRedisDeploymentTemplate(redisName, request.Namespace, image,
 					spec.Spec.Redis.Args,
 					envs,
 					*spec.Spec.Redis.Resources,
@@ -95,13 +107,44 @@ func (r *RedisServiceBuilder) Build(ctx core.ExecutionContext) core.Executable {
 					spec.Spec.PartOf, spec.Spec.ManagedBy,
 				)
 
+				// keep config revision and the restart trigger set by the config propagation
+				redisDC.Annotations = dc.Annotations
+				// keep the classifier and logical database labels set at the database creation
+				for _, label := range append([]string{templates.LogicalDatabase}, templates.ClassifierLabels...) {
+					if value, ok := dc.Labels[label]; ok {
+						redisDC.Labels[label] = value
+					}
+				}
+				// keep the running version set by the adapter while the image is not changed,
+				// the labels are copied because the template shares them
+				if version, ok := dc.Labels[templates.AppVersion]; ok && dc.Spec.Template.Spec.Containers[0].Image == image {
+					deploymentLabels := map[string]string{}
+					for key, value := range redisDC.Labels {
+						deploymentLabels[key] = value
+					}
+					deploymentLabels[templates.AppVersion] = version
+					redisDC.Labels = deploymentLabels
+				}
+				redisDC.Spec.Template.Annotations = dc.Spec.Template.Annotations
+				// keep the data volume claim of the database created with soft delete
+				if claim := templates.DataVolumeClaim(&dc); claim != "" {
+					templates.SetDataVolumeClaim(redisDC, claim)
+				}
+				// keep the resources and scheduling of the database created with a size profile
+				if _, ok := dc.Annotations[service.ProfileAnnotation]; ok {
+					redisDC.Spec.Template.Spec.Containers[0].Resources = dc.Spec.Template.Spec.Containers[0].Resources
+					redisDC.Spec.Template.Spec.NodeSelector = dc.Spec.Template.Spec.NodeSelector
+					redisDC.Spec.Template.Spec.Tolerations = dc.Spec.Template.Spec.Tolerations
+				}
+
 				if spec.Spec.Redis.TLS.ClusterIssuerName != "" {
-					common.UpdateCertificate(spec.Spec.Redis.TLS.Enabled, spec.Spec.Redis.TLS.ClusterIssuerName, dc.ObjectMeta.Name, request.Namespace, kubeClient, runtimeScheme)
+					common.UpdateCertificate(spec.Spec.Redis.TLS.Enabled, spec.Spec.Redis.TLS.ClusterIssuerName, dc.ObjectMeta.Name, request.Namespace, kubeClient, runtimeScheme,
+						map[string]string{templates.LogicalDatabase: dc.ObjectMeta.Name})
 				}
 
 				var updateErr error
 				for i := 0; i < 3; i++ {
-					updateErr = core.CreateOrUpdateRuntimeObject(kubeClient, runtimeScheme, nil, redisDC, redisDC.ObjectMeta, true)
+					_, updateErr = utils.ApplyDeployment(ctx, redisDC)
 					if updateErr == nil {
 						break
 					}
@@ -155,6 +198,17 @@ func (r *RedisPreDeployBuilder) Build(ctx core.ExecutionContext) core.Executable
 			})
 
 		}
+
+		compound.AddStep(&utils.SimpleCtxExecutable{
+			StepName: "Redis Config Propagation",
+			ExecuteFunc: func(ctx core.ExecutionContext, cr *v2.DbaasRedisAdapter, log *zap.Logger) error {
+				adminService, err := adapter.PrepareAdminService(spec, redisClient, kubeClient, runtimeScheme, log, nil, request.Namespace, "")
+				if err != nil {
+					return err
+				}
+				return adminService.PropagateDefaultConfig(context.Background())
+			},
+		})
 	}
 
 	return compound
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const contextRolloutDecisions = "ContextRolloutDecisions"
+
+// ApplyRuntimeObject updates the live object in place or creates it if it does not exist.
+// Services keep their allocated cluster IPs, so clients do not lose connectivity.
+func ApplyRuntimeObject(ctx core.ExecutionContext, object client.Object) error {
+	kubeClient := ctx.Get(constants.ContextClient).(client.Client)
+
+	live := object.DeepCopyObject().(client.Object)
+	err := kubeClient.Get(context.TODO(), client.ObjectKeyFromObject(object), live)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		object.SetResourceVersion(live.GetResourceVersion())
+		if service, ok := object.(*corev1.Service); ok {
+			service.Spec.ClusterIP = live.(*corev1.Service).Spec.ClusterIP
+			service.Spec.ClusterIPs = live.(*corev1.Service).Spec.ClusterIPs
+		}
+	}
+
+	return CreateRuntimeObjectContextWrapper(ctx, object, metav1.ObjectMeta{Name: object.GetName(), Namespace: object.GetNamespace()})
+}
+
+// ApplyDeployment updates the deployment in place and returns true if its pods are going to be recreated.
+// Pods are recreated only when the pod template differs from the one applied last time, its hash is kept
+// in the Deployment annotation. The hash is added to the pod labels only together with a template change,
+// so the new pods can be awaited with WaitForPodsReady and adding the label doesn't recreate the pods.
+// Deployments applied before the hash was introduced get the annotation, their pod template is applied
+// without the label and their pods are not awaited.
+func ApplyDeployment(ctx core.ExecutionContext, deployment *appsv1.Deployment) (bool, error) {
+	kubeClient := ctx.Get(constants.ContextClient).(client.Client)
+	log := ctx.Get(constants.ContextLogger).(*zap.Logger)
+
+	podLabels := map[string]string{}
+	for key, value := range deployment.Spec.Template.Labels {
+		if key != TemplateHash {
+			podLabels[key] = value
+		}
+	}
+	deployment.Spec.Template.Labels = podLabels
+
+	hash, err := PodTemplateHash(&deployment.Spec.Template)
+	if err != nil {
+		return false, err
+	}
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[TemplateHash] = hash
+
+	var decision string
+	recreate := true
+	live := &appsv1.Deployment{}
+	err = kubeClient.Get(context.TODO(), client.ObjectKeyFromObject(deployment), live)
+	switch {
+	case errors.IsNotFound(err):
+		decision = fmt.Sprintf("Deployment %s is created", deployment.Name)
+	case err != nil:
+		return false, err
+	default:
+		deployment.ResourceVersion = live.ResourceVersion
+		liveHash, hashed := live.Annotations[TemplateHash]
+		switch {
+		case !hashed:
+			recreate = false
+			decision = fmt.Sprintf("Deployment %s has no pod template hash yet, the hash is recorded without waiting for pods", deployment.Name)
+		case liveHash == hash:
+			recreate = false
+			decision = fmt.Sprintf("Deployment %s pod template is not changed, pods are kept", deployment.Name)
+			//the label of the pods is kept as it is, removing it would recreate them
+			if liveLabel, ok := live.Spec.Template.Labels[TemplateHash]; ok {
+				deployment.Spec.Template.Labels[TemplateHash] = liveLabel
+			}
+		default:
+			decision = fmt.Sprintf("Deployment %s pod template is changed, pods are recreated", deployment.Name)
+		}
+	}
+	if recreate {
+		deployment.Spec.Template.Labels[TemplateHash] = hash
+	}
+	log.Info(decision)
+	addRolloutDecision(ctx, decision)
+
+	return recreate, CreateRuntimeObjectContextWrapper(ctx, deployment, deployment.ObjectMeta)
+}
+
+// PodTemplateHash returns a short stable hash of the pod template.
+func PodTemplateHash(template *corev1.PodTemplateSpec) (string, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:10], nil
+}
+
+func addRolloutDecision(ctx core.ExecutionContext, decision string) {
+	decisions, _ := ctx.Get(contextRolloutDecisions).([]string)
+	ctx.Set(contextRolloutDecisions, append(decisions, decision))
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestDeployment(image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "redis"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "redis"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"name": "redis"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "redis", Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func newTestApplyContext(objects ...client.Object) (core.ExecutionContext, client.Client) {
+	kubeClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).Build()
+	ctx := core.NewInitExecutionContext(map[string]interface{}{
+		constants.ContextClient:        kubeClient,
+		constants.ContextLogger:        zap.NewNop(),
+		constants.ContextSchema:        clientgoscheme.Scheme,
+		constants.ContextSpec:          &v2.DbaasRedisAdapter{},
+		constants.KubernetesHelperImpl: &core.DefaultKubernetesHelperImpl{Client: kubeClient},
+	})
+	return ctx, kubeClient
+}
+
+func TestApplyDeployment(t *testing.T) {
+	hash, err := PodTemplateHash(&newTestDeployment("redis:7").Spec.Template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed := func(image, annotation, label string) *appsv1.Deployment {
+		deployment := newTestDeployment(image)
+		deployment.Annotations = map[string]string{TemplateHash: annotation}
+		if label != "" {
+			deployment.Spec.Template.Labels[TemplateHash] = label
+		}
+		return deployment
+	}
+
+	tests := []struct {
+		name         string
+		live         *appsv1.Deployment
+		wantRecreate bool
+		wantLabel    string
+		wantImage    string
+	}{
+		{
+			name:         "created",
+			wantRecreate: true,
+			wantLabel:    hash,
+			wantImage:    "redis:7",
+		},
+		{
+			name:         "unchanged",
+			live:         hashed("redis:7", hash, hash),
+			wantRecreate: false,
+			wantLabel:    hash,
+			wantImage:    "redis:7",
+		},
+		{
+			name:         "unchanged after a rollout with an older label",
+			live:         hashed("redis:7", hash, "0123456789"),
+			wantRecreate: false,
+			wantLabel:    "0123456789",
+			wantImage:    "redis:7",
+		},
+		{
+			name:         "changed",
+			live:         hashed("redis:6", "0123456789", "0123456789"),
+			wantRecreate: true,
+			wantLabel:    hash,
+			wantImage:    "redis:7",
+		},
+		{
+			name:         "legacy deployment without the hash",
+			live:         newTestDeployment("redis:7"),
+			wantRecreate: false,
+			wantImage:    "redis:7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []client.Object
+			if tt.live != nil {
+				objects = append(objects, tt.live)
+			}
+			ctx, kubeClient := newTestApplyContext(objects...)
+
+			recreate, err := ApplyDeployment(ctx, newTestDeployment("redis:7"))
+			if err != nil {
+				t.Fatalf("ApplyDeployment() error = %v", err)
+			}
+			if recreate != tt.wantRecreate {
+				t.Errorf("ApplyDeployment() recreate = %v, want %v", recreate, tt.wantRecreate)
+			}
+
+			applied := &appsv1.Deployment{}
+			if err := kubeClient.Get(context.TODO(), client.ObjectKey{Name: "redis", Namespace: "redis"}, applied); err != nil {
+				t.Fatal(err)
+			}
+			if applied.Annotations[TemplateHash] != hash {
+				t.Errorf("annotation = %q, want %q", applied.Annotations[TemplateHash], hash)
+			}
+			label, labeled := applied.Spec.Template.Labels[TemplateHash]
+			if tt.wantLabel == "" && labeled {
+				t.Errorf("pod template label = %q, want none", label)
+			}
+			if tt.wantLabel != "" && label != tt.wantLabel {
+				t.Errorf("pod template label = %q, want %q", label, tt.wantLabel)
+			}
+			if image := applied.Spec.Template.Spec.Containers[0].Image; image != tt.wantImage {
+				t.Errorf("image = %q, want %q", image, tt.wantImage)
+			}
+			decisions, _ := ctx.Get(contextRolloutDecisions).([]string)
+			if len(decisions) != 1 {
+				t.Errorf("rollout decisions = %v, want one", decisions)
+			}
+		})
+	}
+}
+
+func TestApplyDeploymentTwice(t *testing.T) {
+	ctx, _ := newTestApplyContext(newTestDeployment("redis:7"))
+
+	recreate, err := ApplyDeployment(ctx, newTestDeployment("redis:7"))
+	if err != nil || recreate {
+		t.Fatalf("first ApplyDeployment() = %v, %v, want false, nil", recreate, err)
+	}
+	recreate, err = ApplyDeployment(ctx, newTestDeployment("redis:7"))
+	if err != nil || recreate {
+		t.Fatalf("second ApplyDeployment() = %v, %v, want false, nil", recreate, err)
+	}
+	recreate, err = ApplyDeployment(ctx, newTestDeployment("redis:8"))
+	if err != nil || !recreate {
+		t.Fatalf("ApplyDeployment() with a new image = %v, %v, want true, nil", recreate, err)
+	}
+}
@@ -14,4 +14,5 @@ const (
 	AppTechnology        = "app.kubernetes.io/technology"
 	AppPartOf            = "app.kubernetes.io/part-of"
 	DeploymentSessionId  = "deployment.netcracker.com/sessionId"
+	TemplateHash         = "netcracker.com/template-hash"
 )
@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	v12 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
@@ -55,6 +58,40 @@ func (r *SimpleCtxExecutable) Execute(ctx core.ExecutionContext) error {
 	return r.ExecuteFunc(ctx, cr, log)
 }
 
+// ExecuteComponent runs the component steps and records the result as the component condition in the CR status.
+// Steps report failures by panicking, so the panic is recorded and passed on to the reconciler.
+func ExecuteComponent(ctx core.ExecutionContext, component string, execute func(ctx core.ExecutionContext) error) (err error) {
+	cr := ctx.Get(constants.ContextSpec).(*v12.DbaasRedisAdapter)
+	ctx.Set(contextRolloutDecisions, []string{})
+	setComponentCondition(cr, component, "In Progress", "")
+
+	defer func() {
+		if r := recover(); r != nil {
+			setComponentCondition(cr, component, "Failed", fmt.Sprintf("%v", r))
+			panic(r)
+		}
+		if err != nil {
+			setComponentCondition(cr, component, "Failed", err.Error())
+		} else {
+			// Successful condition tells which deployments got new pods
+			decisions, _ := ctx.Get(contextRolloutDecisions).([]string)
+			setComponentCondition(cr, component, "Successful", strings.Join(decisions, "; "))
+		}
+	}()
+
+	return execute(ctx)
+}
+
+func setComponentCondition(cr *v12.DbaasRedisAdapter, component string, statusType string, message string) {
+	cr.Status.SetComponentCondition(component, types.ServiceStatusCondition{
+		Type:               statusType,
+		Status:             statusType != "Failed",
+		Reason:             component + strings.ReplaceAll(statusType, " ", ""),
+		Message:            strings.ReplaceAll(message, "\t", " "),
+		LastTransitionTime: metav1.Time{Time: time.Now()},
+	})
+}
+
 func GetHTTPPort(tlsEnabled bool) int32 {
 	var port int32 = 8080
 	if tlsEnabled {
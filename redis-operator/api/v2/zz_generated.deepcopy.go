@@ -26,6 +26,27 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabasesStatus) DeepCopyInto(out *DatabasesStatus) {
+	*out = *in
+	if in.NotReady != nil {
+		in, out := &in.NotReady, &out.NotReady
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabasesStatus.
+func (in *DatabasesStatus) DeepCopy() *DatabasesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabasesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Dbaas) DeepCopyInto(out *Dbaas) {
 	*out = *in
@@ -62,6 +83,38 @@ func (in *DbaasAdapter) DeepCopyInto(out *DbaasAdapter) {
 			(*out)[key] = val
 		}
 	}
+	if in.PasswordPolicy != nil {
+		in, out := &in.PasswordPolicy, &out.PasswordPolicy
+		*out = new(PasswordPolicy)
+		**out = **in
+	}
+	if in.OrphanCollector != nil {
+		in, out := &in.OrphanCollector, &out.OrphanCollector
+		*out = new(OrphanCollector)
+		**out = **in
+	}
+	if in.SoftDelete != nil {
+		in, out := &in.SoftDelete, &out.SoftDelete
+		*out = new(SoftDelete)
+		**out = **in
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]SizeProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(Quotas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RedisVersions != nil {
+		in, out := &in.RedisVersions, &out.RedisVersions
+		*out = make([]RedisVersion, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DbaasAdapter.
@@ -196,6 +249,33 @@ func (in *DbaasRedisAdapterStatus) DeepCopyInto(out *DbaasRedisAdapterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[string]types.ServiceStatusCondition, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = new(DatabasesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Dbaas != nil {
+		in, out := &in.Dbaas, &out.Dbaas
+		*out = new(DbaasStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Orphans != nil {
+		in, out := &in.Orphans, &out.Orphans
+		*out = new(OrphansStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(QuotasStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DbaasRedisAdapterStatus.
@@ -208,6 +288,22 @@ func (in *DbaasRedisAdapterStatus) DeepCopy() *DbaasRedisAdapterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DbaasStatus) DeepCopyInto(out *DbaasStatus) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DbaasStatus.
+func (in *DbaasStatus) DeepCopy() *DbaasStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DbaasStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfluxSettings) DeepCopyInto(out *InfluxSettings) {
 	*out = *in
@@ -255,6 +351,60 @@ func (in *Monitoring) DeepCopy() *Monitoring {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanCollector) DeepCopyInto(out *OrphanCollector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanCollector.
+func (in *OrphanCollector) DeepCopy() *OrphanCollector {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanCollector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanResource) DeepCopyInto(out *OrphanResource) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanResource.
+func (in *OrphanResource) DeepCopy() *OrphanResource {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphansStatus) DeepCopyInto(out *OrphansStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]OrphanResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphansStatus.
+func (in *OrphansStatus) DeepCopy() *OrphansStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphansStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Parameters) DeepCopyInto(out *Parameters) {
 	*out = *in
@@ -270,6 +420,21 @@ func (in *Parameters) DeepCopy() *Parameters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordPolicy) DeepCopyInto(out *PasswordPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordPolicy.
+func (in *PasswordPolicy) DeepCopy() *PasswordPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Policies) DeepCopyInto(out *Policies) {
 	*out = *in
@@ -292,6 +457,130 @@ func (in *Policies) DeepCopy() *Policies {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Quota) DeepCopyInto(out *Quota) {
+	*out = *in
+	if in.MaxDatabases != nil {
+		in, out := &in.MaxDatabases, &out.MaxDatabases
+		*out = new(int)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Quota.
+func (in *Quota) DeepCopy() *Quota {
+	if in == nil {
+		return nil
+	}
+	out := new(Quota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaOverride) DeepCopyInto(out *QuotaOverride) {
+	*out = *in
+	in.Quota.DeepCopyInto(&out.Quota)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaOverride.
+func (in *QuotaOverride) DeepCopy() *QuotaOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaUsage) DeepCopyInto(out *QuotaUsage) {
+	*out = *in
+	out.Memory = in.Memory.DeepCopy()
+	out.CPU = in.CPU.DeepCopy()
+	in.Quota.DeepCopyInto(&out.Quota)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaUsage.
+func (in *QuotaUsage) DeepCopy() *QuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Quotas) DeepCopyInto(out *Quotas) {
+	*out = *in
+	if in.Adapter != nil {
+		in, out := &in.Adapter, &out.Adapter
+		*out = new(Quota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(Quota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Microservice != nil {
+		in, out := &in.Microservice, &out.Microservice
+		*out = new(Quota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]QuotaOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Quotas.
+func (in *Quotas) DeepCopy() *Quotas {
+	if in == nil {
+		return nil
+	}
+	out := new(Quotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotasStatus) DeepCopyInto(out *QuotasStatus) {
+	*out = *in
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]QuotaUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotasStatus.
+func (in *QuotasStatus) DeepCopy() *QuotasStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotasStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Redis) DeepCopyInto(out *Redis) {
 	*out = *in
@@ -326,6 +615,21 @@ func (in *Redis) DeepCopy() *Redis {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisVersion) DeepCopyInto(out *RedisVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisVersion.
+func (in *RedisVersion) DeepCopy() *RedisVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RobotTests) DeepCopyInto(out *RobotTests) {
 	*out = *in
@@ -353,6 +657,62 @@ func (in *RobotTests) DeepCopy() *RobotTests {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SizeProfile) DeepCopyInto(out *SizeProfile) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SizeProfile.
+func (in *SizeProfile) DeepCopy() *SizeProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(SizeProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoftDelete) DeepCopyInto(out *SoftDelete) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoftDelete.
+func (in *SoftDelete) DeepCopy() *SoftDelete {
+	if in == nil {
+		return nil
+	}
+	out := new(SoftDelete)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
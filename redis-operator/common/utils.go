@@ -6,8 +6,8 @@ import (
 	"time"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
-	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
 	cm "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -29,12 +29,14 @@ var RedisContainerEntryPoint = []string{"/run_entry.sh"}
 
 var TLSSecretNamePattern = "%s-tls"
 
-func UpdateCertificate(tlsEnabled bool, clusterIssuerName, logicalDatabaseName, namespace string, kubeClient client.Client, runtimeScheme *runtime.Scheme) error {
+func UpdateCertificate(tlsEnabled bool, clusterIssuerName, logicalDatabaseName, namespace string, kubeClient client.Client, runtimeScheme *runtime.Scheme,
+	labels map[string]string) error {
 	if !tlsEnabled {
 		return nil
 	}
 
 	certificateTemplate := GetCertificateTemplate(logicalDatabaseName, namespace, clusterIssuerName)
+	certificateTemplate.SetLabels(labels)
 
 	err := cm.AddToScheme(runtimeScheme)
 
@@ -101,8 +103,10 @@ func GetCertificateTemplate(dbName, namespace, clusterIssuerName string) client.
 	}
 }
 
-func GetRedisEnvs(tls types.TLS) []corev1.EnvVar {
-	return []corev1.EnvVar{
+// GetRedisEnvs returns the connection settings of the Redis deployment read by the monitoring agent,
+// REDIS_TLS_PORT is set only if TLS is enabled.
+func GetRedisEnvs(tls v2.TLS) []corev1.EnvVar {
+	envs := []corev1.EnvVar{
 		{
 			Name:  "REDIS_PORT",
 			Value: fmt.Sprint(RedisPort),
@@ -110,6 +114,10 @@ func GetRedisEnvs(tls types.TLS) []corev1.EnvVar {
 		utils.GetPlainTextEnvVar("TLS_ENABLED", strconv.FormatBool(tls.Enabled)),
 		utils.GetPlainTextEnvVar("TLS_ROOTCERT", "/usr/ssl/ca.crt"),
 	}
+	if tls.Enabled && tls.TLSPort > 0 {
+		envs = append(envs, utils.GetPlainTextEnvVar("REDIS_TLS_PORT", fmt.Sprint(tls.TLSPort)))
+	}
+	return envs
 }
 
 func MergeEnvs(from, to []corev1.EnvVar) []corev1.EnvVar {
@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -66,3 +68,29 @@ func TestMergeEnvs(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRedisEnvs(t *testing.T) {
+	tests := []struct {
+		name        string
+		tls         v2.TLS
+		wantTLSPort string
+	}{
+		{name: "TLS disabled", tls: v2.TLS{TLSPort: 6380}},
+		{name: "TLS enabled", tls: v2.TLS{TLS: types.TLS{Enabled: true}, TLSPort: 6380}, wantTLSPort: "6380"},
+		{name: "TLS enabled without the port", tls: v2.TLS{TLS: types.TLS{Enabled: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envs := map[string]string{}
+			for _, env := range GetRedisEnvs(tt.tls) {
+				envs[env.Name] = env.Value
+			}
+			if envs["REDIS_PORT"] != "6379" {
+				t.Errorf("REDIS_PORT = %q, want 6379", envs["REDIS_PORT"])
+			}
+			if tlsPort, ok := envs["REDIS_TLS_PORT"]; tlsPort != tt.wantTLSPort || ok != (tt.wantTLSPort != "") {
+				t.Errorf("REDIS_TLS_PORT = %q (set %v), want %q", tlsPort, ok, tt.wantTLSPort)
+			}
+		})
+	}
+}
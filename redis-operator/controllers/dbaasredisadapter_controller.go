@@ -19,17 +19,22 @@ package controllers
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
 	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
 	netcrackercomv2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
 	"github.com/Netcracker/qubership-redis/redis-operator/api/v2/impl"
+	service "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/services"
 )
 
 // DbaasRedisAdapterReconciler reconciles a DbaasRedisAdapter object
@@ -42,6 +47,7 @@ type DbaasRedisAdapterReconciler struct {
 //+kubebuilder:rbac:groups=netcracker.com,resources=dbaasredisadapters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=netcracker.com,resources=dbaasredisadapters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=netcracker.com,resources=dbaasredisadapters/finalizers,verbs=update
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -62,10 +68,29 @@ func (r *DbaasRedisAdapterReconciler) Reconcile(ctx context.Context, req ctrl.Re
 func (r *DbaasRedisAdapterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Reconciler = newReconciler(mgr)
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&netcrackercomv2.DbaasRedisAdapter{}).
+		// Status is updated by the adapter in background, only spec changes require reconciliation
+		For(&netcrackercomv2.DbaasRedisAdapter{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Changes of the default Redis config are propagated to the existing databases
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.adaptersInNamespace),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return object.GetName() == service.RedisDefaultConfigMapName
+			}))).
 		Complete(r)
 }
 
+func (r *DbaasRedisAdapterReconciler) adaptersInNamespace(ctx context.Context, object client.Object) []reconcile.Request {
+	adapters := &netcrackercomv2.DbaasRedisAdapterList{}
+	if err := r.List(ctx, adapters, client.InNamespace(object.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, adapter := range adapters.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&adapter)})
+	}
+	return requests
+}
+
 func newReconciler(mgr ctrl.Manager) reconcile.Reconciler {
 	return &core.ReconcileCommonService{
 		Client:           mgr.GetClient(),
@@ -82,6 +107,7 @@ var _ reconcile.Reconciler = &core.ReconcileCommonService{}
 
 type RedisReconciler struct {
 	Instance *netcrackercomv2.DbaasRedisAdapter
+	client   client.Client
 }
 
 func (s *RedisReconciler) GetConsulRegistration() *types.ConsulRegistration {
@@ -136,6 +162,7 @@ func (s *RedisReconciler) SetServiceInstance(client client.Client, request recon
 	if msCount != 1 {
 	}
 	s.Instance = &redisServiceList.Items[0]
+	s.client = client
 }
 
 func (s *RedisReconciler) GetStatus() *types.ServiceStatusCondition {
@@ -147,6 +174,17 @@ func (s *RedisReconciler) GetStatus() *types.ServiceStatusCondition {
 
 func (s *RedisReconciler) UpdateStatus(condition types.ServiceStatusCondition) {
 	s.Instance.Status.Conditions = []types.ServiceStatusCondition{condition}
+	s.Instance.Status.ObservedGeneration = s.Instance.Generation
+
+	// Databases, DBaaS registration, orphans and quotas are reported by the adapter, keep the latest ones to not overwrite them
+	latest := &netcrackercomv2.DbaasRedisAdapter{}
+	if err := s.client.Get(context.TODO(), client.ObjectKeyFromObject(s.Instance), latest); err == nil {
+		s.Instance.ResourceVersion = latest.ResourceVersion
+		s.Instance.Status.Databases = latest.Status.Databases
+		s.Instance.Status.Dbaas = latest.Status.Dbaas
+		s.Instance.Status.Orphans = latest.Status.Orphans
+		s.Instance.Status.Quotas = latest.Status.Quotas
+	}
 }
 
 func (s *RedisReconciler) GetSpec() interface{} {
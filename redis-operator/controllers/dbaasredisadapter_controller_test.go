@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/types"
+	netcrackercomv2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateStatusKeepsAdapterStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := netcrackercomv2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	meta := metav1.ObjectMeta{Name: "dbaas-redis-adapter", Namespace: "redis", Generation: 2}
+	//the adapter has reported its status after the reconcile has read the CR
+	live := &netcrackercomv2.DbaasRedisAdapter{
+		ObjectMeta: meta,
+		Status: netcrackercomv2.DbaasRedisAdapterStatus{
+			Databases: &netcrackercomv2.DatabasesStatus{Total: 2},
+			Dbaas:     &netcrackercomv2.DbaasStatus{Registration: "registered"},
+			Orphans:   &netcrackercomv2.OrphansStatus{Resources: []netcrackercomv2.OrphanResource{{Kind: "Secret", Name: "left-credentials"}}},
+			Quotas:    &netcrackercomv2.QuotasStatus{Usage: []netcrackercomv2.QuotaUsage{{Scope: "adapter", Databases: 2}}},
+		},
+	}
+	reconciler := &RedisReconciler{
+		Instance: &netcrackercomv2.DbaasRedisAdapter{ObjectMeta: meta, Status: netcrackercomv2.DbaasRedisAdapterStatus{
+			Orphans: &netcrackercomv2.OrphansStatus{},
+			Quotas:  &netcrackercomv2.QuotasStatus{},
+		}},
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(live).Build(),
+	}
+
+	condition := types.ServiceStatusCondition{Type: "Successful", Status: true}
+	reconciler.UpdateStatus(condition)
+
+	//the quantities are compared after the same round trip through the client
+	stored := &netcrackercomv2.DbaasRedisAdapter{}
+	if err := reconciler.client.Get(context.Background(), client.ObjectKeyFromObject(live), stored); err != nil {
+		t.Fatal(err)
+	}
+
+	status := reconciler.Instance.Status
+	if len(status.Conditions) != 1 || status.Conditions[0] != condition || status.ObservedGeneration != 2 {
+		t.Errorf("UpdateStatus() conditions = %v, observed generation = %d, want the condition of generation 2", status.Conditions, status.ObservedGeneration)
+	}
+	for field, values := range map[string][2]interface{}{
+		"databases": {status.Databases, stored.Status.Databases},
+		"dbaas":     {status.Dbaas, stored.Status.Dbaas},
+		"orphans":   {status.Orphans, stored.Status.Orphans},
+		"quotas":    {status.Quotas, stored.Status.Quotas},
+	} {
+		if !reflect.DeepEqual(values[0], values[1]) {
+			t.Errorf("UpdateStatus() %s = %+v, want %+v reported by the adapter", field, values[0], values[1])
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Directive is a single line of the Redis configuration file.
+type Directive struct {
+	Name string
+	Args string
+}
+
+func (d Directive) String() string {
+	return strings.TrimSpace(d.Name + " " + d.Args)
+}
+
+// RedisConfig is an ordered Redis configuration.
+// Repeatable directives such as save or rename-command keep all their values in the original order.
+type RedisConfig struct {
+	directives []Directive
+}
+
+// Parse reads the configuration from the redis-default-conf YAML, where every key is a directive.
+// Repeatable directives are set as a list of values. The legacy form with several directives
+// joined by a new line in a single value is supported as well.
+func Parse(data string) (*RedisConfig, error) {
+	config := &RedisConfig{}
+	if strings.TrimSpace(data) == "" {
+		return config, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &document); err != nil {
+		return nil, fmt.Errorf("failed to parse Redis config: %v", err)
+	}
+	if len(document.Content) == 0 {
+		return config, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse Redis config: line %d: expected a mapping of directives", root.Line)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		name := key.Value
+		if seen[name] {
+			return nil, fmt.Errorf("failed to parse Redis config: line %d: directive %s is defined twice, use a list of values instead", key.Line, name)
+		}
+		seen[name] = true
+
+		var values []string
+		switch value.Kind {
+		case yaml.ScalarNode:
+			values = []string{value.Value}
+		case yaml.SequenceNode:
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("failed to parse Redis config: line %d: values of %s must be scalars", item.Line, name)
+				}
+				values = append(values, item.Value)
+			}
+		default:
+			return nil, fmt.Errorf("failed to parse Redis config: line %d: unsupported value of %s", value.Line, name)
+		}
+
+		directives, err := toDirectives(name, values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Redis config: line %d: %v", key.Line, err)
+		}
+		config.directives = append(config.directives, directives...)
+	}
+	return config, nil
+}
+
+// ParseConf reads the rendered Redis configuration file, a directive per line as it is rendered by String.
+func ParseConf(data string) (*RedisConfig, error) {
+	config := &RedisConfig{}
+	seen := map[string]bool{}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, args, _ := strings.Cut(line, " ")
+		if seen[name] && !IsRepeatable(name) {
+			return nil, fmt.Errorf("failed to parse Redis config: line %d: directive %s can't have several values", i+1, name)
+		}
+		seen[name] = true
+		config.directives = append(config.directives, Directive{Name: name, Args: strings.TrimSpace(args)})
+	}
+	return config, nil
+}
+
+// Directives returns a copy of the configuration lines.
+func (c *RedisConfig) Directives() []Directive {
+	return append([]Directive(nil), c.directives...)
+}
+
+// Get returns all values of the directive.
+func (c *RedisConfig) Get(name string) []string {
+	var values []string
+	for _, directive := range c.directives {
+		if directive.Name == name {
+			values = append(values, directive.Args)
+		}
+	}
+	return values
+}
+
+// Set replaces all values of the directive keeping its position in the configuration.
+// The value can be a scalar or a list of scalars for repeatable directives.
+func (c *RedisConfig) Set(name string, value interface{}) error {
+	values, err := toStrings(value)
+	if err != nil {
+		return fmt.Errorf("invalid value of %s: %v", name, err)
+	}
+	directives, err := toDirectives(name, values)
+	if err != nil {
+		return err
+	}
+
+	var result []Directive
+	inserted := false
+	for _, directive := range c.directives {
+		if directive.Name != name {
+			result = append(result, directive)
+		} else if !inserted {
+			result = append(result, directives...)
+			inserted = true
+		}
+	}
+	if !inserted {
+		result = append(result, directives...)
+	}
+	c.directives = result
+	return nil
+}
+
+// Merge applies the overrides in the key order, so the result does not depend on the map iteration.
+func (c *RedisConfig) Merge(overrides map[string]interface{}) error {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := c.Set(name, overrides[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copy returns an independent copy of the configuration.
+func (c *RedisConfig) Copy() *RedisConfig {
+	return &RedisConfig{directives: c.Directives()}
+}
+
+// String renders the configuration file. The same configuration always gives the same output.
+func (c *RedisConfig) String() string {
+	lines := make([]string, 0, len(c.directives))
+	for _, directive := range c.directives {
+		lines = append(lines, directive.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Revision is a short hash of the rendered configuration.
+func (c *RedisConfig) Revision() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(c.String())))[:10]
+}
+
+// Changed returns the names of the directives which values differ between the configurations.
+func Changed(base, target *RedisConfig) []string {
+	var changed []string
+	for _, name := range names(base, target) {
+		if strings.Join(base.Get(name), "\n") != strings.Join(target.Get(name), "\n") {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// Diff describes how the target configuration differs from the base one, a line per changed directive.
+func Diff(base, target *RedisConfig) []string {
+	var diff []string
+	for _, name := range Changed(base, target) {
+		before, after := base.Get(name), target.Get(name)
+		switch {
+		case len(before) == 0:
+			diff = append(diff, fmt.Sprintf("+ %s %s", name, strings.Join(after, " | ")))
+		case len(after) == 0:
+			diff = append(diff, fmt.Sprintf("- %s %s", name, strings.Join(before, " | ")))
+		default:
+			diff = append(diff, fmt.Sprintf("~ %s %s -> %s", name, strings.Join(before, " | "), strings.Join(after, " | ")))
+		}
+	}
+	return diff
+}
+
+// names returns the directive names of both configurations in the order of their first appearance.
+func names(configs ...*RedisConfig) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, config := range configs {
+		for _, directive := range config.directives {
+			if !seen[directive.Name] {
+				seen[directive.Name] = true
+				result = append(result, directive.Name)
+			}
+		}
+	}
+	return result
+}
+
+func toDirectives(name string, values []string) ([]Directive, error) {
+	var directives []Directive
+	for _, value := range values {
+		// legacy form: "normal 0 0 0\nclient-output-buffer-limit replica 256mb 64mb 60"
+		for i, line := range strings.Split(value, "\n") {
+			if i > 0 {
+				args, found := strings.CutPrefix(strings.TrimSpace(line), name+" ")
+				if !found {
+					return nil, fmt.Errorf("value of %s contains a line of another directive: %s", name, line)
+				}
+				line = args
+			}
+			directives = append(directives, Directive{Name: name, Args: strings.TrimSpace(line)})
+		}
+	}
+	if len(directives) > 1 && !IsRepeatable(name) {
+		return nil, fmt.Errorf("directive %s can't have several values", name)
+	}
+	return directives, nil
+}
+
+func toStrings(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			values, err := toStrings(item)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, values...)
+		}
+		return result, nil
+	case []string:
+		return v, nil
+	case string:
+		return []string{v}, nil
+	case bool:
+		if v {
+			return []string{"yes"}, nil
+		}
+		return []string{"no"}, nil
+	case float64:
+		if v == math.Trunc(v) {
+			return []string{fmt.Sprintf("%d", int64(v))}, nil
+		}
+		return []string{fmt.Sprintf("%v", v)}, nil
+	case int, int32, int64:
+		return []string{fmt.Sprintf("%d", v)}, nil
+	case nil:
+		return nil, fmt.Errorf("value is empty")
+	default:
+		return nil, fmt.Errorf("unsupported type %T", value)
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Keeps document order",
+			data: "port: \"6379\"\nbind: \"0.0.0.0\"\nappendonly: \"no\"",
+			want: "port 6379\nbind 0.0.0.0\nappendonly no",
+		},
+		{
+			name: "Repeated directive as a list",
+			data: "save:\n  - \"900 1\"\n  - \"300 10\"\nport: \"6379\"",
+			want: "save 900 1\nsave 300 10\nport 6379",
+		},
+		{
+			name: "Repeated directive in legacy multiline form",
+			data: "client-output-buffer-limit: \"normal 0 0 0\\nclient-output-buffer-limit pubsub 32mb 8mb 60\"",
+			want: "client-output-buffer-limit normal 0 0 0\nclient-output-buffer-limit pubsub 32mb 8mb 60",
+		},
+		{
+			name:    "List for not repeatable directive",
+			data:    "port:\n  - \"6379\"\n  - \"6380\"",
+			wantErr: true,
+		},
+		{
+			name:    "Another directive in multiline value",
+			data:    "save: \"900 1\\nport 6379\"",
+			wantErr: true,
+		},
+		{
+			name:    "Duplicated key",
+			data:    "port: \"6379\"\nport: \"6380\"",
+			wantErr: true,
+		},
+		{
+			name:    "Not a mapping",
+			data:    "- port",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("Parse() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConf(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Rendered config",
+			data: "port 6379\nsave 900 1\nsave 300 10\nsave \"\"\nappendonly no",
+			want: "port 6379\nsave 900 1\nsave 300 10\nsave \"\"\nappendonly no",
+		},
+		{
+			name: "Comments and empty lines",
+			data: "# Redis config\n\n  port   6379  \nbind 0.0.0.0 ::1\n",
+			want: "port 6379\nbind 0.0.0.0 ::1",
+		},
+		{
+			name:    "Repeated not repeatable directive",
+			data:    "port 6379\nport 6380",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConf(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseConf() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+
+	rendered, err := Parse("save:\n  - \"900 1\"\n  - \"300 10\"\nmaxmemory-policy: \"allkeys-lru\"\nappendonly: \"yes\"")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseConf(rendered.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed := Changed(rendered, parsed); len(changed) != 0 || parsed.Revision() != rendered.Revision() {
+		t.Errorf("ParseConf() of the rendered config changed %v", changed)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		overrides map[string]interface{}
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "Replaces in place and appends new keys sorted",
+			data:      "port: \"6379\"\nmaxmemory: 200mb\nappendonly: \"no\"",
+			overrides: map[string]interface{}{"maxmemory": "1gb", "timeout": float64(30), "appendfsync": "everysec"},
+			want:      "port 6379\nmaxmemory 1gb\nappendonly no\nappendfsync everysec\ntimeout 30",
+		},
+		{
+			name:      "Replaces all values of repeated directive",
+			data:      "save:\n  - \"900 1\"\n  - \"300 10\"\nport: \"6379\"",
+			overrides: map[string]interface{}{"save": []interface{}{"60 1000"}},
+			want:      "save 60 1000\nport 6379",
+		},
+		{
+			name:      "Converts booleans",
+			data:      "appendonly: \"no\"",
+			overrides: map[string]interface{}{"appendonly": true},
+			want:      "appendonly yes",
+		},
+		{
+			name:      "List for not repeatable directive",
+			data:      "port: \"6379\"",
+			overrides: map[string]interface{}{"maxmemory": []interface{}{"1gb", "2gb"}},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := Parse(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = config.Merge(tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && config.String() != tt.want {
+				t.Errorf("Merge() = %q, want %q", config.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "Valid values", data: "port: \"6379\"\nmaxmemory: 200MB\nmaxmemory-policy: allkeys-lru\nappendonly: \"yes\"\nunknown-directive: anything"},
+		{name: "Invalid integer", data: "port: abc", wantErr: true},
+		{name: "Invalid boolean", data: "appendonly: \"true\"", wantErr: true},
+		{name: "Invalid memory", data: "maxmemory: 200 mb", wantErr: true},
+		{name: "Invalid enum", data: "maxmemory-policy: lru", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := Parse(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base, _ := Parse("port: \"6379\"\nmaxmemory: 200mb\nsave:\n  - \"900 1\"")
+	target := base.Copy()
+	_ = target.Merge(map[string]interface{}{"maxmemory": "1gb", "timeout": "30"})
+	_ = target.Set("save", []string{"900 1", "300 10"})
+
+	want := []string{
+		"~ maxmemory 200mb -> 1gb",
+		"~ save 900 1 -> 900 1 | 300 10",
+		"+ timeout 30",
+	}
+	if got := Diff(base, target); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "1024", want: 1024},
+		{value: "100b", want: 100},
+		{value: "1k", want: 1000},
+		{value: "1KB", want: 1024},
+		{value: "200mb", want: 200 * 1024 * 1024},
+		{value: "2g", want: 2000 * 1000 * 1000},
+		{value: "1gb", want: 1024 * 1024 * 1024},
+		{value: "200 mb", wantErr: true},
+		{value: "1Gi", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseMemory(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMemory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMemory() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
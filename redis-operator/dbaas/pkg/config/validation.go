@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindBool
+	kindInt
+	kindMemory
+	kindEnum
+)
+
+type directiveSpec struct {
+	kind       valueKind
+	values     []string
+	repeatable bool
+	restart    bool
+}
+
+var memoryRegexp = regexp.MustCompile(`^(?i)\d+(b|k|kb|m|mb|g|gb)?$`)
+
+// knownDirectives describes value types of the directives which are checked before rendering.
+// Directives missing here are passed to Redis as is.
+var knownDirectives = map[string]directiveSpec{
+	"port":                        {kind: kindInt, restart: true},
+	"tcp-backlog":                 {kind: kindInt, restart: true},
+	"timeout":                     {kind: kindInt},
+	"tcp-keepalive":               {kind: kindInt},
+	"databases":                   {kind: kindInt, restart: true},
+	"maxclients":                  {kind: kindInt},
+	"hz":                          {kind: kindInt},
+	"lua-time-limit":              {kind: kindInt},
+	"slowlog-log-slower-than":     {kind: kindInt},
+	"slowlog-max-len":             {kind: kindInt},
+	"latency-monitor-threshold":   {kind: kindInt},
+	"maxmemory-samples":           {kind: kindInt},
+	"auto-aof-rewrite-percentage": {kind: kindInt},
+	"hash-max-listpack-entries":   {kind: kindInt},
+	"hash-max-listpack-value":     {kind: kindInt},
+	"list-max-listpack-size":      {kind: kindInt},
+	"list-compress-depth":         {kind: kindInt},
+	"set-max-intset-entries":      {kind: kindInt},
+	"zset-max-listpack-entries":   {kind: kindInt},
+	"zset-max-listpack-value":     {kind: kindInt},
+	"hll-sparse-max-bytes":        {kind: kindInt},
+
+	"protected-mode":                {kind: kindBool},
+	"daemonize":                     {kind: kindBool, restart: true},
+	"stop-writes-on-bgsave-error":   {kind: kindBool},
+	"rdbcompression":                {kind: kindBool},
+	"rdbchecksum":                   {kind: kindBool},
+	"appendonly":                    {kind: kindBool},
+	"no-appendfsync-on-rewrite":     {kind: kindBool},
+	"aof-load-truncated":            {kind: kindBool},
+	"rdb-save-incremental-fsync":    {kind: kindBool},
+	"aof-rewrite-incremental-fsync": {kind: kindBool},
+	"cluster-enabled":               {kind: kindBool, restart: true},
+	"activerehashing":               {kind: kindBool},
+	"lazyfree-lazy-eviction":        {kind: kindBool},
+	"lazyfree-lazy-expire":          {kind: kindBool},
+
+	"maxmemory":                 {kind: kindMemory},
+	"auto-aof-rewrite-min-size": {kind: kindMemory},
+
+	"loglevel":         {kind: kindEnum, values: []string{"debug", "verbose", "notice", "warning", "nothing"}},
+	"supervised":       {kind: kindEnum, values: []string{"upstart", "systemd", "auto", "no"}, restart: true},
+	"appendfsync":      {kind: kindEnum, values: []string{"always", "everysec", "no"}},
+	"tls-auth-clients": {kind: kindEnum, values: []string{"yes", "no", "optional"}},
+	"maxmemory-policy": {kind: kindEnum, values: []string{"volatile-lru", "allkeys-lru", "volatile-lfu", "allkeys-lfu",
+		"volatile-random", "allkeys-random", "volatile-ttl", "noeviction"}},
+
+	"save":                       {kind: kindString, repeatable: true},
+	"rename-command":             {kind: kindString, repeatable: true, restart: true},
+	"loadmodule":                 {kind: kindString, repeatable: true, restart: true},
+	"include":                    {kind: kindString, repeatable: true, restart: true},
+	"client-output-buffer-limit": {kind: kindString, repeatable: true},
+	"user":                       {kind: kindString, repeatable: true, restart: true},
+
+	"logfile":       {kind: kindString, restart: true},
+	"pidfile":       {kind: kindString, restart: true},
+	"unixsocket":    {kind: kindString, restart: true},
+	"io-threads":    {kind: kindInt, restart: true},
+	"tls-port":      {kind: kindInt, restart: true},
+	"tls-cert-file": {kind: kindString, restart: true},
+	"tls-key-file":  {kind: kindString, restart: true},
+}
+
+// IsRepeatable reports whether the directive can be set several times.
+func IsRepeatable(name string) bool {
+	return knownDirectives[name].repeatable
+}
+
+// RequiresRestart reports whether a new value of the directive is applied only on Redis start.
+// Other directives are applied at runtime with CONFIG SET.
+func RequiresRestart(name string) bool {
+	return knownDirectives[name].restart
+}
+
+// Validate checks the values of the known directives.
+func (c *RedisConfig) Validate() error {
+	var errs []error
+	for _, directive := range c.directives {
+		if err := validateDirective(directive); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateDirective(directive Directive) error {
+	spec, ok := knownDirectives[directive.Name]
+	if !ok {
+		return nil
+	}
+	value := directive.Args
+	switch spec.kind {
+	case kindInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("%s must be an integer, got '%s'", directive.Name, value)
+		}
+	case kindBool:
+		if value != "yes" && value != "no" {
+			return fmt.Errorf("%s must be yes or no, got '%s'", directive.Name, value)
+		}
+	case kindMemory:
+		if !memoryRegexp.MatchString(value) {
+			return fmt.Errorf("%s must be a memory size like 100mb, got '%s'", directive.Name, value)
+		}
+	case kindEnum:
+		for _, allowed := range spec.values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s, got '%s'", directive.Name, strings.Join(spec.values, ", "), value)
+	}
+	return nil
+}
+
+// memoryUnits are the multipliers of the memory units of the Redis config, k, m and g are decimal.
+var memoryUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"k":  1000,
+	"kb": 1024,
+	"m":  1000 * 1000,
+	"mb": 1024 * 1024,
+	"g":  1000 * 1000 * 1000,
+	"gb": 1024 * 1024 * 1024,
+}
+
+// ParseMemory returns the number of bytes of the memory size written the same way as in the Redis config, e.g. 100mb.
+func ParseMemory(value string) (int64, error) {
+	if !memoryRegexp.MatchString(value) {
+		return 0, fmt.Errorf("memory size must be like 100mb, got '%s'", value)
+	}
+	lower := strings.ToLower(value)
+	digits := strings.TrimRight(lower, "bkmg")
+	size, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return size * memoryUnits[lower[len(digits):]], nil
+}
+
+// FormatMemory writes the number of bytes in whole megabytes when it is at least one megabyte, the rest is rounded down.
+func FormatMemory(bytes int64) string {
+	if bytes < memoryUnits["mb"] {
+		return strconv.FormatInt(bytes, 10)
+	}
+	return fmt.Sprintf("%dmb", bytes/memoryUnits["mb"])
+}
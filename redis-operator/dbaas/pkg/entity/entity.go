@@ -11,6 +11,10 @@ type DbCreateRequestSettings struct {
 	RedisDbResources              v1.ResourceRequirements `json:"redisDbResources,omitempty" mapstructure:"redisDbResources"`
 	RedisDbNodeSelector           map[string]string       `json:"redisDbNodeSelector,omitempty" mapstructure:"redisDbNodeSelector"`
 	RedisDbWaitStartServiceSecond int                     `json:"redisDbWaitStartServiceSecond,omitempty" mapstructure:"redisDbWaitStartServiceSecond"`
+	RedisDbTolerations            []v1.Toleration         `json:"redisDbTolerations,omitempty" mapstructure:"redisDbTolerations"`
+	Profile                       string                  `json:"profile,omitempty" mapstructure:"profile"`
+	RedisVersion                  string                  `json:"redisVersion,omitempty" mapstructure:"redisVersion"`
+	RedisImage                    string                  `json:"redisImage,omitempty" mapstructure:"redisImage"`
 }
 
 type ConnectionProperties struct {
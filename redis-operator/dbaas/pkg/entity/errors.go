@@ -23,3 +23,69 @@ func NewResourceAlreadyExistsError(message string) error {
 func (e *ResourceAlreadyExistsError) Error() string {
 	return e.message
 }
+
+type TooManyRequestsError struct {
+	message string
+}
+
+func NewTooManyRequestsError(message string) error {
+	return &TooManyRequestsError{message}
+}
+func (e *TooManyRequestsError) Error() string {
+	return e.message
+}
+
+type NotFoundError struct {
+	message string
+}
+
+func NewNotFoundError(message string) error {
+	return &NotFoundError{message}
+}
+func (e *NotFoundError) Error() string {
+	return e.message
+}
+
+type PreconditionFailedError struct {
+	message string
+}
+
+func NewPreconditionFailedError(message string) error {
+	return &PreconditionFailedError{message}
+}
+func (e *PreconditionFailedError) Error() string {
+	return e.message
+}
+
+type BackendUnavailableError struct {
+	message string
+}
+
+func NewBackendUnavailableError(message string) error {
+	return &BackendUnavailableError{message}
+}
+func (e *BackendUnavailableError) Error() string {
+	return e.message
+}
+
+type TimeoutError struct {
+	message string
+}
+
+func NewTimeoutError(message string) error {
+	return &TimeoutError{message}
+}
+func (e *TimeoutError) Error() string {
+	return e.message
+}
+
+type QuotaExceededError struct {
+	message string
+}
+
+func NewQuotaExceededError(message string) error {
+	return &QuotaExceededError{message}
+}
+func (e *QuotaExceededError) Error() string {
+	return e.message
+}
@@ -1,14 +1,31 @@
 package helper
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	invalidDbNameChars     = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+const (
+	dbNameSuffixChars  = "abcdefghijklmnopqrstuvwxyz0123456789"
+	dbNameSuffixLength = 5
+	dbNameHashLength   = 8
 )
 
 func GetEnvBool(key string, fallback bool) bool {
@@ -23,8 +40,51 @@ func GetEnvBool(key string, fallback bool) bool {
 	return fallback
 }
 
+// GenerateDbName returns the millisecond timestamp with a random suffix, so the names generated at the same time differ
 func GenerateDbName() string {
 	currentTime := time.Now().UTC()
 	timestamp := currentTime.Format("150405.000.020106")
-	return strings.ReplaceAll(timestamp, ".", "")
+	return strings.ReplaceAll(timestamp, ".", "") + randomSuffix(dbNameSuffixLength)
+}
+
+// ClassifierDbName returns <microserviceName>-<namespace>-<GenerateDbName>. If the name is longer than maxLen,
+// the classifier part is truncated and the hash of the full classifier is added, so the truncation is deterministic
+// and two long classifiers with the same beginning get different names.
+func ClassifierDbName(namespace string, microserviceName string, maxLen int) string {
+	unique := GenerateDbName()
+	base := strings.Trim(invalidDbNameChars.ReplaceAllString(strings.ToLower(microserviceName+"-"+namespace), "-"), "-")
+	if len(base)+len(unique)+1 > maxLen {
+		hash := sha256.Sum256([]byte(microserviceName + "/" + namespace))
+		suffix := hex.EncodeToString(hash[:])[:dbNameHashLength]
+		keep := maxLen - len(unique) - len(suffix) - 2
+		if keep < 0 {
+			keep = 0
+		}
+		if keep < len(base) {
+			base = base[:keep]
+		}
+		base = strings.Trim(base, "-") + "-" + suffix
+	}
+	return strings.TrimPrefix(base+"-"+unique, "-")
+}
+
+func randomSuffix(length int) string {
+	result := make([]byte, length)
+	for i := range result {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(dbNameSuffixChars))))
+		if err != nil {
+			panic(err)
+		}
+		result[i] = dbNameSuffixChars[n.Int64()]
+	}
+	return string(result)
+}
+
+// LabelValue converts the value to a valid label value, e.g. the microservice name from a classifier
+func LabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(value, "-_.")
 }
@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var dbNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// generatedLength is the length of the timestamp and the random suffix of GenerateDbName
+const generatedLength = 15 + dbNameSuffixLength
+
+func TestGenerateDbName(t *testing.T) {
+	names := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		name := GenerateDbName()
+		if len(name) != generatedLength || !dbNameRegexp.MatchString(name) {
+			t.Fatalf("GenerateDbName() = %s, want %d lowercase letters and digits", name, generatedLength)
+		}
+		if names[name] {
+			t.Fatalf("GenerateDbName() = %s is generated twice", name)
+		}
+		names[name] = true
+	}
+}
+
+func TestClassifierDbName(t *testing.T) {
+	long := strings.Repeat("long-microservice-name-", 4)
+	tests := []struct {
+		name             string
+		namespace        string
+		microserviceName string
+		maxLen           int
+		wantPrefix       string
+	}{
+		{name: "Short classifier", namespace: "app", microserviceName: "service", maxLen: 55, wantPrefix: "service-app-"},
+		{name: "Invalid characters", namespace: "App_NS", microserviceName: "my.Service", maxLen: 55, wantPrefix: "my-service-app-ns-"},
+		{name: "Long microservice name", namespace: "app", microserviceName: long, maxLen: 55, wantPrefix: "long-microservice-name-lo-"},
+		{name: "Long namespace", namespace: long, microserviceName: "service", maxLen: 55, wantPrefix: "service-long-microservice-"},
+		{name: "Room only for the hash", namespace: "app", microserviceName: "service", maxLen: generatedLength + dbNameHashLength + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := ClassifierDbName(tt.namespace, tt.microserviceName, tt.maxLen)
+			if len(name) > tt.maxLen || !dbNameRegexp.MatchString(name) {
+				t.Fatalf("ClassifierDbName() = %s, want a valid name not longer than %d", name, tt.maxLen)
+			}
+			if !strings.HasPrefix(name, tt.wantPrefix) {
+				t.Errorf("ClassifierDbName() = %s, want prefix %s", name, tt.wantPrefix)
+			}
+			//only the generated part differs between the names of the same classifier
+			again := ClassifierDbName(tt.namespace, tt.microserviceName, tt.maxLen)
+			if name == again || name[:len(name)-generatedLength] != again[:len(again)-generatedLength] {
+				t.Errorf("ClassifierDbName() = %s and %s, want the same classifier part and different generated parts", name, again)
+			}
+		})
+	}
+}
+
+func TestClassifierDbNameLongClassifiersWithSamePrefix(t *testing.T) {
+	long := strings.Repeat("microservice-", 5)
+	first := ClassifierDbName("app", long+"first", 55)
+	second := ClassifierDbName("app", long+"second", 55)
+	firstClassifier := first[:len(first)-generatedLength]
+	secondClassifier := second[:len(second)-generatedLength]
+	if firstClassifier == secondClassifier {
+		t.Errorf("ClassifierDbName() = %s and %s, want different hashes of the classifiers", first, second)
+	}
+	if strings.TrimSuffix(firstClassifier, firstClassifier[len(firstClassifier)-dbNameHashLength-1:]) !=
+		strings.TrimSuffix(secondClassifier, secondClassifier[len(secondClassifier)-dbNameHashLength-1:]) {
+		t.Errorf("ClassifierDbName() = %s and %s, want the same truncated classifier", first, second)
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "service", want: "service"},
+		{value: "my/service name", want: "my-service-name"},
+		{value: "-service.", want: "service"},
+		{value: strings.Repeat("a", 70), want: strings.Repeat("a", 63)},
+	}
+	for _, tt := range tests {
+		if got := LabelValue(tt.value); got != tt.want {
+			t.Errorf("LabelValue(%s) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"time"
 )
 
+// Nil is returned by Get when the key does not exist
+const Nil = redis.Nil
+
 type RedisClient struct {
 	client *redis.Client
 }
@@ -18,6 +21,14 @@ type RedisClientInterface interface {
 	Addr() string
 	Get(key string) (string, error)
 	Set(key string, value string, expiration time.Duration) error
+	ConfigSet(parameter string, value string) error
+	Info(sections ...string) (string, error)
+	SlowLogGet(count int64) ([]SlowLogEntry, error)
+	LatencyLatest() ([]LatencyEvent, error)
+	LatencyHistogram() (map[string]LatencyHistogram, error)
+	Scan(cursor uint64, match string, count int64) ([]string, uint64, error)
+	KeyStats(keys []string, memorySamples int) ([]KeyStat, error)
+	Save() error
 	Close() error
 }
 
@@ -59,6 +70,19 @@ func (r RedisClient) Set(key string, value string, expiration time.Duration) err
 	return r.client.Set(key, value, expiration).Err()
 }
 
+func (r RedisClient) ConfigSet(parameter string, value string) error {
+	return r.client.ConfigSet(parameter, value).Err()
+}
+
+func (r RedisClient) Info(sections ...string) (string, error) {
+	return r.client.Info(sections...).Result()
+}
+
+// Save writes the RDB snapshot synchronously.
+func (r RedisClient) Save() error {
+	return r.client.Save().Err()
+}
+
 func (r RedisClient) Close() error {
 	return r.client.Close()
 }
@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis/redact"
+)
+
+// SlowLogEntry is a SLOWLOG GET entry, keys and values in the arguments are redacted.
+type SlowLogEntry struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	DurationUs int64     `json:"durationUs"`
+	Command    string    `json:"command"`
+	Args       []string  `json:"args"`
+	Client     string    `json:"client,omitempty"`
+	ClientName string    `json:"clientName,omitempty"`
+}
+
+// LatencyEvent is a LATENCY LATEST entry.
+type LatencyEvent struct {
+	Event    string    `json:"event"`
+	Time     time.Time `json:"time"`
+	LatestMs int64     `json:"latestMs"`
+	MaxMs    int64     `json:"maxMs"`
+}
+
+// LatencyHistogram is a LATENCY HISTOGRAM entry of a command, buckets are cumulative.
+type LatencyHistogram struct {
+	Calls   int64           `json:"calls"`
+	Buckets []LatencyBucket `json:"buckets"`
+}
+
+type LatencyBucket struct {
+	LessOrEqualUs int64 `json:"leUs"`
+	Count         int64 `json:"count"`
+}
+
+func (r RedisClient) SlowLogGet(count int64) ([]SlowLogEntry, error) {
+	items, err := r.doArray("SLOWLOG", "GET", count)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]SlowLogEntry, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		entry := SlowLogEntry{}
+		entry.ID, _ = fields[0].(int64)
+		timestamp, _ := fields[1].(int64)
+		entry.Time = time.Unix(timestamp, 0).UTC()
+		entry.DurationUs, _ = fields[2].(int64)
+		var args []string
+		if rawArgs, ok := fields[3].([]interface{}); ok {
+			for _, arg := range rawArgs {
+				args = append(args, fmt.Sprint(arg))
+			}
+		}
+		entry.Command, entry.Args = redact.Args(args)
+		if len(fields) > 5 {
+			entry.Client, _ = fields[4].(string)
+			entry.ClientName, _ = fields[5].(string)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (r RedisClient) LatencyLatest() ([]LatencyEvent, error) {
+	items, err := r.doArray("LATENCY", "LATEST")
+	if err != nil {
+		return nil, err
+	}
+	events := make([]LatencyEvent, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		event := LatencyEvent{}
+		event.Event, _ = fields[0].(string)
+		timestamp, _ := fields[1].(int64)
+		event.Time = time.Unix(timestamp, 0).UTC()
+		event.LatestMs, _ = fields[2].(int64)
+		event.MaxMs, _ = fields[3].(int64)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// LatencyHistogram returns the per command latency histograms, it requires Redis 7.0 or newer.
+func (r RedisClient) LatencyHistogram() (map[string]LatencyHistogram, error) {
+	items, err := r.doArray("LATENCY", "HISTOGRAM")
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]LatencyHistogram{}
+	for i := 0; i+1 < len(items); i += 2 {
+		command, _ := items[i].(string)
+		fields, ok := items[i+1].([]interface{})
+		if !ok {
+			continue
+		}
+		histogram := LatencyHistogram{}
+		for j := 0; j+1 < len(fields); j += 2 {
+			switch fields[j] {
+			case "calls":
+				histogram.Calls, _ = fields[j+1].(int64)
+			case "histogram_usec":
+				buckets, _ := fields[j+1].([]interface{})
+				for k := 0; k+1 < len(buckets); k += 2 {
+					bucket := LatencyBucket{}
+					bucket.LessOrEqualUs, _ = buckets[k].(int64)
+					bucket.Count, _ = buckets[k+1].(int64)
+					histogram.Buckets = append(histogram.Buckets, bucket)
+				}
+			}
+		}
+		result[command] = histogram
+	}
+	return result, nil
+}
+
+func (r RedisClient) doArray(args ...interface{}) ([]interface{}, error) {
+	result, err := r.client.Do(args...).Result()
+	if err != nil {
+		return nil, err
+	}
+	items, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected %v reply %T", args, result)
+	}
+	return items, nil
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseInfo returns INFO fields and the keyspace stats per database index, e.g. db0:keys=1,expires=0,avg_ttl=0
+func ParseInfo(info string) (map[string]string, map[string]map[string]int64) {
+	fields := map[string]string{}
+	keyspace := map[string]map[string]int64{}
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		if strings.HasPrefix(name, "db") && strings.Contains(value, "keys=") {
+			stats := map[string]int64{}
+			for _, pair := range strings.Split(value, ",") {
+				key, number, _ := strings.Cut(pair, "=")
+				if parsed, err := strconv.ParseInt(number, 10, 64); err == nil {
+					stats[key] = parsed
+				}
+			}
+			keyspace[name] = stats
+			continue
+		}
+		fields[name] = value
+	}
+	return fields, keyspace
+}
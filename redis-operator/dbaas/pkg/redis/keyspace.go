@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// KeyStat is the type, the memory usage and the TTL of a key.
+type KeyStat struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	MemoryBytes int64  `json:"memoryBytes"`
+	// TTLSeconds is -1 for a key without expiration
+	TTLSeconds int64 `json:"ttlSeconds"`
+}
+
+func (r RedisClient) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	return r.client.Scan(cursor, match, count).Result()
+}
+
+// KeyStats reads TYPE, MEMORY USAGE and PTTL of the keys in a single pipeline.
+// MEMORY USAGE of nested values is estimated by the samples of their elements.
+// Keys which are deleted after they were scanned are skipped.
+func (r RedisClient) KeyStats(keys []string, memorySamples int) ([]KeyStat, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	pipe := r.client.Pipeline()
+	defer pipe.Close()
+
+	types := make([]*redis.StatusCmd, len(keys))
+	memory := make([]*redis.IntCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		types[i] = pipe.Type(key)
+		memory[i] = pipe.MemoryUsage(key, memorySamples)
+		ttls[i] = pipe.PTTL(key)
+	}
+	//a key deleted in between fails MEMORY USAGE with nil reply, it is skipped below
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	stats := make([]KeyStat, 0, len(keys))
+	for i, key := range keys {
+		keyType := types[i].Val()
+		if keyType == "none" || keyType == "" {
+			continue
+		}
+		stat := KeyStat{Key: key, Type: keyType, MemoryBytes: memory[i].Val(), TTLSeconds: -1}
+		if ttl := ttls[i].Val(); ttl >= 0 {
+			stat.TTLSeconds = int64(ttl / time.Second)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
@@ -42,6 +42,20 @@ func (_m *RedisClientInterface) Close() error {
 	return r0
 }
 
+// ConfigSet provides a mock function with given fields: parameter, value
+func (_m *RedisClientInterface) ConfigSet(parameter string, value string) error {
+	ret := _m.Called(parameter, value)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(parameter, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: key
 func (_m *RedisClientInterface) Get(key string) (string, error) {
 	ret := _m.Called(key)
@@ -63,6 +77,33 @@ func (_m *RedisClientInterface) Get(key string) (string, error) {
 	return r0, r1
 }
 
+// Info provides a mock function with given fields: sections
+func (_m *RedisClientInterface) Info(sections ...string) (string, error) {
+	_va := make([]interface{}, len(sections))
+	for _i := range sections {
+		_va[_i] = sections[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(...string) string); ok {
+		r0 = rf(sections...)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(...string) error); ok {
+		r1 = rf(sections...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InitRedisClient provides a mock function with given fields: address, password, caPath, database, tlsEnabled
 func (_m *RedisClientInterface) InitRedisClient(address string, password string, caPath string, database int, tlsEnabled bool) redis.RedisClientInterface {
 	ret := _m.Called(address, password, caPath, database, tlsEnabled)
@@ -79,6 +120,75 @@ func (_m *RedisClientInterface) InitRedisClient(address string, password string,
 	return r0
 }
 
+// KeyStats provides a mock function with given fields: keys, memorySamples
+func (_m *RedisClientInterface) KeyStats(keys []string, memorySamples int) ([]redis.KeyStat, error) {
+	ret := _m.Called(keys, memorySamples)
+
+	var r0 []redis.KeyStat
+	if rf, ok := ret.Get(0).(func([]string, int) []redis.KeyStat); ok {
+		r0 = rf(keys, memorySamples)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]redis.KeyStat)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, int) error); ok {
+		r1 = rf(keys, memorySamples)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LatencyHistogram provides a mock function with given fields:
+func (_m *RedisClientInterface) LatencyHistogram() (map[string]redis.LatencyHistogram, error) {
+	ret := _m.Called()
+
+	var r0 map[string]redis.LatencyHistogram
+	if rf, ok := ret.Get(0).(func() map[string]redis.LatencyHistogram); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]redis.LatencyHistogram)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LatencyLatest provides a mock function with given fields:
+func (_m *RedisClientInterface) LatencyLatest() ([]redis.LatencyEvent, error) {
+	ret := _m.Called()
+
+	var r0 []redis.LatencyEvent
+	if rf, ok := ret.Get(0).(func() []redis.LatencyEvent); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]redis.LatencyEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Ping provides a mock function with given fields:
 func (_m *RedisClientInterface) Ping() (string, error) {
 	ret := _m.Called()
@@ -100,6 +210,50 @@ func (_m *RedisClientInterface) Ping() (string, error) {
 	return r0, r1
 }
 
+// Save provides a mock function with given fields:
+func (_m *RedisClientInterface) Save() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Scan provides a mock function with given fields: cursor, match, count
+func (_m *RedisClientInterface) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	ret := _m.Called(cursor, match, count)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(uint64, string, int64) []string); ok {
+		r0 = rf(cursor, match, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(uint64, string, int64) uint64); ok {
+		r1 = rf(cursor, match, count)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(uint64, string, int64) error); ok {
+		r2 = rf(cursor, match, count)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Set provides a mock function with given fields: key, value, expiration
 func (_m *RedisClientInterface) Set(key string, value string, expiration time.Duration) error {
 	ret := _m.Called(key, value, expiration)
@@ -114,6 +268,29 @@ func (_m *RedisClientInterface) Set(key string, value string, expiration time.Du
 	return r0
 }
 
+// SlowLogGet provides a mock function with given fields: count
+func (_m *RedisClientInterface) SlowLogGet(count int64) ([]redis.SlowLogEntry, error) {
+	ret := _m.Called(count)
+
+	var r0 []redis.SlowLogEntry
+	if rf, ok := ret.Get(0).(func(int64) []redis.SlowLogEntry); ok {
+		r0 = rf(count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]redis.SlowLogEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewRedisClientInterface interface {
 	mock.TestingT
 	Cleanup(func())
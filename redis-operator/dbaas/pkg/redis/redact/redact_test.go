@@ -0,0 +1,82 @@
+package redact
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantCommand string
+		wantArgs    []string
+	}{
+		{name: "No arguments"},
+		{name: "Command only", args: []string{"ping"}, wantCommand: "PING", wantArgs: []string{"PING"}},
+		{
+			name:        "Key",
+			args:        []string{"get", "user:1"},
+			wantCommand: "GET",
+			wantArgs:    []string{"GET", "?"},
+		},
+		{
+			name:        "Key and value",
+			args:        []string{"SET", "user:1", "secret", "EX", "10"},
+			wantCommand: "SET",
+			wantArgs:    []string{"SET", "?", "?", "?", "?"},
+		},
+		{
+			name:        "Subcommand",
+			args:        []string{"config", "set", "requirepass", "secret"},
+			wantCommand: "CONFIG SET",
+			wantArgs:    []string{"CONFIG", "SET", "?", "?"},
+		},
+		{
+			name:        "Subcommand is only the second argument",
+			args:        []string{"CLIENT", "KILL", "ID", "5"},
+			wantCommand: "CLIENT KILL",
+			wantArgs:    []string{"CLIENT", "KILL", "?", "?"},
+		},
+		{
+			name:        "Truncated arguments",
+			args:        []string{"MSET", "a", "1", "... (30 more arguments)"},
+			wantCommand: "MSET",
+			wantArgs:    []string{"MSET", "?", "?", "... (30 more arguments)"},
+		},
+		{
+			name:        "Value looking like the truncated arguments",
+			args:        []string{"SET", "key", "... (30 more arguments) secret"},
+			wantCommand: "SET",
+			wantArgs:    []string{"SET", "?", "?"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, args := Args(tt.args)
+			if command != tt.wantCommand || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args() = %s, %v, want %s, %v", command, args, tt.wantCommand, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// TestAgentCopy fails when the copy of the monitoring agent is not regenerated after the change of this package.
+func TestAgentCopy(t *testing.T) {
+	copied, err := os.ReadFile("../../../../../redis-monitoring-agent/source/internal/redact/redact.go")
+	if os.IsNotExist(err) {
+		t.Skip("the monitoring agent sources are not available")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := os.ReadFile("redact.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original, copied) {
+		t.Errorf("redis-monitoring-agent/source/internal/redact/redact.go differs from redact.go, run go generate in redis-monitoring-agent/source")
+	}
+}
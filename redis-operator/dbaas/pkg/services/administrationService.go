@@ -1,12 +1,10 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"strings"
 	"time"
 
@@ -21,6 +19,7 @@ import (
 	coreUtils "github.com/Netcracker/qubership-nosqldb-operator-core/pkg/utils"
 	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
 	"github.com/Netcracker/qubership-redis/redis-operator/common"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/config"
 	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
 	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/helper"
 	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis"
@@ -28,7 +27,6 @@ import (
 
 	"github.com/mitchellh/mapstructure"
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 	v12 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -62,70 +60,129 @@ type AdministrationService struct {
 	tls                               v2.TLS
 	priorityClassName                 string
 	artDescVersion, partOf, managedBy string
+	inventoryObserver                 InventoryObserver
+	analyses                          *keyspaceAnalyses
+	passwordPolicy                    v2.PasswordPolicy
+	softDelete                        softDeleteConfig
+	sizeProfiles                      sizeProfiles
+	maxmem, maxmemOverhead            string
+	quotas                            *quotas
+	redisVersions                     *redisVersions
+}
+
+// InventoryObserver is notified when logical databases are created or dropped.
+type InventoryObserver interface {
+	InventoryChanged()
 }
 
 var _ coreService.DbAdministration = &AdministrationService{}
 
 var (
-	credsSuffix        = "-credentials"
-	regexpExpression   = "^[a-z][-a-z0-9]*[a-z0-9]?$"
-	nameRegexp, _      = regexp.Compile(regexpExpression)
-	redisPasswordConst = "REDIS_PASSWORD"
-	passCharSet        = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
-		"abcdefghijklmnopqrstuvwxyz" +
-		"0123456789"
+	credsSuffix                      = "-credentials"
+	regexpExpression                 = "^[a-z][-a-z0-9]*[a-z0-9]?$"
+	nameRegexp, _                    = regexp.Compile(regexpExpression)
+	redisPasswordConst               = "REDIS_PASSWORD"
 	RedisDefaultConfigMapName string = "redis-default-conf"
 	// 55 characters because the max name length is 63 chars in k8s, but we use db name + various suffixes for creating other k8s units
 	dbNameLenghtLimit = 55
 )
 
-func NewAdministrationService(
-	redisClient redis.RedisClientInterface,
-	supportedFeatures map[string]bool,
-	apiVersion string,
-	logger *zap.Logger,
-	kubeClint client.Client,
-	runtimeScheme *runtime.Scheme,
-	namespace string,
-	redisServicePort int,
-	redisResources v1.ResourceRequirements,
-	redisImage string,
-	redisArgs []string,
-	redisLabel string,
-	defaultRedisPassword string,
-	defaultRedisDbStartWait int,
-	nodeSelector map[string]string,
-	securityContext v1.PodSecurityContext,
-	serviceAccountName string,
-	tolerations []v1.Toleration,
-	redisImagePullPolicy v1.PullPolicy,
-	redisTls v2.TLS,
-	priorityClassName string, partOf, managedBy string) *AdministrationService {
+// AdministrationServiceOptions is the configuration of the logical databases from the DbaasRedisAdapter spec.
+type AdministrationServiceOptions struct {
+	RedisClient             redis.RedisClientInterface
+	SupportedFeatures       map[string]bool
+	ApiVersion              string
+	Logger                  *zap.Logger
+	KubeClient              client.Client
+	RuntimeScheme           *runtime.Scheme
+	Namespace               string
+	RedisServicePort        int
+	RedisResources          v1.ResourceRequirements
+	RedisImage              string
+	RedisArgs               []string
+	RedisLabel              string
+	DefaultRedisPassword    string
+	DefaultRedisDbStartWait int
+	NodeSelector            map[string]string
+	SecurityContext         v1.PodSecurityContext
+	ServiceAccountName      string
+	Tolerations             []v1.Toleration
+	RedisImagePullPolicy    v1.PullPolicy
+	TLS                     v2.TLS
+	PriorityClassName       string
+	PartOf, ManagedBy       string
+	InventoryObserver       InventoryObserver
+	PasswordPolicy          *v2.PasswordPolicy
+	SoftDelete              *v2.SoftDelete
+	Profiles                []v2.SizeProfile
+	DefaultProfile          string
+	Maxmem, MaxmemOverhead  string
+	Quotas                  *v2.Quotas
+	RedisVersions           []v2.RedisVersion
+}
+
+// NewAdministrationService returns the error if the configuration of the logical databases is invalid.
+func NewAdministrationService(options AdministrationServiceOptions) (*AdministrationService, error) {
+	softDeleteConfig, err := newSoftDeleteConfig(options.SoftDelete)
+	if err != nil {
+		return nil, fmt.Errorf("invalid soft delete configuration: %w", err)
+	}
+	sizeProfiles, err := newSizeProfiles(options.Profiles, options.DefaultProfile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size profiles: %w", err)
+	}
+	quotas, err := newQuotas(options.Quotas)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quotas: %w", err)
+	}
+	redisVersions, err := newRedisVersions(options.RedisVersions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis versions: %w", err)
+	}
+	passwordPolicy, err := newPasswordPolicy(options.PasswordPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password policy: %w", err)
+	}
 
 	return &AdministrationService{
-		redisClient:             redisClient,
-		supportedFeatures:       supportedFeatures,
-		apiVersion:              apiVersion,
-		logger:                  logger,
-		kubeClient:              kubeClint,
-		runtimeScheme:           runtimeScheme,
-		namespace:               namespace,
-		redisServicePort:        redisServicePort,
-		redisResources:          redisResources,
-		redisImage:              redisImage,
-		redisArgs:               redisArgs,
-		redisLabel:              redisLabel,
-		defaultRedisPassword:    defaultRedisPassword,
-		defaultRedisDbStartWait: defaultRedisDbStartWait,
-		nodeSelector:            nodeSelector,
-		securityContext:         securityContext,
-		serviceAccountName:      serviceAccountName,
-		tolerations:             tolerations,
-		redisImagePullPolicy:    redisImagePullPolicy,
-		tls:                     redisTls,
-		priorityClassName:       priorityClassName,
-		partOf:                  partOf,
-		managedBy:               managedBy,
+		redisClient:             options.RedisClient,
+		supportedFeatures:       options.SupportedFeatures,
+		apiVersion:              options.ApiVersion,
+		logger:                  options.Logger,
+		kubeClient:              options.KubeClient,
+		runtimeScheme:           options.RuntimeScheme,
+		namespace:               options.Namespace,
+		redisServicePort:        options.RedisServicePort,
+		redisResources:          options.RedisResources,
+		redisImage:              options.RedisImage,
+		redisArgs:               options.RedisArgs,
+		redisLabel:              options.RedisLabel,
+		defaultRedisPassword:    options.DefaultRedisPassword,
+		defaultRedisDbStartWait: options.DefaultRedisDbStartWait,
+		nodeSelector:            options.NodeSelector,
+		securityContext:         options.SecurityContext,
+		serviceAccountName:      options.ServiceAccountName,
+		tolerations:             options.Tolerations,
+		redisImagePullPolicy:    options.RedisImagePullPolicy,
+		tls:                     options.TLS,
+		priorityClassName:       options.PriorityClassName,
+		partOf:                  options.PartOf,
+		managedBy:               options.ManagedBy,
+		inventoryObserver:       options.InventoryObserver,
+		analyses:                newKeyspaceAnalyses(),
+		passwordPolicy:          passwordPolicy,
+		softDelete:              softDeleteConfig,
+		sizeProfiles:            sizeProfiles,
+		maxmem:                  options.Maxmem,
+		maxmemOverhead:          options.MaxmemOverhead,
+		quotas:                  quotas,
+		redisVersions:           redisVersions,
+	}, nil
+}
+
+func (adminService *AdministrationService) notifyInventoryChanged() {
+	if adminService.inventoryObserver != nil {
+		adminService.inventoryObserver.InventoryChanged()
 	}
 }
 
@@ -223,45 +280,134 @@ func (adminService *AdministrationService) CreateRoles(ctx context.Context, role
 	return nil, nil
 }
 
+// CreateUser rotates the password of the logical database, Redis has the only user per database,
+// so the user name is only returned back. The password of the request is checked against the password policy,
+// otherwise a new one is generated.
 func (adminService *AdministrationService) CreateUser(ctx context.Context, userName string, requestOnCreateUser dao.UserCreateRequest) (*dao.CreatedUser, error) {
 	logger := utils.AddLoggerContext(adminService.logger, ctx)
-	core.PanicError(fmt.Errorf("Not Implemeted"), logger.Error, "This function must never be called for Redis")
-	return nil, nil
+	dbName := requestOnCreateUser.DbName
+	if dbName == "" {
+		return nil, customEntity.NewInvalidArgumentError("The database name must be set")
+	}
+	if role := requestOnCreateUser.Role; role != "" && role != "admin" {
+		return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("The role %s is not supported, the only role is admin", role))
+	}
+	if _, err := adminService.getRedisDeployment(ctx, dbName); err != nil {
+		return nil, err
+	}
+
+	password := requestOnCreateUser.Password
+	if password != "" {
+		if err := checkPassword(password, adminService.passwordPolicy); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if password, err = generatePassword(adminService.passwordPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := adminService.rotatePassword(ctx, dbName, password); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("The password of database %s is changed", dbName))
+	return &dao.CreatedUser{
+		ConnectionProperties: createConnectionProperties(dbName, password, adminService.namespace, adminService.redisServicePort)[0],
+		Resources:            adminService.getDBResources(dbName),
+		Name:                 userName,
+		Role:                 "admin",
+	}, nil
+}
+
+// rotatePassword stores the password in the credentials secret, so it is used on the next start of the database,
+// and sets it to the running database. The secret is restored if the database rejects the password.
+func (adminService *AdministrationService) rotatePassword(ctx context.Context, dbName, password string) error {
+	redisdb, err := adminService.connectDatabase(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	defer redisdb.Close()
+
+	secret := &v1.Secret{}
+	secretName := credsName(dbName)
+	if err = adminService.kubeClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: adminService.namespace}, secret); err != nil {
+		return typedError(err, fmt.Sprintf("failed to read credentials secret %s", secretName))
+	}
+	rotated := secret.DeepCopy()
+	if rotated.Data == nil {
+		rotated.Data = map[string][]byte{}
+	}
+	rotated.Data[constants.Password] = []byte(password)
+	if err = adminService.kubeClient.Update(ctx, rotated); err != nil {
+		return typedError(err, fmt.Sprintf("failed to update credentials secret %s", secretName))
+	}
+	if err = redisdb.ConfigSet("requirepass", password); err != nil {
+		restored := rotated.DeepCopy()
+		restored.Data[constants.Password] = secret.Data[constants.Password]
+		if restoreErr := adminService.kubeClient.Update(ctx, restored); restoreErr != nil {
+			utils.AddLoggerContext(adminService.logger, ctx).Error(fmt.Sprintf("Failed to restore credentials secret %s: %v", secretName, restoreErr))
+		}
+		return typedError(err, fmt.Sprintf("failed to change the password of database %s", dbName))
+	}
+	return nil
 }
 
 func (adminService *AdministrationService) GetDefaultUserCreateRequest() dao.UserCreateRequest {
-	core.PanicError(fmt.Errorf("Not Implemeted"), adminService.logger.Error, "This function must never be called for Redis")
-	return dao.UserCreateRequest{}
+	return dao.UserCreateRequest{Role: "admin"}
 }
 
-func (adminService *AdministrationService) setMetadata(ctx context.Context, metadata map[string]interface{}, redisdb redis.RedisClientInterface) {
-	logger := utils.AddLoggerContext(adminService.logger, ctx)
+func (adminService *AdministrationService) setMetadata(metadata map[string]interface{}, redisdb redis.RedisClientInterface) error {
 	metadataBytes, err := json.Marshal(metadata)
-	core.PanicError(err, logger.Error, "Error during marshal metadata")
+	if err != nil {
+		return customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid metadata: %v", err))
+	}
 	err = redisdb.Set("dbaas.metadata", string(metadataBytes), 0)
-	core.PanicError(err, logger.Error, fmt.Sprintf("Error during set metadata in %s", redisdb.Addr()))
+	return typedError(err, fmt.Sprintf("failed to set metadata in %s", redisdb.Addr()))
 }
 
+// GetMetadata is the operation of the DBaaS adapter core interface, the error is logged and no metadata is returned.
 func (adminService *AdministrationService) GetMetadata(ctx context.Context, serviceName string) map[string]interface{} {
-	logger := utils.AddLoggerContext(adminService.logger, ctx)
-	password := adminService.readRedisDBPassword(ctx, serviceName)
-	redisdb := adminService.createRedisClient(ctx, fmt.Sprintf("%s.%s:%d", serviceName, adminService.namespace, adminService.redisServicePort), password, 0)
+	metadata, err := adminService.getMetadata(ctx, serviceName)
+	adminService.logError(ctx, err)
+	return metadata
+}
+
+func (adminService *AdministrationService) getMetadata(ctx context.Context, serviceName string) (map[string]interface{}, error) {
+	redisdb, err := adminService.connectDatabase(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
 	defer redisdb.Close()
 	meta, err := redisdb.Get("dbaas.metadata")
-	core.PanicError(err, logger.Error, fmt.Sprintf("Failed to read metadata for DB %s", serviceName))
+	if err == redis.Nil {
+		return nil, customEntity.NewNotFoundError(fmt.Sprintf("Metadata of database %s is not found", serviceName))
+	}
+	if err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to read metadata of database %s", serviceName))
+	}
 
 	var metadata map[string]interface{}
-	err = json.Unmarshal([]byte(meta), &metadata)
-	core.PanicError(err, logger.Error, fmt.Sprintf("failed to unmarshal metadata for DB %s", serviceName))
-
-	return metadata
+	if err = json.Unmarshal([]byte(meta), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata of database %s: %v", serviceName, err)
+	}
+	return metadata, nil
 }
 
+// UpdateMetadata is the operation of the DBaaS adapter core interface, the error is logged.
+// The adapter API updates the metadata with UpdateDatabaseMetadata.
 func (adminService *AdministrationService) UpdateMetadata(ctx context.Context, newMetadata map[string]interface{}, serviceName string) {
-	password := adminService.readRedisDBPassword(ctx, serviceName)
-	redisdb := adminService.createRedisClient(ctx, fmt.Sprintf("%s.%s:%d", serviceName, adminService.namespace, adminService.redisServicePort), password, 0)
+	adminService.logError(ctx, adminService.UpdateDatabaseMetadata(ctx, newMetadata, serviceName))
+}
+
+// UpdateDatabaseMetadata replaces the metadata stored in the logical database.
+func (adminService *AdministrationService) UpdateDatabaseMetadata(ctx context.Context, newMetadata map[string]interface{}, serviceName string) error {
+	redisdb, err := adminService.connectDatabase(ctx, serviceName)
+	if err != nil {
+		return err
+	}
 	defer redisdb.Close()
-	adminService.setMetadata(ctx, newMetadata, redisdb)
+	return adminService.setMetadata(newMetadata, redisdb)
 }
 
 func (adminService *AdministrationService) GetDefaultCreateRequest() dao.DbCreateRequest {
@@ -274,6 +420,18 @@ func (adminService *AdministrationService) GetDefaultCreateRequest() dao.DbCreat
 		"redisDbNodeSelector":           selectorsCopy,
 		"redisDbWaitStartServiceSecond": adminService.defaultRedisDbStartWait,
 	}
+	if adminService.sizeProfiles.configured() {
+		//the defaults are applied under the profile by convertSettings, in the request they would override it
+		delete(redisSettings, "redisDbResources")
+		delete(redisSettings, "redisDbNodeSelector")
+		redisSettings["availableProfiles"] = adminService.sizeProfiles.ordered
+		if adminService.sizeProfiles.defaultProfile != "" {
+			redisSettings["defaultProfile"] = adminService.sizeProfiles.defaultProfile
+		}
+	}
+	if adminService.redisVersions.configured() {
+		redisSettings["availableRedisVersions"] = adminService.redisVersions.versions
+	}
 	return dao.DbCreateRequest{
 		Settings: redisSettings,
 	}
@@ -293,11 +451,26 @@ func (adminService *AdministrationService) CreateDatabase(ctx context.Context, r
 		return "", nil, err
 	}
 
+	redisImage := adminService.redisImage
+	redisVersion, err := adminService.redisVersions.selected(settings)
+	if err != nil {
+		return "", nil, err
+	}
+	if redisVersion != nil {
+		redisImage = redisVersion.Image
+	}
+
+	if requestOnCreateDb.Password != "" {
+		if err = checkPassword(requestOnCreateDb.Password, adminService.passwordPolicy); err != nil {
+			return "", nil, err
+		}
+	}
+
 	if requestOnCreateDb.NamePrefix != nil {
 		if *requestOnCreateDb.NamePrefix != "" {
-			// check if prefix + generated name more then the maximum length
-			if len(*requestOnCreateDb.NamePrefix) > (dbNameLenghtLimit - len(logicalDatabaseName)) {
-				return "", nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("The combined length of the prefix [%s] and the database name [%s] can't be longer than 63 characters", *requestOnCreateDb.NamePrefix, logicalDatabaseName))
+			// check if prefix + delimiter + generated name more then the maximum length
+			if len(*requestOnCreateDb.NamePrefix)+1 > (dbNameLenghtLimit - len(logicalDatabaseName)) {
+				return "", nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("The combined length of the prefix [%s] and the database name [%s] can't be longer than %d characters", *requestOnCreateDb.NamePrefix, logicalDatabaseName, dbNameLenghtLimit-1))
 			}
 			logicalDatabaseName = *requestOnCreateDb.NamePrefix + "-" + logicalDatabaseName
 		}
@@ -305,11 +478,7 @@ func (adminService *AdministrationService) CreateDatabase(ctx context.Context, r
 		if classifier, ok := requestOnCreateDb.Metadata["classifier"].(map[string]interface{}); ok {
 			if namespace, ok := classifier["namespace"].(string); ok {
 				if microserviceName, ok := classifier["microserviceName"].(string); ok {
-					logicalDatabaseName, err = utils.PrepareDatabaseName(namespace, microserviceName, dbNameLenghtLimit)
-					if err != nil {
-						return "", nil, err
-					}
-					logicalDatabaseName = strings.ReplaceAll(logicalDatabaseName, "_", "-")
+					logicalDatabaseName = helper.ClassifierDbName(namespace, microserviceName, dbNameLenghtLimit)
 				}
 			}
 		} else {
@@ -328,18 +497,31 @@ func (adminService *AdministrationService) CreateDatabase(ctx context.Context, r
 	//check if deployment already exists
 	lo := []client.ListOption{
 		client.InNamespace(adminService.namespace),
-		client.MatchingLabelsSelector{labels.SelectorFromSet(map[string]string{constants.Name: logicalDatabaseName})},
+		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(map[string]string{constants.Name: logicalDatabaseName})},
 	}
 
 	redisDL, dErr := adminService.listRedisDeployments(lo)
 	if dErr != nil && !errors.IsNotFound(dErr) {
-		return "", nil, dErr
+		return "", nil, typedError(dErr, "failed to list Redis databases")
+	}
+	waitSeconds := adminService.defaultRedisDbStartWait
+	if settings.RedisDbWaitStartServiceSecond > 0 {
+		waitSeconds = settings.RedisDbWaitStartServiceSecond
 	}
 	for _, db := range redisDL.Items {
 		if logicalDatabaseName == db.Name {
-			return "", nil, dao.NewResourceAlreadyExistsError(fmt.Sprintf("Database %s already exists", logicalDatabaseName))
+			//the aggregator retries the request after a timeout
+			return adminService.existingDatabase(ctx, logicalDatabaseName, requestOnCreateDb, waitSeconds)
 		}
 	}
+	//the existing database is returned before the checks of a new one, so a retried request isn't rejected by a full quota
+	reserved, err := adminService.databaseNameReserved(ctx, logicalDatabaseName)
+	if err != nil {
+		return "", nil, err
+	}
+	if reserved {
+		return adminService.existingDatabase(ctx, logicalDatabaseName, requestOnCreateDb, waitSeconds)
+	}
 
 	type objectToCreate struct {
 		object client.Object
@@ -348,26 +530,74 @@ func (adminService *AdministrationService) CreateDatabase(ctx context.Context, r
 
 	var objectsToCreate []objectToCreate
 
-	certErr := common.UpdateCertificate(adminService.tls.Enabled, adminService.tls.ClusterIssuerName, logicalDatabaseName, adminService.namespace, adminService.kubeClient, adminService.runtimeScheme)
-	core.PanicError(certErr, logger.Error, "Failed to update TLS certificate")
-
-	// Making secret for pass
+	// Making secret for pass, it reserves the database name
 	credsSecretName := credsName(logicalDatabaseName)
 	secret, plainTextPass, redisEnvCredErr := adminService.storeCredentialsAndGetEnvForRedisInstance(credsSecretName, requestOnCreateDb.Password)
 	if redisEnvCredErr != nil {
 		return "", nil, redisEnvCredErr
 	}
-
-	envVarForRedisInstance := coreUtils.GetSecretEnvVar(redisPasswordConst, credsSecretName, constants.Password)
+	objectLabels := getClassifierLabels(requestOnCreateDb.Metadata)
+	objectLabels[templates.LogicalDatabase] = logicalDatabaseName
+	addLabels(secret, objectLabels)
+	if err = setClassifierAnnotation(secret, requestOnCreateDb.Metadata); err != nil {
+		return "", nil, err
+	}
+	tolerations := append(append([]v1.Toleration{}, adminService.tolerations...), settings.RedisDbTolerations...)
+	if err = adminService.preflightCheck(ctx, settings.RedisDbResources, settings.RedisDbNodeSelector, tolerations); err != nil {
+		return "", nil, err
+	}
+	releaseQuotas, err := adminService.checkQuotas(ctx, logicalDatabaseName, objectLabels, settings.RedisDbResources)
+	if err != nil {
+		return "", nil, err
+	}
+	defer releaseQuotas()
+	if err = adminService.reserveDatabaseName(ctx, logicalDatabaseName, secret); err != nil {
+		if _, ok := err.(*dao.ResourceAlreadyExistsError); ok {
+			return adminService.existingDatabase(ctx, logicalDatabaseName, requestOnCreateDb, waitSeconds)
+		}
+		return "", nil, typedError(err, fmt.Sprintf("failed to create credentials secret %s", credsSecretName))
+	}
 
 	objectsToCreate = append(objectsToCreate,
 		objectToCreate{secret, secret.ObjectMeta})
 
+	created := false
+	//rollback - delete all if any object has failed to create, the name is reserved so all the objects belong to this request
+	defer func() {
+		if !created {
+			for _, objectToCreate := range objectsToCreate {
+				core.DeleteRuntimeObject(adminService.kubeClient, objectToCreate.object)
+			}
+		}
+
+	}()
+
+	certErr := common.UpdateCertificate(adminService.tls.Enabled, adminService.tls.ClusterIssuerName, logicalDatabaseName, adminService.namespace, adminService.kubeClient, adminService.runtimeScheme,
+		map[string]string{templates.LogicalDatabase: logicalDatabaseName})
+	if certErr != nil {
+		return "", nil, typedError(certErr, "failed to update TLS certificate")
+	}
+
+	envVarForRedisInstance := coreUtils.GetSecretEnvVar(redisPasswordConst, credsSecretName, constants.Password)
+
 	// Making ConfigMap
-	redisConfig := GetRedisDefaultConfigMap(adminService.kubeClient, adminService.namespace, logger)
-	adminService.setRedisDatabaseSettings(ctx, &redisConfig, &settings.RedisDbSettings)
-	configString := RedisMapConfigToString(redisConfig)
-	configMap := templates.GetRedisConfigTemplate(logicalDatabaseName, adminService.namespace, configString)
+	defaultConfig, err := GetRedisDefaultConfig(adminService.kubeClient, adminService.namespace)
+	if err != nil {
+		return "", nil, err
+	}
+	redisConfig, err := adminService.setRedisDatabaseSettings(ctx, defaultConfig, settings.RedisDbSettings, settings.RedisDbResources)
+	if err != nil {
+		return "", nil, err
+	}
+	configMap := templates.GetRedisConfigTemplate(logicalDatabaseName, adminService.namespace, redisConfig.String())
+	settingsJson, err := json.Marshal(settings.RedisDbSettings)
+	if err != nil {
+		return "", nil, err
+	}
+	configMap.Annotations = map[string]string{
+		DbSettingsAnnotation:     string(settingsJson),
+		ConfigRevisionAnnotation: redisConfig.Revision(),
+	}
 	objectsToCreate = append(objectsToCreate, objectToCreate{configMap, configMap.ObjectMeta})
 
 	// The Redis Service
@@ -377,21 +607,21 @@ func (adminService *AdministrationService) CreateDatabase(ctx context.Context, r
 
 	objectsToCreate = append(objectsToCreate, objectToCreate{redisService, redisService.ObjectMeta})
 
-	envs := common.GetRedisEnvs(adminService.tls.TLS)
+	envs := common.GetRedisEnvs(adminService.tls)
 	envs = append(envs, envVarForRedisInstance)
 
 	// The Redis Deployment
 	redisDeployment := templates.GetRedisDeploymentTemplate(
 		logicalDatabaseName,
 		adminService.namespace,
-		adminService.redisImage,
+		redisImage,
 		adminService.redisArgs,
 		envs,
 		settings.RedisDbResources,
 		settings.RedisDbNodeSelector,
 		&adminService.securityContext,
 		adminService.serviceAccountName,
-		adminService.tolerations,
+		tolerations,
 		adminService.redisLabel,
 		adminService.redisImagePullPolicy,
 		adminService.tls,
@@ -400,38 +630,56 @@ func (adminService *AdministrationService) CreateDatabase(ctx context.Context, r
 		adminService.managedBy,
 	)
 
+	redisDeployment.Annotations = map[string]string{ConfigRevisionAnnotation: redisConfig.Revision()}
+	if settings.Profile != "" {
+		redisDeployment.Annotations[ProfileAnnotation] = settings.Profile
+	}
+	if redisVersion != nil {
+		redisDeployment.Annotations[RedisVersionAnnotation] = redisVersion.Version
+	}
+	redisDeployment.Spec.Template.Annotations = map[string]string{ConfigHashAnnotation: redisConfig.Revision()}
+
+	// The data volume claim keeps the data of the soft deleted database
+	if adminService.softDelete.enabled {
+		dataClaim := templates.GetRedisDataClaimTemplate(logicalDatabaseName, adminService.namespace,
+			adminService.softDelete.storageClass, adminService.softDelete.storageSize)
+		objectsToCreate = append(objectsToCreate, objectToCreate{dataClaim, dataClaim.ObjectMeta})
+		templates.SetDataVolumeClaim(redisDeployment, dataClaim.Name)
+	}
+
 	objectsToCreate = append(objectsToCreate, objectToCreate{redisDeployment, redisDeployment.ObjectMeta})
 
 	var createAndCheckErr error
+	createdAt := time.Now()
 
-	//rollback - delete all if any object has failed to create
-	defer func() {
-		if createAndCheckErr != nil {
-			for _, objectToCreate := range objectsToCreate {
-				core.DeleteRuntimeObject(adminService.kubeClient, objectToCreate.object)
-			}
-		}
-
-	}()
-
-	for _, objectToCreate := range objectsToCreate {
+	//the secret is already created by the reservation
+	for _, objectToCreate := range objectsToCreate[1:] {
+		addLabels(objectToCreate.object, objectLabels)
 		createAndCheckErr = core.CreateOrUpdateRuntimeObject(adminService.kubeClient, nil, nil, objectToCreate.object, objectToCreate.meta, true)
 		if createAndCheckErr != nil {
-			return "", nil, createAndCheckErr
+			return "", nil, typedError(createAndCheckErr, fmt.Sprintf("failed to create %s %s", objectToCreate.object.GetObjectKind().GroupVersionKind().Kind, objectToCreate.meta.Name))
 		}
 	}
+	//the deployment is counted in the quotas of the next requests
+	releaseQuotas()
 
 	connectionProperties := createConnectionProperties(logicalDatabaseName, plainTextPass, adminService.namespace, adminService.redisServicePort)
 
 	cp := &customEntity.ConnectionProperties{}
 	mapstructure.Decode(connectionProperties[0], cp)
-	createAndCheckErr = adminService.checkConnectAndSetMetadata(ctx, *cp, requestOnCreateDb)
+	createAndCheckErr = adminService.checkConnectAndSetMetadata(ctx, *cp, requestOnCreateDb, createdAt)
 	if createAndCheckErr != nil {
 		return "", nil, createAndCheckErr
 	}
+	//the version in the image tag may differ from the running one
+	if err = adminService.syncVersionLabels(ctx, logicalDatabaseName); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to set version labels of database %s", logicalDatabaseName), zap.Error(err))
+	}
 	resources := adminService.getDBResources(logicalDatabaseName)
 
 	logger.Info(fmt.Sprintf("Logical database with name %s has resources %+v", logicalDatabaseName, resources))
+	created = true
+	adminService.notifyInventoryChanged()
 
 	return logicalDatabaseName, &dao.LogicalDatabaseDescribed{ConnectionProperties: connectionProperties, Resources: resources}, nil
 }
@@ -440,34 +688,77 @@ func (adminService *AdministrationService) convertSettings(requestSettings map[s
 	settings := customEntity.DbCreateRequestSettings{
 		RedisDbResources: *adminService.redisResources.DeepCopy(),
 	}
+	if adminService.sizeProfiles.configured() {
+		settings.RedisDbNodeSelector = map[string]string{}
+		for key, value := range adminService.nodeSelector {
+			settings.RedisDbNodeSelector[key] = value
+		}
+	}
+	profile, err := adminService.sizeProfiles.selected(requestSettings)
+	if err != nil {
+		return nil, err
+	}
+	if profile != nil {
+		if err = applyProfile(&settings, profile); err != nil {
+			return nil, err
+		}
+	}
 
 	jsonSettings, err := json.Marshal(requestSettings)
 	if err != nil {
 		return nil, err
 	}
 
+	//the fields of the request override the ones of the profile, the maps are merged
 	err = json.Unmarshal(jsonSettings, &settings)
 
 	if err != nil {
-		return nil, err
+		return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid settings: %v", err))
 	}
 
 	return &settings, nil
 }
 
-func (adminService *AdministrationService) setRedisDatabaseSettings(ctx context.Context, parameters *map[string]interface{}, settings *map[string]interface{}) {
+func (adminService *AdministrationService) setRedisDatabaseSettings(ctx context.Context, defaultConfig *config.RedisConfig, settings map[string]interface{},
+	resources v1.ResourceRequirements) (*config.RedisConfig, error) {
 	logger := utils.AddLoggerContext(adminService.logger, ctx)
-	if *settings != nil {
-		for parameter, value := range *settings {
-			logger.Info(fmt.Sprintf("Redis database settings: parameter = %s, value = %+v", parameter, value))
-			(*parameters)[parameter] = value
+	redisConfig := defaultConfig.Copy()
+	if err := redisConfig.Merge(settings); err != nil {
+		return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid redisDbSettings: %v", err))
+	}
+	if err := redisConfig.Validate(); err != nil {
+		return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid redisDbSettings: %v", err))
+	}
+	if err := checkDatabaseMaxmemory(settings, resources); err != nil {
+		return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Invalid redisDbSettings: %v", err))
+	}
+	//without maxmemory Redis grows until the pod is killed by the memory limit
+	if _, ok := settings["maxmemory"]; !ok {
+		maxmemory, err := adminService.databaseMaxmemory(resources)
+		if err != nil {
+			return nil, err
 		}
+		if maxmemory != "" {
+			if err = redisConfig.Set("maxmemory", maxmemory); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, line := range config.Diff(defaultConfig, redisConfig) {
+		logger.Info(fmt.Sprintf("Redis database settings: %s", line))
 	}
+	return redisConfig, nil
 }
 
-func (adminService *AdministrationService) checkConnectAndSetMetadata(ctx context.Context, connectionProperties customEntity.ConnectionProperties, requestOnCreateDb dao.DbCreateRequest) error {
+// checkConnectAndSetMetadata waits for the database to start, the wait fails as soon as the pod of the database
+// can't start according to its state or the events occurred since the objects of the database were created.
+func (adminService *AdministrationService) checkConnectAndSetMetadata(ctx context.Context, connectionProperties customEntity.ConnectionProperties,
+	requestOnCreateDb dao.DbCreateRequest, createdAt time.Time) error {
 	logger := utils.AddLoggerContext(adminService.logger, ctx)
-	redisdb := adminService.createRedisClient(ctx, fmt.Sprintf("%s:%d", connectionProperties.Host, connectionProperties.Port), connectionProperties.Password, 0)
+	redisdb, err := adminService.createRedisClient(ctx, fmt.Sprintf("%s:%d", connectionProperties.Host, connectionProperties.Port), connectionProperties.Password, 0)
+	if err != nil {
+		return err
+	}
 	defer redisdb.Close()
 	timeWaitServiceSecond := adminService.defaultRedisDbStartWait
 	settings, err := adminService.convertSettings(requestOnCreateDb.Settings)
@@ -488,15 +779,22 @@ func (adminService *AdministrationService) checkConnectAndSetMetadata(ctx contex
 		}
 		logger.Info(fmt.Sprintf("Wait until service %s will start and redis-client will be able to perform connect (host\"%s\"). Time left %d, result %s, error %s", connectionProperties.Service,
 			redisdb.Addr(), timeWaitServiceSecond, result, err))
+		if (initialTime-timeWaitServiceSecond)%podCheckPeriodSecond == 0 {
+			if failure := adminService.podStartFailure(ctx, connectionProperties.Service, createdAt); failure != nil {
+				return failure
+			}
+		}
 		if timeWaitServiceSecond != 0 {
 			logger.Info(fmt.Sprint("sleep one second and then check connect again ..."))
 			time.Sleep(time.Second)
 			timeWaitServiceSecond--
 		} else {
-			return fmt.Errorf("the service %s could not start in %d second, err: %v", connectionProperties.Host, initialTime, err)
+			return customEntity.NewTimeoutError(fmt.Sprintf("The service %s could not start in %d second, err: %v", connectionProperties.Host, initialTime, err))
 		}
 	}
-	adminService.setMetadata(ctx, requestOnCreateDb.Metadata, redisdb)
+	if err = adminService.setMetadata(requestOnCreateDb.Metadata, redisdb); err != nil {
+		return err
+	}
 	logger.Info(fmt.Sprintf("Metadata %+v was set successfully", requestOnCreateDb.Metadata))
 
 	return nil
@@ -511,31 +809,41 @@ func createConnectionProperties(logicalDatabaseName string, password string, nam
 	return []dao.ConnectionProperties{cpMap}
 }
 
+// GetDatabases is the operation of the DBaaS adapter core interface, the error is logged and no databases are returned.
+// The adapter API lists the databases with ListDatabases.
 func (adminService *AdministrationService) GetDatabases(ctx context.Context) []string {
-	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	databases, err := adminService.ListDatabases(ctx)
+	adminService.logError(ctx, err)
+	return databases
+}
+
+// ListDatabases returns the names of the logical databases of the adapter.
+func (adminService *AdministrationService) ListDatabases(ctx context.Context) ([]string, error) {
 	lo := []client.ListOption{
 		client.InNamespace(adminService.namespace),
-		client.MatchingLabelsSelector{labels.SelectorFromSet(map[string]string{adminService.redisLabel: adminService.redisLabel})},
+		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(map[string]string{adminService.redisLabel: adminService.redisLabel})},
 	}
 
 	var result []string
 	redisDL, dErr := adminService.listRedisDeployments(lo)
 	if dErr != nil {
 		if !errors.IsNotFound(dErr) {
-			core.PanicError(dErr, logger.Error, "Failed listing Redis Databases")
-		} else {
-			return result
+			return nil, typedError(dErr, "failed to list Redis databases")
 		}
+		return result, nil
 	}
 	for _, deployment := range redisDL.Items {
 		result = append(result, deployment.ObjectMeta.Name)
 	}
-	return result
+	return result, nil
 }
 
 func (adminService *AdministrationService) DropResources(ctx context.Context, resources []dao.DbResource) []dao.DbResource {
 	logger := utils.AddLoggerContext(adminService.logger, ctx)
 	var dropStatuses []dao.DbResource
+	if adminService.softDelete.enabled {
+		dropStatuses, resources = adminService.dropDatabasesSoftly(ctx, resources)
+	}
 	for _, resource := range resources {
 		resourceKind := resource.Kind
 		resourceName := resource.Name
@@ -554,30 +862,49 @@ func (adminService *AdministrationService) DropResources(ctx context.Context, re
 		}
 		dropStatuses = append(dropStatuses, resource)
 	}
+	adminService.notifyInventoryChanged()
 	return dropStatuses
 }
 
-func (adminService *AdministrationService) readRedisDBPassword(ctx context.Context, serviceName string) string {
-	logger := utils.AddLoggerContext(adminService.logger, ctx)
+// getRedisDBPassword reads the password of the logical database from its credentials secret.
+func (adminService *AdministrationService) getRedisDBPassword(ctx context.Context, serviceName string) (string, error) {
 	passSecretName := credsName(serviceName)
-	var password string
 
 	// Read pass in secret
 	secretObj := v1.Secret{}
 	secretErr := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: passSecretName, Namespace: adminService.namespace}, &secretObj)
+	if errors.IsNotFound(secretErr) {
+		return "", customEntity.NewNotFoundError(fmt.Sprintf("Credentials secret %s of database %s is not found", passSecretName, serviceName))
+	}
 	if secretErr != nil {
-		core.PanicError(secretErr, logger.Error, fmt.Sprintf("Failed getting service %s password from secret %s", serviceName, passSecretName))
+		return "", typedError(secretErr, fmt.Sprintf("failed to read credentials secret %s", passSecretName))
 	}
-	password = string(secretObj.Data[constants.Password])
+	return string(secretObj.Data[constants.Password]), nil
+}
 
-	if password == "" {
-		core.PanicError(secretErr, logger.Error, fmt.Sprintf("The password for connect and update metadata was not found for %s", serviceName))
+// connectDatabase returns the client of the logical database with the password from its credentials secret.
+func (adminService *AdministrationService) connectDatabase(ctx context.Context, dbName string) (redis.RedisClientInterface, error) {
+	password, err := adminService.getRedisDBPassword(ctx, dbName)
+	if err != nil {
+		return nil, err
 	}
+	return adminService.createRedisClient(ctx, fmt.Sprintf("%s.%s:%d", dbName, adminService.namespace, adminService.redisServicePort), password, 0)
+}
 
-	return password
+// getRedisDeployment returns the Deployment of the logical database, NotFound error is returned for other Deployments.
+func (adminService *AdministrationService) getRedisDeployment(ctx context.Context, dbName string) (*v12.Deployment, error) {
+	deployment := &v12.Deployment{}
+	err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: dbName, Namespace: adminService.namespace}, deployment)
+	if errors.IsNotFound(err) || err == nil && deployment.Labels[adminService.redisLabel] != adminService.redisLabel {
+		return nil, customEntity.NewNotFoundError(fmt.Sprintf("Database %s is not found", dbName))
+	}
+	if err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to read deployment of database %s", dbName))
+	}
+	return deployment, nil
 }
 
-func (adminService *AdministrationService) createRedisClient(ctx context.Context, address string, password string, db int) redis.RedisClientInterface {
+func (adminService *AdministrationService) createRedisClient(ctx context.Context, address string, password string, db int) (redis.RedisClientInterface, error) {
 	logger := utils.AddLoggerContext(adminService.logger, ctx)
 	logger.Info(fmt.Sprintf("Create redis client with address %s", address))
 	var caCert []byte
@@ -585,19 +912,18 @@ func (adminService *AdministrationService) createRedisClient(ctx context.Context
 		var err error
 		caCert, err = ioutil.ReadFile(fmt.Sprintf("/usr/ssl/%s", adminService.tls.RootCAFileName))
 		if err != nil {
-			core.PanicError(err, logger.Error, "Failed to read root certificate")
+			return nil, fmt.Errorf("failed to read root certificate: %v", err)
 		}
 	}
-	return adminService.redisClient.InitRedisClient(address, password, string(caCert), 0, adminService.tls.Enabled)
+	return adminService.redisClient.InitRedisClient(address, password, string(caCert), 0, adminService.tls.Enabled), nil
 }
 
-func generatePassword(length int) string {
-	chars := []rune(passCharSet)
-	var b bytes.Buffer
-	for i := 0; i < length; i++ {
-		b.WriteRune(chars[rand.Intn(len(chars))])
+// logError logs the error of the operation of the DBaaS adapter core interface which can't return it,
+// the adapter API serves these operations with the variants returning the error.
+func (adminService *AdministrationService) logError(ctx context.Context, err error) {
+	if err != nil {
+		utils.AddLoggerContext(adminService.logger, ctx).Error(err.Error())
 	}
-	return b.String()
 }
 
 func (adminService *AdministrationService) storeCredentialsAndGetEnvForRedisInstance(secretName string, passwordFromRequest string) (*v1.Secret, string, error) {
@@ -606,7 +932,11 @@ func (adminService *AdministrationService) storeCredentialsAndGetEnvForRedisInst
 	if passwordFromRequest != "" {
 		password = passwordFromRequest
 	} else {
-		password = generatePassword(16)
+		var err error
+		password, err = generatePassword(adminService.passwordPolicy)
+		if err != nil {
+			return nil, "", err
+		}
 	}
 
 	// Password to return
@@ -625,11 +955,63 @@ func (adminService *AdministrationService) storeCredentialsAndGetEnvForRedisInst
 	return secret, returnPass, nil
 }
 
+// reserveDatabaseName creates the credentials secret of the database. Only one request can create it,
+// so concurrent requests for the same name fail with ResourceAlreadyExistsError instead of sharing the objects.
+func (adminService *AdministrationService) reserveDatabaseName(ctx context.Context, dbName string, secret *v1.Secret) error {
+	err := adminService.kubeClient.Create(ctx, secret)
+	if errors.IsAlreadyExists(err) {
+		return dao.NewResourceAlreadyExistsError(fmt.Sprintf("Database %s already exists", dbName))
+	}
+	return err
+}
+
+// getClassifierLabels returns the labels which identify the owner of the logical database by its classifier
+func getClassifierLabels(metadata map[string]interface{}) map[string]string {
+	result := map[string]string{}
+	classifier, ok := metadata["classifier"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+	for key, label := range map[string]string{
+		"namespace":        templates.ClassifierNamespace,
+		"microserviceName": templates.ClassifierMicroserviceName,
+	} {
+		if value, ok := classifier[key].(string); ok && helper.LabelValue(value) != "" {
+			result[label] = helper.LabelValue(value)
+		}
+	}
+	return result
+}
+
+func addLabels(object client.Object, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	objectLabels := object.GetLabels()
+	if objectLabels == nil {
+		objectLabels = map[string]string{}
+	}
+	for key, value := range labels {
+		objectLabels[key] = value
+	}
+	object.SetLabels(objectLabels)
+}
+
 func credsName(dbName string) string {
 	return dbName + credsSuffix
 }
 
+// DescribeDatabases is the operation of the DBaaS adapter core interface, the error is logged and no databases are described.
+// The adapter API describes the databases with DescribeLogicalDatabases.
 func (adminService *AdministrationService) DescribeDatabases(ctx context.Context, logicalDatabases []string, showResources bool, showConnections bool) map[string]dao.LogicalDatabaseDescribed {
+	described, err := adminService.DescribeLogicalDatabases(ctx, logicalDatabases, showResources, showConnections)
+	adminService.logError(ctx, err)
+	return described
+}
+
+// DescribeLogicalDatabases returns the resources and the connection properties of the logical databases.
+func (adminService *AdministrationService) DescribeLogicalDatabases(ctx context.Context, logicalDatabases []string,
+	showResources bool, showConnections bool) (map[string]dao.LogicalDatabaseDescribed, error) {
 	logger := utils.AddLoggerContext(adminService.logger, ctx)
 	describedLogicalDbs := make(map[string]dao.LogicalDatabaseDescribed)
 	for _, service := range logicalDatabases {
@@ -660,34 +1042,36 @@ func (adminService *AdministrationService) DescribeDatabases(ctx context.Context
 			logger.Info(fmt.Sprintf("Resources of database: %+v", describedLogicalDb.Resources))
 		}
 		if showConnections {
-			password := adminService.readRedisDBPassword(ctx, service)
+			password, err := adminService.getRedisDBPassword(ctx, service)
+			if err != nil {
+				return nil, err
+			}
 			conn := createConnectionProperties(service, password, adminService.namespace, adminService.redisServicePort)
 			describedLogicalDb.ConnectionProperties = conn
 		}
 		describedLogicalDbs[service] = describedLogicalDb
 	}
-	return describedLogicalDbs
-}
-
-func RedisMapConfigToString(redisConfig map[string]interface{}) (configString string) {
-	delimiter := ""
-	for key, value := range redisConfig {
-		configString += delimiter + key + " " + fmt.Sprintf("%v", value)
-		delimiter = "\n"
-	}
-	return configString
+	return describedLogicalDbs, nil
 }
 
-func GetRedisDefaultConfigMap(kubeClient client.Client, namespace string, log *zap.Logger) map[string]interface{} {
+// GetRedisDefaultConfig reads and validates the default Redis configuration from redis-default-conf ConfigMap.
+func GetRedisDefaultConfig(kubeClient client.Client, namespace string) (*config.RedisConfig, error) {
 	configMapFromCloud := &v1.ConfigMap{}
 	err := kubeClient.Get(context.TODO(),
 		types.NamespacedName{Name: RedisDefaultConfigMapName, Namespace: namespace}, configMapFromCloud)
-	core.PanicError(err, log.Error, "Redis configuration listing failed")
-
-	configBytesIn := configMapFromCloud.Data["config"]
-	configFromCloud := make(map[string]interface{})
-	err = yaml.Unmarshal([]byte(configBytesIn), &configFromCloud)
-	core.HandleError(err, log.Error, "Could not unmarshal passed yaml config!")
+	if errors.IsNotFound(err) {
+		return nil, customEntity.NewPreconditionFailedError(fmt.Sprintf("%s config map is not found", RedisDefaultConfigMapName))
+	}
+	if err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to read %s config map", RedisDefaultConfigMapName))
+	}
 
-	return configFromCloud
+	redisConfig, err := config.Parse(configMapFromCloud.Data["config"])
+	if err != nil {
+		return nil, customEntity.NewPreconditionFailedError(fmt.Sprintf("%s config map is invalid: %v", RedisDefaultConfigMapName, err))
+	}
+	if err = redisConfig.Validate(); err != nil {
+		return nil, customEntity.NewPreconditionFailedError(fmt.Sprintf("%s config map is invalid: %v", RedisDefaultConfigMapName, err))
+	}
+	return redisConfig, nil
 }
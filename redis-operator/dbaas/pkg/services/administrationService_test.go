@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "redis"
+	testLabel     = "redis"
+)
+
+// newTestAdministrationService returns the service with the default configuration over the fake client with the objects.
+func newTestAdministrationService(t *testing.T, objects ...client.Object) *AdministrationService {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	adminService, err := NewAdministrationService(AdministrationServiceOptions{
+		Logger:           zap.NewNop(),
+		KubeClient:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		RuntimeScheme:    scheme,
+		Namespace:        testNamespace,
+		RedisServicePort: 6379,
+		RedisLabel:       testLabel,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adminService
+}
+
+// testDatabase returns the credentials secret and the Deployment of the logical database created by the adapter.
+func testDatabase(name, namespace, microservice string, readyReplicas int32) (*v1.Secret, *appsv1.Deployment) {
+	classifierLabels := map[string]string{templates.LogicalDatabase: name}
+	if namespace != "" {
+		classifierLabels[templates.ClassifierNamespace] = namespace
+	}
+	if microservice != "" {
+		classifierLabels[templates.ClassifierMicroserviceName] = microservice
+	}
+	classifier, _ := json.Marshal(map[string]interface{}{"namespace": namespace, "microserviceName": microservice})
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        credsName(name),
+			Namespace:   testNamespace,
+			Labels:      classifierLabels,
+			Annotations: map[string]string{ClassifierAnnotation: string(classifier)},
+		},
+		Data: map[string][]byte{constants.Password: []byte("password")},
+	}
+
+	deploymentLabels := map[string]string{constants.Name: name, testLabel: testLabel}
+	for key, value := range classifierLabels {
+		deploymentLabels[key] = value
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: deploymentLabels},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "redis",
+						Env: []v1.EnvVar{{
+							Name: redisPasswordConst,
+							ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+								LocalObjectReference: v1.LocalObjectReference{Name: credsName(name)},
+								Key:                  constants.Password,
+							}},
+						}},
+						Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+							v1.ResourceMemory: resource.MustParse("256Mi"),
+							v1.ResourceCPU:    resource.MustParse("100m"),
+						}},
+					}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: readyReplicas},
+	}
+	return secret, deployment
+}
+
+func TestNewAdministrationServiceInvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		options AdministrationServiceOptions
+	}{
+		{name: "Soft delete", options: AdministrationServiceOptions{SoftDelete: &v2.SoftDelete{Enabled: true, Retention: "3 days"}}},
+		{name: "Password policy", options: AdministrationServiceOptions{PasswordPolicy: &v2.PasswordPolicy{Length: 8, MinLength: 12}}},
+		{name: "Default profile", options: AdministrationServiceOptions{DefaultProfile: "small"}},
+		{name: "Quotas", options: AdministrationServiceOptions{Quotas: &v2.Quotas{Overrides: []v2.QuotaOverride{{}}}}},
+		{name: "Redis versions", options: AdministrationServiceOptions{RedisVersions: []v2.RedisVersion{{Version: "7.2"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Logger = zap.NewNop()
+			if _, err := NewAdministrationService(tt.options); err == nil {
+				t.Errorf("NewAdministrationService() error = nil, want the configuration error")
+			}
+		})
+	}
+}
+
+func TestCreateDatabaseRetriedAtFullQuota(t *testing.T) {
+	otherSecret, otherDeployment := testDatabase("other", "app", "service", 1)
+	//the first request has reserved the name, but its Deployment isn't created yet
+	secret, _ := testDatabase("redisdb", "app", "service", 0)
+	adminService := newTestAdministrationService(t, otherSecret, otherDeployment, secret)
+	maxDatabases := 1
+	adminService.quotas.adapter = &v2.Quota{MaxDatabases: &maxDatabases}
+
+	noPrefix := ""
+	_, _, err := adminService.CreateDatabase(context.Background(), dao.DbCreateRequest{
+		DbName:     "redisdb",
+		NamePrefix: &noPrefix,
+		Metadata: map[string]interface{}{
+			"classifier": map[string]interface{}{"namespace": "app", "microserviceName": "service"},
+		},
+	})
+	var timeout *customEntity.TimeoutError
+	if !errors.As(err, &timeout) {
+		t.Fatalf("CreateDatabase() error = %v, want TimeoutError of the existing database which isn't ready", err)
+	}
+}
+
+func TestCreateDatabaseNamePrefixLength(t *testing.T) {
+	generatedName := "redisdb"
+	tests := []struct {
+		name        string
+		prefixLen   int
+		wantInvalid bool
+	}{
+		{name: "Longest prefix", prefixLen: dbNameLenghtLimit - len(generatedName) - 1},
+		{name: "Too long prefix", prefixLen: dbNameLenghtLimit - len(generatedName), wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := newTestAdministrationService(t)
+			prefix := strings.Repeat("p", tt.prefixLen)
+			//the default configuration is not deployed, so a valid name fails later
+			_, _, err := adminService.CreateDatabase(context.Background(), dao.DbCreateRequest{
+				DbName:     generatedName,
+				NamePrefix: &prefix,
+			})
+			var invalidArgument *customEntity.InvalidArgumentError
+			if errors.As(err, &invalidArgument) != tt.wantInvalid {
+				t.Errorf("CreateDatabase() error = %v, want InvalidArgumentError %t", err, tt.wantInvalid)
+			}
+		})
+	}
+}
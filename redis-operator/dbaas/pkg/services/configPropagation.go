@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/config"
+	v12 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DbSettingsAnnotation keeps redisDbSettings of the logical database, so its config can be rebuilt from new defaults
+	DbSettingsAnnotation = "netcracker.com/redis-db-settings"
+	// ConfigRevisionAnnotation is set on the ConfigMap with the rendered config revision
+	// and on the Deployment with the revision Redis is running
+	ConfigRevisionAnnotation = "netcracker.com/config-revision"
+	// ConfigHashAnnotation is the pod template annotation, its change restarts Redis
+	ConfigHashAnnotation = "netcracker.com/config-hash"
+	// ConfigErrorAnnotation is set on the Deployment with the error of the last propagation of the default config
+	ConfigErrorAnnotation = "netcracker.com/config-propagation-error"
+)
+
+// configPropagation runs one propagation at a time. A propagation requested while another one is running
+// is run after it by the service which requested it last, so the latest defaults are propagated.
+var configPropagation = struct {
+	sync.Mutex
+	running bool
+	next    *AdministrationService
+}{}
+
+// PropagateDefaultConfig validates redis-default-conf and starts its propagation to the logical databases
+// in the background, so the reconcile neither waits for the databases nor fails when some of them are unreachable.
+func (adminService *AdministrationService) PropagateDefaultConfig(ctx context.Context) error {
+	if _, err := GetRedisDefaultConfig(adminService.kubeClient, adminService.namespace); err != nil {
+		return err
+	}
+
+	configPropagation.Lock()
+	defer configPropagation.Unlock()
+	if configPropagation.running {
+		configPropagation.next = adminService
+		return nil
+	}
+	configPropagation.running = true
+	go func() {
+		next := adminService
+		for next != nil {
+			next.propagateDefaultConfig(ctx)
+
+			configPropagation.Lock()
+			next, configPropagation.next = configPropagation.next, nil
+			configPropagation.running = next != nil
+			configPropagation.Unlock()
+		}
+	}()
+	return nil
+}
+
+// propagateDefaultConfig rebuilds the config of every logical database from redis-default-conf
+// and the database redisDbSettings. Runtime changeable directives are applied with CONFIG SET,
+// the database is restarted only when a directive can't be changed at runtime.
+// A failure of a database doesn't stop the propagation to others, it is reported by ConfigErrorAnnotation
+// of the database Deployment. The number of the failed databases is returned.
+func (adminService *AdministrationService) propagateDefaultConfig(ctx context.Context) int {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	defaults, err := GetRedisDefaultConfig(adminService.kubeClient, adminService.namespace)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to propagate default Redis config, err: %v", err))
+		return 0
+	}
+
+	deployments, err := adminService.listRedisDeployments([]client.ListOption{
+		client.InNamespace(adminService.namespace),
+		client.MatchingLabels{adminService.redisLabel: adminService.redisLabel},
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list databases to propagate default Redis config, err: %v", err))
+		return 0
+	}
+
+	logger.Info(fmt.Sprintf("Propagating default Redis config revision %s to %d databases", defaults.Revision(), len(deployments.Items)))
+	failed := 0
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		err := adminService.propagateConfig(ctx, defaults, deployment)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to propagate default config to %s, err: %v", deployment.Name, err))
+			failed++
+		}
+		adminService.reportConfigError(ctx, deployment, err)
+	}
+	if failed > 0 {
+		logger.Warn(fmt.Sprintf("Default Redis config revision %s is not propagated to %d of %d databases, see %s annotation of their Deployments",
+			defaults.Revision(), failed, len(deployments.Items), ConfigErrorAnnotation))
+	}
+	return failed
+}
+
+// reportConfigError sets ConfigErrorAnnotation of the database Deployment to the error of the propagation,
+// the annotation is removed once the config is propagated.
+func (adminService *AdministrationService) reportConfigError(ctx context.Context, deployment *v12.Deployment, err error) {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	if deployment.Annotations[ConfigErrorAnnotation] == message {
+		return
+	}
+	patch := client.MergeFrom(deployment.DeepCopy())
+	if message == "" {
+		delete(deployment.Annotations, ConfigErrorAnnotation)
+	} else {
+		if deployment.Annotations == nil {
+			deployment.Annotations = map[string]string{}
+		}
+		deployment.Annotations[ConfigErrorAnnotation] = message
+	}
+	if patchErr := adminService.kubeClient.Patch(ctx, deployment, patch); patchErr != nil {
+		utils.AddLoggerContext(adminService.logger, ctx).Warn(fmt.Sprintf("Failed to report config propagation error of %s, err: %v", deployment.Name, patchErr))
+	}
+}
+
+// propagateConfig applies the config to the database first, with CONFIG SET or with the restart, then the Deployment
+// records the revision. A revision which is in the ConfigMap but is not recorded was left by an interrupted
+// propagation, the database is restarted as it is not known whether the config was applied.
+func (adminService *AdministrationService) propagateConfig(ctx context.Context, defaults *config.RedisConfig, deployment *v12.Deployment) error {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	name := deployment.Name
+
+	configMap := &v1.ConfigMap{}
+	if err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: adminService.namespace}, configMap); err != nil {
+		return err
+	}
+	current, err := config.ParseConf(configMap.Data["config"])
+	if err != nil {
+		return err
+	}
+
+	settings, err := dbSettings(configMap, defaults, current)
+	if err != nil {
+		return err
+	}
+	effective := defaults.Copy()
+	if err = effective.Merge(settings); err != nil {
+		return err
+	}
+	//maxmemory follows the memory limit, it could be changed with the Redis resources
+	if _, ok := settings["maxmemory"]; !ok && len(deployment.Spec.Template.Spec.Containers) > 0 {
+		maxmemory, err := adminService.databaseMaxmemory(deployment.Spec.Template.Spec.Containers[0].Resources)
+		if err != nil {
+			return err
+		}
+		if maxmemory != "" {
+			if err = effective.Set("maxmemory", maxmemory); err != nil {
+				return err
+			}
+		}
+	}
+	if err = effective.Validate(); err != nil {
+		return err
+	}
+	revision := effective.Revision()
+
+	changed := config.Changed(current, effective)
+	settingsJson, _ := json.Marshal(settings)
+	recorded := deployment.Annotations[ConfigRevisionAnnotation] == revision
+	if len(changed) == 0 && configMap.Annotations[DbSettingsAnnotation] == string(settingsJson) && recorded {
+		return nil
+	}
+
+	for _, line := range config.Diff(current, effective) {
+		logger.Info(fmt.Sprintf("Database %s config change: %s", name, line))
+	}
+
+	restart := !recorded && len(changed) == 0 && configMap.Annotations[ConfigRevisionAnnotation] == revision
+	var hot []string
+	for _, directive := range changed {
+		if config.RequiresRestart(directive) || len(effective.Get(directive)) == 0 {
+			restart = true
+		} else {
+			hot = append(hot, directive)
+		}
+	}
+	if !restart && len(hot) > 0 {
+		if err = adminService.configSet(ctx, name, effective, hot); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to apply config to %s at runtime, the database is going to be restarted, err: %v", name, err))
+			restart = true
+		}
+	}
+
+	//the restarted pods read the config from the ConfigMap, so it is updated before the Deployment
+	configMapPatch := client.MergeFrom(configMap.DeepCopy())
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	configMap.Annotations[DbSettingsAnnotation] = string(settingsJson)
+	configMap.Annotations[ConfigRevisionAnnotation] = revision
+	configMap.Data["config"] = effective.String()
+	if err = adminService.kubeClient.Patch(ctx, configMap, configMapPatch); err != nil {
+		return err
+	}
+
+	deploymentPatch := client.MergeFrom(deployment.DeepCopy())
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[ConfigRevisionAnnotation] = revision
+	if restart {
+		logger.Info(fmt.Sprintf("Database %s is restarted to apply config revision %s, changed: %s", name, revision, strings.Join(changed, ", ")))
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[ConfigHashAnnotation] = revision
+	} else if len(hot) > 0 {
+		logger.Info(fmt.Sprintf("Database %s applied %s at runtime", name, strings.Join(hot, ", ")))
+	}
+	return adminService.kubeClient.Patch(ctx, deployment, deploymentPatch)
+}
+
+// dbSettings returns redisDbSettings of the database. Databases created before the settings were stored
+// keep all directives which differ from the current defaults.
+func dbSettings(configMap *v1.ConfigMap, defaults *config.RedisConfig, current *config.RedisConfig) (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
+	if stored, ok := configMap.Annotations[DbSettingsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(stored), &settings); err != nil {
+			return nil, fmt.Errorf("failed to read %s annotation: %v", DbSettingsAnnotation, err)
+		}
+		return settings, nil
+	}
+
+	for _, name := range config.Changed(defaults, current) {
+		switch values := current.Get(name); len(values) {
+		case 0:
+		case 1:
+			settings[name] = values[0]
+		default:
+			settings[name] = values
+		}
+	}
+	return settings, nil
+}
+
+func (adminService *AdministrationService) configSet(ctx context.Context, name string, redisConfig *config.RedisConfig, directives []string) error {
+	redisdb, err := adminService.connectDatabase(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer redisdb.Close()
+
+	for _, directive := range directives {
+		// repeated directives are set at once, e.g. CONFIG SET save "900 1 300 10"
+		if err := redisdb.ConfigSet(directive, configSetValue(redisConfig.Get(directive))); err != nil {
+			return typedError(err, fmt.Sprintf("CONFIG SET %s failed", directive))
+		}
+	}
+	return nil
+}
+
+// configSetValue joins the values of the directive, the quotes of the config file are removed, e.g. save "" is set as an empty value.
+func configSetValue(values []string) string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+			value = unquoted
+		}
+		result = append(result, value)
+	}
+	return strings.Join(result, " ")
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/config"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis/mocks"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const testDefaultConfig = "port: \"6379\"\ndatabases: \"16\"\nmaxmemory-policy: \"allkeys-lru\""
+
+func parseTestConfig(t *testing.T, data string) *config.RedisConfig {
+	t.Helper()
+	parsed, err := config.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+// configDatabase returns the objects of the database with the rendered config, its memory is not limited
+// to not derive maxmemory. The annotations are set if the revision is not empty.
+func configDatabase(name string, rendered *config.RedisConfig, revision string, settings string) []client.Object {
+	secret, deployment := testDatabase(name, "app", "service", 1)
+	deployment.Spec.Template.Spec.Containers[0].Resources = v1.ResourceRequirements{}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Annotations: map[string]string{}},
+		Data:       map[string]string{"config": rendered.String()},
+	}
+	if revision != "" {
+		configMap.Annotations[ConfigRevisionAnnotation] = revision
+		deployment.Annotations = map[string]string{ConfigRevisionAnnotation: revision}
+	}
+	if settings != "" {
+		configMap.Annotations[DbSettingsAnnotation] = settings
+	}
+	return []client.Object{secret, deployment, configMap}
+}
+
+func defaultConfigMap(data string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: RedisDefaultConfigMapName, Namespace: testNamespace},
+		Data:       map[string]string{"config": data},
+	}
+}
+
+func getConfigObjects(t *testing.T, adminService *AdministrationService, name string) (*v1.ConfigMap, *appsv1.Deployment) {
+	t.Helper()
+	configMap := &v1.ConfigMap{}
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Name: name, Namespace: testNamespace}
+	if err := adminService.kubeClient.Get(context.Background(), key, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if err := adminService.kubeClient.Get(context.Background(), key, deployment); err != nil {
+		t.Fatal(err)
+	}
+	return configMap, deployment
+}
+
+func TestPropagateConfig(t *testing.T) {
+	defaults := parseTestConfig(t, testDefaultConfig)
+	noEviction := parseTestConfig(t, "port: \"6379\"\ndatabases: \"16\"\nmaxmemory-policy: \"noeviction\"")
+	moreDatabases := parseTestConfig(t, "port: \"6379\"\ndatabases: \"32\"\nmaxmemory-policy: \"allkeys-lru\"")
+	tests := []struct {
+		name      string
+		objects   []client.Object
+		configSet error
+		// wantConfigSet is false if CONFIG SET is not called
+		wantConfigSet bool
+		wantRestart   bool
+	}{
+		{
+			name:    "Unchanged",
+			objects: configDatabase("redisdb", defaults, defaults.Revision(), "{}"),
+		},
+		{
+			name:    "Legacy database",
+			objects: configDatabase("redisdb", defaults, "", ""),
+		},
+		{
+			name:          "Runtime change",
+			objects:       configDatabase("redisdb", noEviction, noEviction.Revision(), "{}"),
+			wantConfigSet: true,
+		},
+		{
+			name:          "Runtime change failed",
+			objects:       configDatabase("redisdb", noEviction, noEviction.Revision(), "{}"),
+			configSet:     errors.New("ERR unsupported"),
+			wantConfigSet: true,
+			wantRestart:   true,
+		},
+		{
+			name:        "Restart directive",
+			objects:     configDatabase("redisdb", moreDatabases, moreDatabases.Revision(), "{}"),
+			wantRestart: true,
+		},
+		{
+			name: "Interrupted restart",
+			objects: func() []client.Object {
+				objects := configDatabase("redisdb", defaults, defaults.Revision(), "{}")
+				objects[1].SetAnnotations(map[string]string{ConfigRevisionAnnotation: moreDatabases.Revision()})
+				return objects
+			}(),
+			wantRestart: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := newTestAdministrationService(t, tt.objects...)
+			redisClient := mocks.NewRedisClientInterface(t)
+			if tt.wantConfigSet {
+				redisClient.On("InitRedisClient", mock.Anything, "password", mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+				redisClient.On("ConfigSet", "maxmemory-policy", "allkeys-lru").Return(tt.configSet)
+				redisClient.On("Close").Return(nil)
+			}
+			adminService.redisClient = redisClient
+			deployment := tt.objects[1].(*appsv1.Deployment)
+
+			if err := adminService.propagateConfig(context.Background(), defaults, deployment); err != nil {
+				t.Fatal(err)
+			}
+			configMap, stored := getConfigObjects(t, adminService, "redisdb")
+			if configMap.Data["config"] != defaults.String() || configMap.Annotations[ConfigRevisionAnnotation] != defaults.Revision() {
+				t.Errorf("config map = %q revision %s, want %q revision %s", configMap.Data["config"],
+					configMap.Annotations[ConfigRevisionAnnotation], defaults.String(), defaults.Revision())
+			}
+			if stored.Annotations[ConfigRevisionAnnotation] != defaults.Revision() {
+				t.Errorf("Deployment revision = %s, want %s", stored.Annotations[ConfigRevisionAnnotation], defaults.Revision())
+			}
+			if restarted := stored.Spec.Template.Annotations[ConfigHashAnnotation] == defaults.Revision(); restarted != tt.wantRestart {
+				t.Errorf("database restarted = %t, want %t", restarted, tt.wantRestart)
+			}
+		})
+	}
+}
+
+// failingPatches returns the client which fails the patches of the objects of the type.
+func failingPatches(t *testing.T, objects []client.Object, failed client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, client client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if _, ok := failed.(*v1.ConfigMap); ok {
+				if _, isConfigMap := obj.(*v1.ConfigMap); isConfigMap {
+					return errors.New("conflict")
+				}
+			}
+			if _, ok := failed.(*appsv1.Deployment); ok {
+				if _, isDeployment := obj.(*appsv1.Deployment); isDeployment {
+					return errors.New("conflict")
+				}
+			}
+			return client.Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+}
+
+func TestPropagateConfigRecordsRevisionLast(t *testing.T) {
+	defaults := parseTestConfig(t, testDefaultConfig)
+	moreDatabases := parseTestConfig(t, "port: \"6379\"\ndatabases: \"32\"\nmaxmemory-policy: \"allkeys-lru\"")
+	noEviction := parseTestConfig(t, "port: \"6379\"\ndatabases: \"16\"\nmaxmemory-policy: \"noeviction\"")
+
+	t.Run("Restart interrupted before the Deployment", func(t *testing.T) {
+		objects := configDatabase("redisdb", moreDatabases, moreDatabases.Revision(), "{}")
+		adminService := newTestAdministrationService(t)
+		adminService.kubeClient = failingPatches(t, objects, &appsv1.Deployment{})
+		if err := adminService.propagateConfig(context.Background(), defaults, objects[1].(*appsv1.Deployment)); err == nil {
+			t.Fatal("propagateConfig() succeeded with the failed Deployment patch")
+		}
+
+		configMap, deployment := getConfigObjects(t, adminService, "redisdb")
+		if deployment.Annotations[ConfigRevisionAnnotation] == defaults.Revision() {
+			t.Fatalf("revision %s is recorded, but the database is not restarted", defaults.Revision())
+		}
+		adminService.kubeClient = failingPatches(t, []client.Object{objects[0], configMap, deployment}, nil)
+		if err := adminService.propagateConfig(context.Background(), defaults, deployment); err != nil {
+			t.Fatal(err)
+		}
+		_, deployment = getConfigObjects(t, adminService, "redisdb")
+		if deployment.Spec.Template.Annotations[ConfigHashAnnotation] != defaults.Revision() ||
+			deployment.Annotations[ConfigRevisionAnnotation] != defaults.Revision() {
+			t.Errorf("Deployment = %v %v, want restarted with revision %s", deployment.Annotations,
+				deployment.Spec.Template.Annotations, defaults.Revision())
+		}
+	})
+
+	t.Run("Runtime change interrupted before the ConfigMap", func(t *testing.T) {
+		objects := configDatabase("redisdb", noEviction, noEviction.Revision(), "{}")
+		adminService := newTestAdministrationService(t)
+		adminService.kubeClient = failingPatches(t, objects, &v1.ConfigMap{})
+		redisClient := mocks.NewRedisClientInterface(t)
+		redisClient.On("InitRedisClient", mock.Anything, "password", mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+		redisClient.On("ConfigSet", "maxmemory-policy", "allkeys-lru").Return(nil).Once()
+		redisClient.On("Close").Return(nil)
+		adminService.redisClient = redisClient
+
+		if err := adminService.propagateConfig(context.Background(), defaults, objects[1].(*appsv1.Deployment)); err == nil {
+			t.Fatal("propagateConfig() succeeded with the failed ConfigMap patch")
+		}
+		_, deployment := getConfigObjects(t, adminService, "redisdb")
+		if deployment.Annotations[ConfigRevisionAnnotation] == defaults.Revision() {
+			t.Errorf("revision %s is recorded, but the ConfigMap is not updated", defaults.Revision())
+		}
+		redisClient.AssertNumberOfCalls(t, "ConfigSet", 1)
+	})
+}
+
+func TestPropagateDefaultConfigReportsFailures(t *testing.T) {
+	defaults := parseTestConfig(t, testDefaultConfig)
+	objects := configDatabase("redisdb", defaults, "", "")
+	_, broken := testDatabase("brokendb", "app", "service", 1)
+	adminService := newTestAdministrationService(t, append(objects, broken, defaultConfigMap(testDefaultConfig))...)
+
+	if failed := adminService.propagateDefaultConfig(context.Background()); failed != 1 {
+		t.Errorf("propagateDefaultConfig() failed %d databases, want 1", failed)
+	}
+	_, deployment := getConfigObjects(t, adminService, "redisdb")
+	if deployment.Annotations[ConfigRevisionAnnotation] != defaults.Revision() || deployment.Annotations[ConfigErrorAnnotation] != "" {
+		t.Errorf("redisdb annotations = %v, want revision %s without error", deployment.Annotations, defaults.Revision())
+	}
+	brokenDeployment := &appsv1.Deployment{}
+	if err := adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: "brokendb", Namespace: testNamespace}, brokenDeployment); err != nil {
+		t.Fatal(err)
+	}
+	if brokenDeployment.Annotations[ConfigErrorAnnotation] == "" {
+		t.Errorf("brokendb annotations = %v, want %s", brokenDeployment.Annotations, ConfigErrorAnnotation)
+	}
+
+	brokenConfig := configDatabase("brokendb", defaults, "", "")[2]
+	if err := adminService.kubeClient.Create(context.Background(), brokenConfig); err != nil {
+		t.Fatal(err)
+	}
+	if failed := adminService.propagateDefaultConfig(context.Background()); failed != 0 {
+		t.Errorf("propagateDefaultConfig() failed %d databases, want 0", failed)
+	}
+	if err := adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: "brokendb", Namespace: testNamespace}, brokenDeployment); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := brokenDeployment.Annotations[ConfigErrorAnnotation]; ok {
+		t.Errorf("brokendb annotations = %v, want the error removed", brokenDeployment.Annotations)
+	}
+}
+
+func TestPropagateDefaultConfigInBackground(t *testing.T) {
+	defaults := parseTestConfig(t, testDefaultConfig)
+	adminService := newTestAdministrationService(t, configDatabase("redisdb", defaults, "", "")...)
+	if err := adminService.PropagateDefaultConfig(context.Background()); err == nil {
+		t.Fatal("PropagateDefaultConfig() succeeded without redis-default-conf")
+	}
+
+	adminService = newTestAdministrationService(t, append(configDatabase("redisdb", defaults, "", ""), defaultConfigMap(testDefaultConfig))...)
+	if err := adminService.PropagateDefaultConfig(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, deployment := getConfigObjects(t, adminService, "redisdb")
+		if deployment.Annotations[ConfigRevisionAnnotation] == defaults.Revision() {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("revision %s is not propagated in the background", defaults.Revision())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis"
+)
+
+const (
+	DefaultSlowLogCount = 128
+	MaxSlowLogCount     = 1024
+)
+
+// Diagnostics is the current slowlog and latency state of a logical database.
+type Diagnostics struct {
+	Name             string                            `json:"name"`
+	SlowLog          []redis.SlowLogEntry              `json:"slowLog"`
+	LatencyLatest    []redis.LatencyEvent              `json:"latencyLatest"`
+	LatencyHistogram map[string]redis.LatencyHistogram `json:"latencyHistogram,omitempty"`
+	// Errors of the commands which are not supported by the Redis version, e.g. LATENCY HISTOGRAM before 7.0
+	Errors []string `json:"errors,omitempty"`
+}
+
+// GetDiagnostics reads the slowlog and latency stats of the logical database on demand.
+// The arguments of the slowlog commands are redacted. A NotFound error is returned if the database does not exist.
+func (adminService *AdministrationService) GetDiagnostics(ctx context.Context, dbName string, slowLogCount int64) (*Diagnostics, error) {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+
+	if _, err := adminService.getRedisDeployment(ctx, dbName); err != nil {
+		return nil, err
+	}
+
+	redisdb, err := adminService.connectDatabase(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer redisdb.Close()
+
+	diagnostics := &Diagnostics{Name: dbName}
+	if diagnostics.SlowLog, err = redisdb.SlowLogGet(slowLogCount); err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to read slowlog of %s", dbName))
+	}
+	if diagnostics.LatencyLatest, err = redisdb.LatencyLatest(); err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to read latency events of %s", dbName))
+	}
+	if diagnostics.LatencyHistogram, err = redisdb.LatencyHistogram(); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to read latency histogram of %s: %v", dbName, err))
+		diagnostics.Errors = append(diagnostics.Errors, fmt.Sprintf("LATENCY HISTOGRAM: %v", err))
+	}
+	return diagnostics, nil
+}
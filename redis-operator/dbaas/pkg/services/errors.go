@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// typedError converts the error of Kubernetes API or Redis to the typed error of the adapter API,
+// the message describes the failed operation. Typed errors are returned as is.
+func typedError(err error, message string) error {
+	if err == nil || isTypedError(err) {
+		return err
+	}
+	text := fmt.Sprintf("%s: %v", message, err)
+	var netErr net.Error
+	switch {
+	case apierrors.IsNotFound(err):
+		return customEntity.NewNotFoundError(text)
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return customEntity.NewTimeoutError(text)
+	case apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota"):
+		return customEntity.NewQuotaExceededError(text)
+	case apierrors.IsServiceUnavailable(err), apierrors.IsTooManyRequests(err), errors.As(err, &netErr):
+		return customEntity.NewBackendUnavailableError(text)
+	}
+	return errors.New(text)
+}
+
+// withMessage adds the message to the typed error keeping its type, other errors are converted by typedError.
+func withMessage(err error, message string) error {
+	text := fmt.Sprintf("%s: %v", message, err)
+	var invalidArgument *customEntity.InvalidArgumentError
+	var alreadyExists *customEntity.ResourceAlreadyExistsError
+	var tooManyRequests *customEntity.TooManyRequestsError
+	var notFound *customEntity.NotFoundError
+	var preconditionFailed *customEntity.PreconditionFailedError
+	var backendUnavailable *customEntity.BackendUnavailableError
+	var timeout *customEntity.TimeoutError
+	var quotaExceeded *customEntity.QuotaExceededError
+	switch {
+	case errors.As(err, &invalidArgument):
+		return customEntity.NewInvalidArgumentError(text)
+	case errors.As(err, &alreadyExists):
+		return customEntity.NewResourceAlreadyExistsError(text)
+	case errors.As(err, &tooManyRequests):
+		return customEntity.NewTooManyRequestsError(text)
+	case errors.As(err, &notFound):
+		return customEntity.NewNotFoundError(text)
+	case errors.As(err, &preconditionFailed):
+		return customEntity.NewPreconditionFailedError(text)
+	case errors.As(err, &backendUnavailable):
+		return customEntity.NewBackendUnavailableError(text)
+	case errors.As(err, &timeout):
+		return customEntity.NewTimeoutError(text)
+	case errors.As(err, &quotaExceeded):
+		return customEntity.NewQuotaExceededError(text)
+	}
+	return typedError(err, message)
+}
+
+func isTypedError(err error) bool {
+	var invalidArgument *customEntity.InvalidArgumentError
+	var alreadyExists *customEntity.ResourceAlreadyExistsError
+	var tooManyRequests *customEntity.TooManyRequestsError
+	var notFound *customEntity.NotFoundError
+	var preconditionFailed *customEntity.PreconditionFailedError
+	var backendUnavailable *customEntity.BackendUnavailableError
+	var timeout *customEntity.TimeoutError
+	var quotaExceeded *customEntity.QuotaExceededError
+	var coreInvalidArgument *dao.InvalidArgumentError
+	var coreAlreadyExists *dao.ResourceAlreadyExistsError
+	return errors.As(err, &invalidArgument) || errors.As(err, &alreadyExists) || errors.As(err, &tooManyRequests) ||
+		errors.As(err, &notFound) || errors.As(err, &preconditionFailed) || errors.As(err, &backendUnavailable) ||
+		errors.As(err, &timeout) || errors.As(err, &quotaExceeded) ||
+		errors.As(err, &coreInvalidArgument) || errors.As(err, &coreAlreadyExists)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+)
+
+func TestWithMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "Timeout", err: customEntity.NewTimeoutError("not ready")},
+		{name: "Precondition failed", err: customEntity.NewPreconditionFailedError("ImagePullBackOff")},
+		{name: "Quota exceeded", err: customEntity.NewQuotaExceededError("exceeded quota")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withMessage(tt.err, "rolled back")
+			if got.Error() != "rolled back: "+tt.err.Error() {
+				t.Errorf("withMessage() = %q", got.Error())
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.err) {
+				t.Errorf("withMessage() = %T, want %T", got, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ClassifierAnnotation keeps the classifier of the create request on the credentials secret,
+// a retried request with the same classifier gets the existing database
+const ClassifierAnnotation = "netcracker.com/classifier"
+
+// setClassifierAnnotation stores the classifier of the request on the secret which reserves the database name.
+func setClassifierAnnotation(secret *v1.Secret, metadata map[string]interface{}) error {
+	classifier, err := json.Marshal(metadata["classifier"])
+	if err != nil {
+		return err
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[ClassifierAnnotation] = string(classifier)
+	return nil
+}
+
+// databaseNameReserved returns true if the credentials secret of the database exists, i.e. the database is created,
+// is being created by another request or is soft deleted.
+func (adminService *AdministrationService) databaseNameReserved(ctx context.Context, dbName string) (bool, error) {
+	secret := &v1.Secret{}
+	err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: credsName(dbName), Namespace: adminService.namespace}, secret)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, typedError(err, fmt.Sprintf("failed to read credentials secret %s", credsName(dbName)))
+	}
+	return true, nil
+}
+
+// existingDatabase returns the database with the same name if it was created by the same request, e.g. retried by
+// the aggregator after a timeout. The creation in progress is awaited. Otherwise ResourceAlreadyExistsError is returned.
+func (adminService *AdministrationService) existingDatabase(ctx context.Context, dbName string,
+	requestOnCreateDb dao.DbCreateRequest, waitSeconds int) (string, *dao.LogicalDatabaseDescribed, error) {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	conflict := dao.NewResourceAlreadyExistsError(fmt.Sprintf("Database %s already exists", dbName))
+
+	secret := &v1.Secret{}
+	err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: credsName(dbName), Namespace: adminService.namespace}, secret)
+	if errors.IsNotFound(err) {
+		return "", nil, conflict
+	}
+	if err != nil {
+		return "", nil, typedError(err, fmt.Sprintf("failed to read credentials secret %s", credsName(dbName)))
+	}
+	if secret.Labels[templates.PendingDeletion] == "true" {
+		return "", nil, dao.NewResourceAlreadyExistsError(fmt.Sprintf("Database %s is deleted, it can be restored until its retention expires", dbName))
+	}
+	password := string(secret.Data[constants.Password])
+	if requestOnCreateDb.Password != "" && requestOnCreateDb.Password != password {
+		return "", nil, conflict
+	}
+
+	same, err := adminService.sameClassifier(ctx, dbName, secret, requestOnCreateDb.Metadata)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to read the classifier of existing database %s, err: %v", dbName, err))
+		return "", nil, conflict
+	}
+	if !same {
+		return "", nil, conflict
+	}
+
+	//the first request can still create the database
+	for elapsed := 0; ; elapsed++ {
+		deployment := &appsv1.Deployment{}
+		err = adminService.kubeClient.Get(ctx, types.NamespacedName{Name: dbName, Namespace: adminService.namespace}, deployment)
+		if err != nil && !errors.IsNotFound(err) {
+			return "", nil, typedError(err, fmt.Sprintf("failed to read deployment of database %s", dbName))
+		}
+		if err == nil && deployment.Status.ReadyReplicas > 0 {
+			break
+		}
+		if elapsed >= waitSeconds {
+			return "", nil, customEntity.NewTimeoutError(fmt.Sprintf("Database %s is requested again, but it is not ready in %d seconds", dbName, waitSeconds))
+		}
+		time.Sleep(time.Second)
+	}
+
+	logger.Info(fmt.Sprintf("Database %s already exists with the same classifier, its connection properties are returned", dbName))
+	return dbName, &dao.LogicalDatabaseDescribed{
+		ConnectionProperties: createConnectionProperties(dbName, password, adminService.namespace, adminService.redisServicePort),
+		Resources:            adminService.getDBResources(dbName),
+	}, nil
+}
+
+// sameClassifier compares the classifier of the request with the one stored on the secret, or in the database
+// metadata for the databases created before the classifier was stored on the secret.
+func (adminService *AdministrationService) sameClassifier(ctx context.Context, dbName string, secret *v1.Secret,
+	metadata map[string]interface{}) (bool, error) {
+	requested, err := normalizeClassifier(metadata["classifier"])
+	if err != nil {
+		return false, err
+	}
+
+	var stored interface{}
+	if annotation, ok := secret.Annotations[ClassifierAnnotation]; ok {
+		if err = json.Unmarshal([]byte(annotation), &stored); err != nil {
+			return false, err
+		}
+	} else {
+		redisdb, clientErr := adminService.createRedisClient(ctx, fmt.Sprintf("%s.%s:%d", dbName, adminService.namespace, adminService.redisServicePort),
+			string(secret.Data[constants.Password]), 0)
+		if clientErr != nil {
+			return false, clientErr
+		}
+		defer redisdb.Close()
+		value, getErr := redisdb.Get("dbaas.metadata")
+		if getErr != nil {
+			return false, getErr
+		}
+		var storedMetadata map[string]interface{}
+		if err = json.Unmarshal([]byte(value), &storedMetadata); err != nil {
+			return false, err
+		}
+		stored = storedMetadata["classifier"]
+	}
+	if requested == nil || stored == nil {
+		return false, nil
+	}
+	return reflect.DeepEqual(requested, stored), nil
+}
+
+// normalizeClassifier converts the classifier to the same types as it has after it is read from JSON.
+func normalizeClassifier(classifier interface{}) (interface{}, error) {
+	if classifier == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(classifier)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis/mocks"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestExistingDatabase(t *testing.T) {
+	classifier := func(namespace, microservice string) map[string]interface{} {
+		return map[string]interface{}{"classifier": map[string]interface{}{"namespace": namespace, "microserviceName": microservice}}
+	}
+	tests := []struct {
+		name string
+		//prepare changes the secret of the existing database, it is ready unless notReady is set
+		prepare   func(secret *v1.Secret, redisClient *mocks.RedisClientInterface)
+		notReady  bool
+		request   dao.DbCreateRequest
+		wantError interface{}
+	}{
+		{
+			name:    "Matching classifier",
+			request: dao.DbCreateRequest{Metadata: classifier("app", "service")},
+		},
+		{
+			name:    "Matching classifier and password",
+			request: dao.DbCreateRequest{Metadata: classifier("app", "service"), Password: "password"},
+		},
+		{
+			name:      "Mismatched classifier",
+			request:   dao.DbCreateRequest{Metadata: classifier("app", "other")},
+			wantError: &dao.ResourceAlreadyExistsError{},
+		},
+		{
+			name:      "Mismatched password",
+			request:   dao.DbCreateRequest{Metadata: classifier("app", "service"), Password: "other"},
+			wantError: &dao.ResourceAlreadyExistsError{},
+		},
+		{
+			name:      "No classifier in the request",
+			wantError: &dao.ResourceAlreadyExistsError{},
+		},
+		{
+			name: "Pending deletion",
+			prepare: func(secret *v1.Secret, redisClient *mocks.RedisClientInterface) {
+				secret.Labels[templates.PendingDeletion] = "true"
+			},
+			request:   dao.DbCreateRequest{Metadata: classifier("app", "service")},
+			wantError: &dao.ResourceAlreadyExistsError{},
+		},
+		{
+			name: "Legacy database with the classifier in its metadata",
+			prepare: func(secret *v1.Secret, redisClient *mocks.RedisClientInterface) {
+				delete(secret.Annotations, ClassifierAnnotation)
+				redisClient.On("InitRedisClient", mock.Anything, "password", mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+				redisClient.On("Get", "dbaas.metadata").Return(`{"classifier":{"namespace":"app","microserviceName":"service"}}`, nil)
+				redisClient.On("Close").Return(nil)
+			},
+			request: dao.DbCreateRequest{Metadata: classifier("app", "service")},
+		},
+		{
+			name: "Legacy database with another classifier",
+			prepare: func(secret *v1.Secret, redisClient *mocks.RedisClientInterface) {
+				delete(secret.Annotations, ClassifierAnnotation)
+				redisClient.On("InitRedisClient", mock.Anything, "password", mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+				redisClient.On("Get", "dbaas.metadata").Return(`{"classifier":{"namespace":"app","microserviceName":"other"}}`, nil)
+				redisClient.On("Close").Return(nil)
+			},
+			request:   dao.DbCreateRequest{Metadata: classifier("app", "service")},
+			wantError: &dao.ResourceAlreadyExistsError{},
+		},
+		{
+			name:      "Not ready in time",
+			notReady:  true,
+			request:   dao.DbCreateRequest{Metadata: classifier("app", "service")},
+			wantError: &customEntity.TimeoutError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readyReplicas := int32(1)
+			if tt.notReady {
+				readyReplicas = 0
+			}
+			secret, deployment := testDatabase("redisdb", "app", "service", readyReplicas)
+			redisClient := mocks.NewRedisClientInterface(t)
+			if tt.prepare != nil {
+				tt.prepare(secret, redisClient)
+			}
+			adminService := newTestAdministrationService(t, secret, deployment)
+			adminService.redisClient = redisClient
+
+			name, described, err := adminService.existingDatabase(context.Background(), "redisdb", tt.request, 0)
+			if tt.wantError != nil {
+				if err == nil || reflect.TypeOf(err) != reflect.TypeOf(tt.wantError) {
+					t.Errorf("existingDatabase() error = %v, want %T", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("existingDatabase() error = %v", err)
+			}
+			if name != "redisdb" || described == nil || len(described.ConnectionProperties) != 1 {
+				t.Fatalf("existingDatabase() = %s, %+v, want the connection properties of redisdb", name, described)
+			}
+			if password := described.ConnectionProperties[0]["password"]; password != "password" {
+				t.Errorf("existingDatabase() password = %v, want the password of the existing database", password)
+			}
+		})
+	}
+}
+
+func TestDatabaseNameReserved(t *testing.T) {
+	secret, _ := testDatabase("redisdb", "app", "service", 0)
+	adminService := newTestAdministrationService(t, secret)
+	for name, want := range map[string]bool{"redisdb": true, "other": false} {
+		got, err := adminService.databaseNameReserved(context.Background(), name)
+		if err != nil || got != want {
+			t.Errorf("databaseNameReserved(%s) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+}
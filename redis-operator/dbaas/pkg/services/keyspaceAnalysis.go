@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/helper"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis"
+	v12 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	AnalysisPending   = "Pending"
+	AnalysisRunning   = "Running"
+	AnalysisCompleted = "Completed"
+	AnalysisFailed    = "Failed"
+
+	analysisConfigMapSuffix = "-keyspace-analysis"
+	analysisConfigMapKey    = "analysis.json"
+	// analyses are run one by one, the queue of the background executor is limited
+	maxPendingAnalyses = 5
+)
+
+// KeyspaceAnalysisRequest are the options of the key space analysis, zero values are replaced with defaults.
+type KeyspaceAnalysisRequest struct {
+	// Match is the SCAN pattern of the analyzed keys
+	Match string `json:"match,omitempty"`
+	// TopN is the number of the largest keys and prefixes in the result
+	TopN int `json:"topN,omitempty"`
+	// MaxKeys limits the number of the analyzed keys, the result is partial if the database has more keys
+	MaxKeys int64 `json:"maxKeys,omitempty"`
+	// ScanCount is the COUNT hint of every SCAN call
+	ScanCount int64 `json:"scanCount,omitempty"`
+	// ScanDelayMs is the pause between SCAN batches which limits the load on the database
+	ScanDelayMs int `json:"scanDelayMs,omitempty"`
+	// MemorySamples is the number of elements sampled by MEMORY USAGE for nested values
+	MemorySamples int `json:"memorySamples,omitempty"`
+	// PrefixSeparator and PrefixDepth define the key prefix, e.g. "user:42:cart" has prefix "user" with depth 1
+	PrefixSeparator string `json:"prefixSeparator,omitempty"`
+	PrefixDepth     int    `json:"prefixDepth,omitempty"`
+	// StoreConfigMap stores the result in the <db>-keyspace-analysis ConfigMap
+	StoreConfigMap bool `json:"storeConfigMap,omitempty"`
+}
+
+func (r *KeyspaceAnalysisRequest) setDefaults() error {
+	if r.Match == "" {
+		r.Match = "*"
+	}
+	if r.TopN == 0 {
+		r.TopN = 20
+	}
+	if r.MaxKeys == 0 {
+		r.MaxKeys = 1000000
+	}
+	if r.ScanCount == 0 {
+		r.ScanCount = 100
+	}
+	if r.ScanDelayMs == 0 {
+		r.ScanDelayMs = 10
+	}
+	if r.MemorySamples == 0 {
+		r.MemorySamples = 5
+	}
+	if r.PrefixSeparator == "" {
+		r.PrefixSeparator = ":"
+	}
+	if r.PrefixDepth == 0 {
+		r.PrefixDepth = 1
+	}
+	if r.TopN < 0 || r.TopN > 1000 || r.MaxKeys < 0 || r.ScanCount < 0 || r.ScanCount > 10000 ||
+		r.ScanDelayMs < 0 || r.MemorySamples < 0 || r.PrefixDepth < 0 {
+		return customEntity.NewInvalidArgumentError("topN must be up to 1000, scanCount up to 10000, other limits must not be negative")
+	}
+	return nil
+}
+
+// KeyspaceAnalysis is the state and the result of the key space analysis of a logical database.
+type KeyspaceAnalysis struct {
+	Database   string                  `json:"database"`
+	Status     string                  `json:"status"`
+	Error      string                  `json:"error,omitempty"`
+	Request    KeyspaceAnalysisRequest `json:"request"`
+	StartedAt  *metav1.Time            `json:"startedAt,omitempty"`
+	FinishedAt *metav1.Time            `json:"finishedAt,omitempty"`
+	ConfigMap  string                  `json:"configMap,omitempty"`
+	Result     *KeyspaceAnalysisResult `json:"result,omitempty"`
+}
+
+type KeyspaceAnalysisResult struct {
+	AnalyzedKeys int64 `json:"analyzedKeys"`
+	// Complete is false if the analysis was stopped by maxKeys
+	Complete         bool                `json:"complete"`
+	TotalMemoryBytes int64               `json:"totalMemoryBytes"`
+	TopKeys          []redis.KeyStat     `json:"topKeys"`
+	Prefixes         []KeyGroupStats     `json:"prefixes"`
+	Types            []KeyGroupStats     `json:"types"`
+	TTL              KeyspaceTTLCoverage `json:"ttl"`
+}
+
+// KeyGroupStats is the number and the memory of the keys with the same prefix or type.
+type KeyGroupStats struct {
+	Name        string `json:"name"`
+	Keys        int64  `json:"keys"`
+	MemoryBytes int64  `json:"memoryBytes"`
+}
+
+type KeyspaceTTLCoverage struct {
+	KeysWithTTL      int64   `json:"keysWithTTL"`
+	KeysWithoutTTL   int64   `json:"keysWithoutTTL"`
+	MemoryWithoutTTL int64   `json:"memoryWithoutTTLBytes"`
+	Coverage         float64 `json:"coverage"`
+}
+
+// keyspaceAnalyses keeps the last analysis of every logical database.
+type keyspaceAnalyses struct {
+	executor *helper.BackgroundExecutor
+	mutex    *sync.Mutex
+	byDB     map[string]*KeyspaceAnalysis
+}
+
+func newKeyspaceAnalyses() *keyspaceAnalyses {
+	return &keyspaceAnalyses{
+		executor: helper.NewBackgroundExecutor(),
+		mutex:    &sync.Mutex{},
+		byDB:     map[string]*KeyspaceAnalysis{},
+	}
+}
+
+// StartKeyspaceAnalysis submits the key space analysis of the logical database. Analyses are run one by one
+// in the background, the state is returned by GetKeyspaceAnalysis.
+// A ResourceAlreadyExistsError is returned if the analysis of the database is already pending or running.
+func (adminService *AdministrationService) StartKeyspaceAnalysis(ctx context.Context, dbName string, request KeyspaceAnalysisRequest) (*KeyspaceAnalysis, error) {
+	if err := request.setDefaults(); err != nil {
+		return nil, err
+	}
+	deployment, err := adminService.getRedisDeployment(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis, err := adminService.registerKeyspaceAnalysis(dbName, request)
+	if err != nil {
+		return nil, err
+	}
+	copied := *analysis
+	//submitted out of the lock, the running analysis updates its state under it
+	adminService.analyses.executor.Submit(func() {
+		adminService.runKeyspaceAnalysis(ctx, deployment, analysis)
+	})
+	return &copied, nil
+}
+
+func (adminService *AdministrationService) registerKeyspaceAnalysis(dbName string, request KeyspaceAnalysisRequest) (*KeyspaceAnalysis, error) {
+	analyses := adminService.analyses
+	analyses.mutex.Lock()
+	defer analyses.mutex.Unlock()
+
+	pending := 0
+	for _, analysis := range analyses.byDB {
+		if analysis.Status == AnalysisPending || analysis.Status == AnalysisRunning {
+			if analysis.Database == dbName {
+				return nil, customEntity.NewResourceAlreadyExistsError(fmt.Sprintf("Key space analysis of %s is already %s", dbName, strings.ToLower(analysis.Status)))
+			}
+			pending++
+		}
+	}
+	if pending >= maxPendingAnalyses {
+		return nil, customEntity.NewTooManyRequestsError(fmt.Sprintf("%d key space analyses are already in progress", pending))
+	}
+
+	analysis := &KeyspaceAnalysis{Database: dbName, Status: AnalysisPending, Request: request}
+	analyses.byDB[dbName] = analysis
+	return analysis, nil
+}
+
+// GetKeyspaceAnalysis returns the last key space analysis of the logical database, or nil if it was not run.
+func (adminService *AdministrationService) GetKeyspaceAnalysis(dbName string) *KeyspaceAnalysis {
+	analyses := adminService.analyses
+	analyses.mutex.Lock()
+	defer analyses.mutex.Unlock()
+
+	analysis, ok := analyses.byDB[dbName]
+	if !ok {
+		return nil
+	}
+	copied := *analysis
+	return &copied
+}
+
+func (adminService *AdministrationService) updateAnalysis(update func()) {
+	adminService.analyses.mutex.Lock()
+	defer adminService.analyses.mutex.Unlock()
+	update()
+}
+
+func (adminService *AdministrationService) runKeyspaceAnalysis(ctx context.Context, deployment *v12.Deployment, analysis *KeyspaceAnalysis) {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	dbName := deployment.Name
+	adminService.updateAnalysis(func() {
+		now := metav1.Now()
+		analysis.Status = AnalysisRunning
+		analysis.StartedAt = &now
+	})
+	logger.Info(fmt.Sprintf("Key space analysis of %s is started with %+v", dbName, analysis.Request))
+
+	var result *KeyspaceAnalysisResult
+	var err error
+	defer func() {
+		//an unexpected panic fails the analysis instead of the adapter
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+		now := metav1.Now()
+		configMap := ""
+		//the result is stored before the analysis is completed, so a completed analysis is always stored
+		if err == nil && analysis.Request.StoreConfigMap {
+			stored := adminService.GetKeyspaceAnalysis(dbName)
+			stored.Status = AnalysisCompleted
+			stored.FinishedAt = &now
+			stored.Result = result
+			stored.ConfigMap = dbName + analysisConfigMapSuffix
+			if err = adminService.storeKeyspaceAnalysis(ctx, deployment, stored); err != nil {
+				err = fmt.Errorf("failed to store the result in %s config map: %v", stored.ConfigMap, err)
+			} else {
+				configMap = stored.ConfigMap
+			}
+		}
+		adminService.updateAnalysis(func() {
+			analysis.FinishedAt = &now
+			analysis.Result = result
+			analysis.ConfigMap = configMap
+			analysis.Status = AnalysisCompleted
+			if err != nil {
+				analysis.Status = AnalysisFailed
+				analysis.Error = err.Error()
+			}
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("Key space analysis of %s is failed: %v", dbName, err))
+			return
+		}
+		logger.Info(fmt.Sprintf("Key space analysis of %s is completed, %d keys are analyzed", dbName, result.AnalyzedKeys))
+	}()
+
+	result, err = adminService.analyzeKeyspace(ctx, dbName, analysis.Request)
+}
+
+// analyzeKeyspace scans the keys in batches with a pause between them and reads their type, memory usage and TTL.
+// SCAN can return a key more than once, so the result is an estimation.
+func (adminService *AdministrationService) analyzeKeyspace(ctx context.Context, dbName string, request KeyspaceAnalysisRequest) (*KeyspaceAnalysisResult, error) {
+	redisdb, err := adminService.connectDatabase(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	defer redisdb.Close()
+
+	result := &KeyspaceAnalysisResult{Complete: true}
+	prefixes := map[string]*KeyGroupStats{}
+	keyTypes := map[string]*KeyGroupStats{}
+	var cursor uint64
+	for {
+		keys, next, err := redisdb.Scan(cursor, request.Match, request.ScanCount)
+		if err != nil {
+			return nil, err
+		}
+		if remaining := request.MaxKeys - result.AnalyzedKeys; int64(len(keys)) > remaining {
+			keys = keys[:remaining]
+			result.Complete = false
+		}
+		stats, err := redisdb.KeyStats(keys, request.MemorySamples)
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range stats {
+			result.AnalyzedKeys++
+			result.TotalMemoryBytes += stat.MemoryBytes
+			addToGroup(prefixes, keyPrefix(stat.Key, request.PrefixSeparator, request.PrefixDepth), stat)
+			addToGroup(keyTypes, stat.Type, stat)
+			if stat.TTLSeconds >= 0 {
+				result.TTL.KeysWithTTL++
+			} else {
+				result.TTL.KeysWithoutTTL++
+				result.TTL.MemoryWithoutTTL += stat.MemoryBytes
+			}
+		}
+		result.TopKeys = topKeys(append(result.TopKeys, stats...), request.TopN)
+
+		if next == 0 {
+			break
+		}
+		if result.AnalyzedKeys >= request.MaxKeys {
+			result.Complete = false
+			break
+		}
+		cursor = next
+		time.Sleep(time.Duration(request.ScanDelayMs) * time.Millisecond)
+	}
+
+	if result.AnalyzedKeys > 0 {
+		result.TTL.Coverage = float64(result.TTL.KeysWithTTL) / float64(result.AnalyzedKeys)
+	}
+	result.Prefixes = sortedGroups(prefixes, request.TopN)
+	result.Types = sortedGroups(keyTypes, len(keyTypes))
+	return result, nil
+}
+
+// storeKeyspaceAnalysis saves the analysis to its ConfigMap, it is owned by the database Deployment
+// and is removed together with the database.
+func (adminService *AdministrationService) storeKeyspaceAnalysis(ctx context.Context, deployment *v12.Deployment, analysis *KeyspaceAnalysis) error {
+	data, err := json.Marshal(analysis)
+	if err != nil {
+		return err
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      analysis.ConfigMap,
+			Namespace: adminService.namespace,
+			Labels: map[string]string{
+				constants.Name: deployment.Name,
+			},
+		},
+		Data: map[string]string{
+			analysisConfigMapKey: string(data),
+		},
+	}
+	if err = controllerutil.SetOwnerReference(deployment, configMap, adminService.runtimeScheme); err != nil {
+		return err
+	}
+	return core.CreateOrUpdateRuntimeObject(adminService.kubeClient, nil, nil, configMap, configMap.ObjectMeta, true)
+}
+
+// keyPrefix returns the first depth parts of the key, keys with fewer parts are grouped as <none>.
+func keyPrefix(key string, separator string, depth int) string {
+	parts := strings.SplitN(key, separator, depth+1)
+	if len(parts) <= depth {
+		return "<none>"
+	}
+	return strings.Join(parts[:depth], separator)
+}
+
+func addToGroup(groups map[string]*KeyGroupStats, name string, stat redis.KeyStat) {
+	group, ok := groups[name]
+	if !ok {
+		group = &KeyGroupStats{Name: name}
+		groups[name] = group
+	}
+	group.Keys++
+	group.MemoryBytes += stat.MemoryBytes
+}
+
+func sortedGroups(groups map[string]*KeyGroupStats, limit int) []KeyGroupStats {
+	result := make([]KeyGroupStats, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MemoryBytes == result[j].MemoryBytes {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].MemoryBytes > result[j].MemoryBytes
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func topKeys(keys []redis.KeyStat, limit int) []redis.KeyStat {
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].MemoryBytes > keys[j].MemoryBytes
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis/mocks"
+	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var (
+	firstBatch = []redis.KeyStat{
+		{Key: "user:1:cart", Type: "hash", MemoryBytes: 300, TTLSeconds: 60},
+		{Key: "user:2:cart", Type: "hash", MemoryBytes: 200, TTLSeconds: -1},
+		{Key: "session:a", Type: "string", MemoryBytes: 50, TTLSeconds: 3600},
+	}
+	secondBatch = []redis.KeyStat{
+		{Key: "counter", Type: "string", MemoryBytes: 450, TTLSeconds: -1},
+	}
+)
+
+// keyspaceRedisClient scans the keys of both batches with the cursor 7 between them.
+func keyspaceRedisClient(t *testing.T) *mocks.RedisClientInterface {
+	redisClient := mocks.NewRedisClientInterface(t)
+	redisClient.On("InitRedisClient", mock.Anything, "password", mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+	redisClient.On("Scan", uint64(0), "*", int64(100)).Return(keys(firstBatch), uint64(7), nil).Maybe()
+	redisClient.On("Scan", uint64(7), "*", int64(100)).Return(keys(secondBatch), uint64(0), nil).Maybe()
+	redisClient.On("Close").Return(nil)
+	return redisClient
+}
+
+func keys(stats []redis.KeyStat) []string {
+	var result []string
+	for _, stat := range stats {
+		result = append(result, stat.Key)
+	}
+	return result
+}
+
+func keyspaceAnalysisRequest(t *testing.T, request KeyspaceAnalysisRequest) KeyspaceAnalysisRequest {
+	request.ScanDelayMs = 1
+	if err := request.setDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func TestAnalyzeKeyspace(t *testing.T) {
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	adminService := newTestAdministrationService(t, secret, deployment)
+	redisClient := keyspaceRedisClient(t)
+	redisClient.On("KeyStats", keys(firstBatch), 5).Return(firstBatch, nil)
+	redisClient.On("KeyStats", keys(secondBatch), 5).Return(secondBatch, nil)
+	adminService.redisClient = redisClient
+
+	result, err := adminService.analyzeKeyspace(context.Background(), "redisdb", keyspaceAnalysisRequest(t, KeyspaceAnalysisRequest{TopN: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &KeyspaceAnalysisResult{
+		AnalyzedKeys:     4,
+		Complete:         true,
+		TotalMemoryBytes: 1000,
+		TopKeys:          []redis.KeyStat{secondBatch[0], firstBatch[0]},
+		Prefixes:         []KeyGroupStats{{Name: "user", Keys: 2, MemoryBytes: 500}, {Name: "<none>", Keys: 1, MemoryBytes: 450}},
+		Types:            []KeyGroupStats{{Name: "hash", Keys: 2, MemoryBytes: 500}, {Name: "string", Keys: 2, MemoryBytes: 500}},
+		TTL:              KeyspaceTTLCoverage{KeysWithTTL: 2, KeysWithoutTTL: 2, MemoryWithoutTTL: 650, Coverage: 0.5},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("analyzeKeyspace() = %+v, want %+v", result, want)
+	}
+}
+
+func TestAnalyzeKeyspacePrefixDepth(t *testing.T) {
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	adminService := newTestAdministrationService(t, secret, deployment)
+	redisClient := keyspaceRedisClient(t)
+	redisClient.On("KeyStats", keys(firstBatch), 5).Return(firstBatch, nil)
+	redisClient.On("KeyStats", keys(secondBatch), 5).Return(secondBatch, nil)
+	adminService.redisClient = redisClient
+
+	result, err := adminService.analyzeKeyspace(context.Background(), "redisdb", keyspaceAnalysisRequest(t, KeyspaceAnalysisRequest{PrefixDepth: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []KeyGroupStats{
+		{Name: "<none>", Keys: 2, MemoryBytes: 500},
+		{Name: "user:1", Keys: 1, MemoryBytes: 300},
+		{Name: "user:2", Keys: 1, MemoryBytes: 200},
+	}
+	if !reflect.DeepEqual(result.Prefixes, want) {
+		t.Errorf("analyzeKeyspace() prefixes = %+v, want %+v", result.Prefixes, want)
+	}
+}
+
+func TestAnalyzeKeyspaceMaxKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxKeys int64
+		batches [][]redis.KeyStat
+	}{
+		{name: "Cut in the batch", maxKeys: 2, batches: [][]redis.KeyStat{firstBatch[:2]}},
+		{name: "Cut after the batch", maxKeys: 3, batches: [][]redis.KeyStat{firstBatch}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, deployment := testDatabase("redisdb", "app", "service", 1)
+			adminService := newTestAdministrationService(t, secret, deployment)
+			redisClient := keyspaceRedisClient(t)
+			for _, batch := range tt.batches {
+				redisClient.On("KeyStats", keys(batch), 5).Return(batch, nil).Once()
+			}
+			adminService.redisClient = redisClient
+
+			result, err := adminService.analyzeKeyspace(context.Background(), "redisdb",
+				keyspaceAnalysisRequest(t, KeyspaceAnalysisRequest{MaxKeys: tt.maxKeys}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.AnalyzedKeys != tt.maxKeys || result.Complete {
+				t.Errorf("analyzeKeyspace() analyzed %d keys, complete %t, want %d keys not complete", result.AnalyzedKeys, result.Complete, tt.maxKeys)
+			}
+			redisClient.AssertNotCalled(t, "Scan", uint64(7), "*", int64(100))
+		})
+	}
+}
+
+func TestRegisterKeyspaceAnalysis(t *testing.T) {
+	adminService := newTestAdministrationService(t)
+	for _, dbName := range []string{"db1", "db2", "db3", "db4", "db5"} {
+		if _, err := adminService.registerKeyspaceAnalysis(dbName, KeyspaceAnalysisRequest{}); err != nil {
+			t.Fatalf("registerKeyspaceAnalysis(%s) error = %v", dbName, err)
+		}
+	}
+
+	_, err := adminService.registerKeyspaceAnalysis("db1", KeyspaceAnalysisRequest{})
+	var alreadyExists *customEntity.ResourceAlreadyExistsError
+	if !errors.As(err, &alreadyExists) {
+		t.Errorf("registerKeyspaceAnalysis() of the pending database error = %v, want ResourceAlreadyExistsError", err)
+	}
+
+	_, err = adminService.registerKeyspaceAnalysis("db6", KeyspaceAnalysisRequest{})
+	var tooManyRequests *customEntity.TooManyRequestsError
+	if !errors.As(err, &tooManyRequests) {
+		t.Errorf("registerKeyspaceAnalysis() over the limit error = %v, want TooManyRequestsError", err)
+	}
+
+	adminService.updateAnalysis(func() {
+		adminService.analyses.byDB["db1"].Status = AnalysisCompleted
+	})
+	if _, err = adminService.registerKeyspaceAnalysis("db1", KeyspaceAnalysisRequest{}); err != nil {
+		t.Errorf("registerKeyspaceAnalysis() of the completed database error = %v", err)
+	}
+}
+
+func TestRunKeyspaceAnalysisStoresResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		storeError error
+		wantStatus string
+	}{
+		{name: "Stored", wantStatus: AnalysisCompleted},
+		{name: "Store failed", storeError: errors.New("forbidden"), wantStatus: AnalysisFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, deployment := testDatabase("redisdb", "app", "service", 1)
+			adminService := newTestAdministrationService(t)
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			failConfigMap := func(obj client.Object) error {
+				if _, ok := obj.(*v1.ConfigMap); ok {
+					return tt.storeError
+				}
+				return nil
+			}
+			adminService.kubeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, deployment).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if err := failConfigMap(obj); err != nil {
+							return err
+						}
+						return client.Create(ctx, obj, opts...)
+					},
+					Update: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						if err := failConfigMap(obj); err != nil {
+							return err
+						}
+						return client.Update(ctx, obj, opts...)
+					},
+				}).Build()
+			redisClient := keyspaceRedisClient(t)
+			redisClient.On("KeyStats", mock.Anything, 5).Return(nil, nil)
+			adminService.redisClient = redisClient
+
+			analysis, err := adminService.registerKeyspaceAnalysis("redisdb", keyspaceAnalysisRequest(t, KeyspaceAnalysisRequest{StoreConfigMap: true}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			adminService.runKeyspaceAnalysis(context.Background(), deployment, analysis)
+
+			got := adminService.GetKeyspaceAnalysis("redisdb")
+			if got.Status != tt.wantStatus || got.Result == nil {
+				t.Fatalf("analysis = %+v, want %s with the result", got, tt.wantStatus)
+			}
+			configMap := &v1.ConfigMap{}
+			err = adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: "redisdb" + analysisConfigMapSuffix, Namespace: testNamespace}, configMap)
+			if tt.storeError != nil {
+				if got.ConfigMap != "" || err == nil {
+					t.Errorf("analysis refers to config map %q, stored: %t, want no config map", got.ConfigMap, err == nil)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored := &KeyspaceAnalysis{}
+			if err = json.Unmarshal([]byte(configMap.Data[analysisConfigMapKey]), stored); err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != AnalysisCompleted || stored.Result == nil || got.ConfigMap != configMap.Name {
+				t.Errorf("stored analysis = %+v, analysis config map %q, want completed with the result in %s", stored, got.ConfigMap, configMap.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DefaultMaxmemOverhead is the part of the memory limit left for the replication and client buffers,
+// the fork on persistence and the fragmentation when maxmemory is derived from the limit
+const DefaultMaxmemOverhead = "25%"
+
+// RedisMaxmemory returns maxmemory of Redis running with the resources. Maxmem takes precedence, otherwise
+// maxmemory is the memory limit minus the overhead. Empty maxmemory is returned when there is no memory limit,
+// the value of the default config is kept then.
+func RedisMaxmemory(maxmem, overhead string, resources v1.ResourceRequirements) (string, error) {
+	limit, limited := resources.Limits[v1.ResourceMemory]
+	limited = limited && !limit.IsZero()
+	if maxmem != "" {
+		if limited {
+			return maxmem, CheckMaxmemory(maxmem, limit)
+		}
+		_, err := config.ParseMemory(maxmem)
+		return maxmem, err
+	}
+	if !limited {
+		return "", nil
+	}
+	return MaxmemoryFromLimit(limit, overhead)
+}
+
+// MaxmemoryFromLimit returns the memory limit minus the overhead, the overhead is a percentage of the limit
+// like 25% or a quantity like 64Mi.
+func MaxmemoryFromLimit(limit resource.Quantity, overhead string) (string, error) {
+	if overhead == "" {
+		overhead = DefaultMaxmemOverhead
+	}
+	limitBytes := limit.Value()
+	var overheadBytes int64
+	if percentage, isPercentage := strings.CutSuffix(overhead, "%"); isPercentage {
+		percent, err := strconv.ParseFloat(percentage, 64)
+		if err != nil || percent < 0 || percent >= 100 {
+			return "", fmt.Errorf("maxmemory overhead must be a percentage from 0%% to 100%%, got '%s'", overhead)
+		}
+		overheadBytes = int64(float64(limitBytes) * percent / 100)
+	} else {
+		quantity, err := resource.ParseQuantity(overhead)
+		if err != nil {
+			return "", fmt.Errorf("maxmemory overhead must be a percentage like 25%% or a quantity like 64Mi, got '%s'", overhead)
+		}
+		overheadBytes = quantity.Value()
+	}
+	if overheadBytes >= limitBytes {
+		return "", fmt.Errorf("maxmemory overhead %s leaves nothing of the memory limit %s", overhead, limit.String())
+	}
+	return config.FormatMemory(limitBytes - overheadBytes), nil
+}
+
+// CheckMaxmemory returns an error if maxmemory exceeds the memory limit. Zero maxmemory is not limited by Redis,
+// so it exceeds the limit too.
+func CheckMaxmemory(maxmemory string, limit resource.Quantity) error {
+	bytes, err := config.ParseMemory(maxmemory)
+	if err != nil {
+		return err
+	}
+	if bytes == 0 || bytes > limit.Value() {
+		return fmt.Errorf("maxmemory %s exceeds the memory limit %s", maxmemory, limit.String())
+	}
+	return nil
+}
+
+// databaseMaxmemory returns maxmemory derived for the logical database with the resources when its settings
+// don't set maxmemory. Maxmem of the Redis spec is used by the databases with the memory limit of the Redis spec.
+func (adminService *AdministrationService) databaseMaxmemory(resources v1.ResourceRequirements) (string, error) {
+	maxmem := ""
+	limit := resources.Limits[v1.ResourceMemory]
+	if defaultLimit, ok := adminService.redisResources.Limits[v1.ResourceMemory]; ok && limit.Cmp(defaultLimit) == 0 {
+		maxmem = adminService.maxmem
+	}
+	return RedisMaxmemory(maxmem, adminService.maxmemOverhead, resources)
+}
+
+// checkDatabaseMaxmemory returns an error if maxmemory of the settings exceeds the memory limit of the database.
+func checkDatabaseMaxmemory(settings map[string]interface{}, resources v1.ResourceRequirements) error {
+	value, ok := settings["maxmemory"]
+	limit, limited := resources.Limits[v1.ResourceMemory]
+	if !ok || !limited || limit.IsZero() {
+		return nil
+	}
+	maxmemory, isString := value.(string)
+	if !isString {
+		maxmemory = fmt.Sprint(value)
+	}
+	return CheckMaxmemory(maxmemory, limit)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/config"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func memoryLimit(limit string) v1.ResourceRequirements {
+	return v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse(limit)}}
+}
+
+func TestRedisMaxmemory(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxmem    string
+		overhead  string
+		resources v1.ResourceRequirements
+		want      string
+		wantErr   bool
+	}{
+		{name: "No memory limit", want: ""},
+		{name: "Maxmem without memory limit", maxmem: "100mb", want: "100mb"},
+		{name: "Invalid maxmem", maxmem: "100 megabytes", wantErr: true},
+		{name: "Maxmem within the limit", maxmem: "256mb", resources: memoryLimit("256Mi"), want: "256mb"},
+		{name: "Maxmem above the limit", maxmem: "300mb", resources: memoryLimit("256Mi"), wantErr: true},
+		{name: "Unlimited maxmem with the limit", maxmem: "0", resources: memoryLimit("256Mi"), wantErr: true},
+		{name: "Default overhead", resources: memoryLimit("256Mi"), want: "192mb"},
+		{name: "Percentage overhead", overhead: "50%", resources: memoryLimit("256Mi"), want: "128mb"},
+		{name: "Quantity overhead", overhead: "64Mi", resources: memoryLimit("256Mi"), want: "192mb"},
+		{name: "Overhead of the whole limit", overhead: "256Mi", resources: memoryLimit("256Mi"), wantErr: true},
+		{name: "Percentage overhead out of range", overhead: "100%", resources: memoryLimit("256Mi"), wantErr: true},
+		{name: "Invalid overhead", overhead: "a lot", resources: memoryLimit("256Mi"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RedisMaxmemory(tt.maxmem, tt.overhead, tt.resources)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RedisMaxmemory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("RedisMaxmemory() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDatabaseMaxmemory(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  map[string]interface{}
+		resources v1.ResourceRequirements
+		wantErr   bool
+	}{
+		{name: "No maxmemory", settings: map[string]interface{}{}, resources: memoryLimit("256Mi")},
+		{name: "No memory limit", settings: map[string]interface{}{"maxmemory": "1gb"}},
+		{name: "Within the limit", settings: map[string]interface{}{"maxmemory": "200mb"}, resources: memoryLimit("256Mi")},
+		{name: "Equal to the limit", settings: map[string]interface{}{"maxmemory": "256mb"}, resources: memoryLimit("256Mi")},
+		{name: "Above the limit", settings: map[string]interface{}{"maxmemory": "257mb"}, resources: memoryLimit("256Mi"), wantErr: true},
+		{name: "Number above the limit", settings: map[string]interface{}{"maxmemory": 300000000}, resources: memoryLimit("256Mi"), wantErr: true},
+		{name: "Unlimited", settings: map[string]interface{}{"maxmemory": "0"}, resources: memoryLimit("256Mi"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkDatabaseMaxmemory(tt.settings, tt.resources); (err != nil) != tt.wantErr {
+				t.Errorf("checkDatabaseMaxmemory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetRedisDatabaseSettingsMaxmemory(t *testing.T) {
+	defaultConfig, err := config.Parse("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		settings      map[string]interface{}
+		want          string
+		wantInvalid   bool
+		defaultMaxmem string
+	}{
+		{name: "Derived from the limit", settings: map[string]interface{}{}, want: "192mb"},
+		{name: "Maxmem of the Redis spec", settings: map[string]interface{}{}, defaultMaxmem: "240mb", want: "240mb"},
+		{name: "Requested maxmemory", settings: map[string]interface{}{"maxmemory": "100mb"}, want: "100mb"},
+		{name: "Requested maxmemory above the limit", settings: map[string]interface{}{"maxmemory": "1gb"}, wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := newTestAdministrationService(t)
+			adminService.redisResources = memoryLimit("256Mi")
+			adminService.maxmem = tt.defaultMaxmem
+
+			redisConfig, err := adminService.setRedisDatabaseSettings(context.Background(), defaultConfig, tt.settings, memoryLimit("256Mi"))
+			if _, invalid := err.(*customEntity.InvalidArgumentError); invalid != tt.wantInvalid {
+				t.Fatalf("setRedisDatabaseSettings() error = %v, want InvalidArgumentError %t", err, tt.wantInvalid)
+			}
+			if tt.wantInvalid {
+				return
+			}
+			if got := redisConfig.Get("maxmemory"); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("setRedisDatabaseSettings() maxmemory = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
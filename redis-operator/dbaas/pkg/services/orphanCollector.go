@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	cm "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultOrphanSweepInterval = 10 * time.Minute
+	defaultOrphanRetention     = 24 * time.Hour
+
+	OrphanReasonNoDeployment  = "NoDeployment"
+	OrphanReasonNoCredentials = "NoCredentials"
+)
+
+var (
+	orphanedResourcesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "redis_dbaas_orphaned_resources",
+		Help: "Objects of logical databases left without their Deployment or credentials Secret",
+	}, []string{"kind", "reason"})
+	orphanedResourcesDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_dbaas_orphaned_resources_deleted_total",
+		Help: "Orphaned objects of logical databases deleted after the retention period",
+	}, []string{"kind"})
+)
+
+func init() {
+	// the adapter serves /metrics from the default registry
+	prometheus.MustRegister(orphanedResourcesGauge, orphanedResourcesDeleted)
+}
+
+// OrphanCollector periodically finds the objects of logical databases which are left by a creation interrupted
+// by the operator restart or by a partially failed drop. The objects are reported in the CR status and metrics
+// and deleted when they are still orphaned after the retention period.
+type OrphanCollector struct {
+	adminService *AdministrationService
+	interval     time.Duration
+	retention    time.Duration
+	dryRun       bool
+	logger       *zap.Logger
+
+	mutex sync.Mutex
+	// the time when the object was found orphaned first, it is kept in memory so the retention starts over after restart
+	firstSeen map[string]time.Time
+	found     []v2.OrphanResource
+}
+
+type orphanObject struct {
+	object   client.Object
+	kind     string
+	database string
+	reason   string
+}
+
+// NewOrphanCollector returns nil if the collector is not enabled in the CR.
+func NewOrphanCollector(adminService *AdministrationService, spec *v2.OrphanCollector, logger *zap.Logger) (*OrphanCollector, error) {
+	if spec == nil || !spec.Enabled {
+		return nil, nil
+	}
+	interval, err := parseDurationOrDefault(spec.Interval, defaultOrphanSweepInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid orphan collector interval: %v", err)
+	}
+	retention, err := parseDurationOrDefault(spec.Retention, defaultOrphanRetention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid orphan collector retention: %v", err)
+	}
+	return &OrphanCollector{
+		adminService: adminService,
+		interval:     interval,
+		retention:    retention,
+		dryRun:       spec.DryRun,
+		logger:       logger,
+		firstSeen:    map[string]time.Time{},
+	}, nil
+}
+
+func parseDurationOrDefault(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Run starts the sweeps which live until the adapter server context is done.
+func (c *OrphanCollector) Run(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			c.sweep(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Status returns the orphaned objects found by the last sweep.
+func (c *OrphanCollector) Status() *v2.OrphansStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return &v2.OrphansStatus{
+		Resources: append([]v2.OrphanResource{}, c.found...),
+		DryRun:    c.dryRun,
+	}
+}
+
+func (c *OrphanCollector) sweep(ctx context.Context) {
+	orphans, err := c.findOrphans(ctx)
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("Failed to find orphaned objects of logical databases, err: %v", err))
+		return
+	}
+
+	now := time.Now()
+	firstSeen := map[string]time.Time{}
+	var found []v2.OrphanResource
+	counts := map[[2]string]float64{}
+	for _, orphan := range orphans {
+		key := orphan.kind + "/" + orphan.object.GetName()
+		since, ok := c.firstSeen[key]
+		if !ok {
+			since = now
+			c.logger.Warn(fmt.Sprintf("%s %s of logical database %s is orphaned: %s", orphan.kind, orphan.object.GetName(), orphan.database, orphan.reason))
+		}
+		if !c.dryRun && now.Sub(since) >= c.retention {
+			if c.deleteOrphan(ctx, orphan) {
+				continue
+			}
+		}
+		firstSeen[key] = since
+		found = append(found, v2.OrphanResource{
+			Kind:     orphan.kind,
+			Name:     orphan.object.GetName(),
+			Database: orphan.database,
+			Reason:   orphan.reason,
+			Since:    metav1.NewTime(since),
+		})
+		counts[[2]string{orphan.kind, orphan.reason}]++
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Kind != found[j].Kind {
+			return found[i].Kind < found[j].Kind
+		}
+		return found[i].Name < found[j].Name
+	})
+
+	orphanedResourcesGauge.Reset()
+	for labels, count := range counts {
+		orphanedResourcesGauge.WithLabelValues(labels[0], labels[1]).Set(count)
+	}
+
+	c.mutex.Lock()
+	changed := !reflect.DeepEqual(found, c.found)
+	c.firstSeen = firstSeen
+	c.found = found
+	c.mutex.Unlock()
+	if changed {
+		c.adminService.notifyInventoryChanged()
+	}
+}
+
+// deleteOrphan deletes the object if it is still orphaned, the database could be created again with the same name.
+func (c *OrphanCollector) deleteOrphan(ctx context.Context, orphan orphanObject) bool {
+	still, err := c.stillOrphaned(ctx, orphan)
+	if err != nil || !still {
+		return false
+	}
+	if err = core.DeleteRuntimeObject(c.adminService.kubeClient, orphan.object); err != nil {
+		c.logger.Warn(fmt.Sprintf("Failed to delete orphaned %s %s, err: %v", orphan.kind, orphan.object.GetName(), err))
+		return false
+	}
+	c.logger.Info(fmt.Sprintf("Orphaned %s %s of logical database %s is deleted after %s", orphan.kind, orphan.object.GetName(), orphan.database, c.retention))
+	orphanedResourcesDeleted.WithLabelValues(orphan.kind).Inc()
+	return true
+}
+
+func (c *OrphanCollector) stillOrphaned(ctx context.Context, orphan orphanObject) (bool, error) {
+	var owner client.Object = &appsv1.Deployment{}
+	ownerName := orphan.database
+	if orphan.reason == OrphanReasonNoCredentials {
+		owner = &v1.Secret{}
+		ownerName = credsName(orphan.database)
+	}
+	err := c.adminService.kubeClient.Get(ctx, types.NamespacedName{Name: ownerName, Namespace: c.adminService.namespace}, owner)
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+// findOrphans returns the objects labeled with the logical database for which there is no Deployment
+// and the Deployments of logical databases without the credentials Secret. The objects younger than the database
+// creation wait are skipped, they can belong to a creation in progress which hasn't created the Deployment yet.
+func (c *OrphanCollector) findOrphans(ctx context.Context) ([]orphanObject, error) {
+	adminService := c.adminService
+	createdBefore := time.Now().Add(-time.Duration(adminService.defaultRedisDbStartWait) * time.Second)
+	young := func(object client.Object) bool {
+		return object.GetCreationTimestamp().Time.After(createdBefore)
+	}
+	deployments := &appsv1.DeploymentList{}
+	err := adminService.kubeClient.List(ctx, deployments, client.InNamespace(adminService.namespace),
+		client.MatchingLabels{adminService.redisLabel: adminService.redisLabel})
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []orphanObject
+	databases := map[string]bool{}
+	//the objects of the soft deleted databases are kept until their retention expires
+	deleted, err := adminService.listDeletedDeployments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deleted.Items {
+		databases[deployment.Name] = true
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		databases[deployment.Name] = true
+		if !usesCredentialsSecret(deployment) {
+			//not created by the adapter, e.g. the standalone Redis
+			continue
+		}
+		secret := &v1.Secret{}
+		err = adminService.kubeClient.Get(ctx, types.NamespacedName{Name: credsName(deployment.Name), Namespace: adminService.namespace}, secret)
+		if errors.IsNotFound(err) && !young(deployment) {
+			orphans = append(orphans, orphanObject{deployment, "Deployment", deployment.Name, OrphanReasonNoCredentials})
+		} else if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	lists := map[string]client.ObjectList{
+		"Secret":                &v1.SecretList{},
+		"ConfigMap":             &v1.ConfigMapList{},
+		"Service":               &v1.ServiceList{},
+		"PersistentVolumeClaim": &v1.PersistentVolumeClaimList{},
+	}
+	if adminService.tls.Enabled {
+		//the same as for the certificate creation
+		if err = cm.AddToScheme(adminService.runtimeScheme); err != nil {
+			return nil, err
+		}
+		lists["Certificate"] = &cm.CertificateList{}
+	}
+	for kind, list := range lists {
+		err = adminService.kubeClient.List(ctx, list, client.InNamespace(adminService.namespace), client.HasLabels{templates.LogicalDatabase})
+		if err != nil && kind == "Certificate" {
+			//certificates can be managed without the access of the operator
+			c.logger.Debug(fmt.Sprintf("Certificates are skipped, err: %v", err))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", kind, err)
+		}
+		for _, object := range listObjects(list) {
+			database := object.GetLabels()[templates.LogicalDatabase]
+			if !databases[database] && !young(object) {
+				orphans = append(orphans, orphanObject{object, kind, database, OrphanReasonNoDeployment})
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// usesCredentialsSecret is true if the Redis password of the deployment is read from the credentials secret of the logical database.
+func usesCredentialsSecret(deployment *appsv1.Deployment) bool {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == redisPasswordConst && env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil &&
+				env.ValueFrom.SecretKeyRef.Name == credsName(deployment.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func listObjects(list client.ObjectList) []client.Object {
+	var objects []client.Object
+	switch typed := list.(type) {
+	case *v1.SecretList:
+		for i := range typed.Items {
+			objects = append(objects, &typed.Items[i])
+		}
+	case *v1.ConfigMapList:
+		for i := range typed.Items {
+			objects = append(objects, &typed.Items[i])
+		}
+	case *v1.ServiceList:
+		for i := range typed.Items {
+			objects = append(objects, &typed.Items[i])
+		}
+	case *v1.PersistentVolumeClaimList:
+		for i := range typed.Items {
+			objects = append(objects, &typed.Items[i])
+		}
+	case *cm.CertificateList:
+		for i := range typed.Items {
+			objects = append(objects, &typed.Items[i])
+		}
+	}
+	return objects
+}
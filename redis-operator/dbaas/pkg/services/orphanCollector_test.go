@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestOrphanCollector(t *testing.T, adminService *AdministrationService, dryRun bool) *OrphanCollector {
+	t.Helper()
+	collector, err := NewOrphanCollector(adminService, &v2.OrphanCollector{Enabled: true, Retention: "1h", DryRun: dryRun}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return collector
+}
+
+// orphanedConfigMap returns the ConfigMap of the logical database which has no Deployment.
+func orphanedConfigMap(database string, created time.Time) *v1.ConfigMap {
+	return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:              database,
+		Namespace:         testNamespace,
+		Labels:            map[string]string{templates.LogicalDatabase: database},
+		CreationTimestamp: metav1.NewTime(created),
+	}}
+}
+
+func TestFindOrphans(t *testing.T) {
+	old := time.Now().Add(-time.Hour)
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	//a creation in progress has reserved the name, but hasn't created the Deployment yet
+	creatingSecret, _ := testDatabase("creating", "app", "service", 0)
+	creatingSecret.CreationTimestamp = metav1.NewTime(time.Now())
+	leftSecret, _ := testDatabase("left", "app", "service", 0)
+	leftSecret.CreationTimestamp = metav1.NewTime(old)
+	_, noCredentials := testDatabase("nocredentials", "app", "service", 1)
+	noCredentials.CreationTimestamp = metav1.NewTime(old)
+	standalone := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      "standalone",
+		Namespace: testNamespace,
+		Labels:    map[string]string{testLabel: testLabel},
+	}}
+
+	adminService := newTestAdministrationService(t, secret, deployment, creatingSecret, leftSecret, noCredentials, standalone,
+		orphanedConfigMap("left", old), orphanedConfigMap("creating", time.Now()))
+	adminService.defaultRedisDbStartWait = 60
+	collector := newTestOrphanCollector(t, adminService, true)
+
+	orphans, err := collector.findOrphans(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, orphan := range orphans {
+		got = append(got, orphan.kind+"/"+orphan.object.GetName()+"/"+orphan.database+"/"+orphan.reason)
+	}
+	sort.Strings(got)
+	want := []string{
+		"ConfigMap/left/left/" + OrphanReasonNoDeployment,
+		"Deployment/nocredentials/nocredentials/" + OrphanReasonNoCredentials,
+		"Secret/left-credentials/left/" + OrphanReasonNoDeployment,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findOrphans() = %v, want %v", got, want)
+	}
+}
+
+func TestOrphanSweep(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+		//how long ago the orphan was found first, it is negative if it is found by this sweep
+		seenBefore  time.Duration
+		wantDeleted bool
+	}{
+		{name: "Found first", seenBefore: -1},
+		{name: "Within retention", seenBefore: 30 * time.Minute},
+		{name: "After retention", seenBefore: 2 * time.Hour, wantDeleted: true},
+		{name: "After retention in dry run", dryRun: true, seenBefore: 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := newTestAdministrationService(t, orphanedConfigMap("left", time.Now().Add(-3*time.Hour)))
+			collector := newTestOrphanCollector(t, adminService, tt.dryRun)
+			if tt.seenBefore >= 0 {
+				collector.firstSeen["ConfigMap/left"] = time.Now().Add(-tt.seenBefore)
+			}
+
+			collector.sweep(context.Background())
+
+			err := adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: "left", Namespace: testNamespace}, &v1.ConfigMap{})
+			if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("sweep() deleted the orphan = %t, want %t, err: %v", deleted, tt.wantDeleted, err)
+			}
+			status := collector.Status()
+			if reported := len(status.Resources) == 1; reported == tt.wantDeleted {
+				t.Errorf("sweep() reported %v, want the orphan reported until it is deleted", status.Resources)
+			}
+			if status.DryRun != tt.dryRun {
+				t.Errorf("Status().DryRun = %t, want %t", status.DryRun, tt.dryRun)
+			}
+		})
+	}
+}
+
+func TestOrphanSweepKeepsRecreatedDatabase(t *testing.T) {
+	adminService := newTestAdministrationService(t, orphanedConfigMap("left", time.Now().Add(-3*time.Hour)))
+	collector := newTestOrphanCollector(t, adminService, false)
+	collector.firstSeen["ConfigMap/left"] = time.Now().Add(-2 * time.Hour)
+	//the database is created again with the same name after the orphan was found
+	orphans, err := collector.findOrphans(context.Background())
+	if err != nil || len(orphans) != 1 {
+		t.Fatalf("findOrphans() = %v, %v, want the ConfigMap", orphans, err)
+	}
+	_, deployment := testDatabase("left", "app", "service", 1)
+	if err = adminService.kubeClient.Create(context.Background(), deployment); err != nil {
+		t.Fatal(err)
+	}
+
+	if collector.deleteOrphan(context.Background(), orphans[0]) {
+		t.Errorf("deleteOrphan() = true, want the object of the recreated database kept")
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+)
+
+const (
+	defaultPasswordLength    = 24
+	defaultPasswordMinLength = 12
+)
+
+var (
+	passUppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passLowercase = "abcdefghijklmnopqrstuvwxyz"
+	passDigits    = "0123456789"
+	// special characters which don't need quoting in redis.conf, environment variables and connection strings,
+	// any other character of the set passwords counts as special, but only these are counted in the strength estimation
+	passSpecial = "!#%+,-.:=?@^_~"
+)
+
+// newPasswordPolicy returns the policy from the CR with the default lengths,
+// the policy which can't be satisfied by the generated passwords is rejected.
+func newPasswordPolicy(policy *v2.PasswordPolicy) (v2.PasswordPolicy, error) {
+	result := v2.PasswordPolicy{}
+	if policy != nil {
+		result = *policy
+	}
+	if result.Length <= 0 {
+		result.Length = defaultPasswordLength
+	}
+	if result.MinLength <= 0 {
+		result.MinLength = defaultPasswordMinLength
+	}
+	if result.Length < result.MinLength {
+		return result, fmt.Errorf("password length %d is less than the minimal length %d", result.Length, result.MinLength)
+	}
+	if classes := len(passwordClasses(result)); result.Length < classes {
+		return result, fmt.Errorf("password length %d is less than the number of the character classes %d of the generated passwords", result.Length, classes)
+	}
+	if bits := passwordEntropy(result.Length, true, true, true, result.RequireSpecial); result.MinEntropyBits > 0 && bits < float64(result.MinEntropyBits) {
+		return result, fmt.Errorf("password length %d gives at most %.0f bits of strength, at least %d bits are required", result.Length, bits, result.MinEntropyBits)
+	}
+	return result, nil
+}
+
+// passwordClasses returns the characters of every character class of the generated passwords: letters and digits,
+// and special characters if the policy requires them.
+func passwordClasses(policy v2.PasswordPolicy) []string {
+	classes := []string{passUppercase, passLowercase, passDigits}
+	if policy.RequireSpecial {
+		classes = append(classes, passSpecial)
+	}
+	return classes
+}
+
+// generatePassword returns a password of the policy length with the characters from crypto/rand,
+// one character of every class is always included, so the password has the strength checked by newPasswordPolicy.
+func generatePassword(policy v2.PasswordPolicy) (string, error) {
+	required := passwordClasses(policy)
+	charSet := strings.Join(required, "")
+	if policy.Length < len(required) {
+		return "", fmt.Errorf("password length %d is less than the number of the character classes", policy.Length)
+	}
+
+	password := make([]byte, policy.Length)
+	for i := range password {
+		chars := charSet
+		if i < len(required) {
+			chars = required[i]
+		}
+		n, err := randomInt(len(chars))
+		if err != nil {
+			return "", err
+		}
+		password[i] = chars[n]
+	}
+	//the required characters are moved to random positions
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	result := string(password)
+	if err := checkPassword(result, policy); err != nil {
+		return "", fmt.Errorf("password policy can't be satisfied by the generated password: %v", err)
+	}
+	return result, nil
+}
+
+func randomInt(max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random number: %v", err)
+	}
+	return int(n.Int64()), nil
+}
+
+// checkPassword returns InvalidArgumentError if the password violates the policy,
+// it must be checked for every password which is set to a logical database.
+// The password itself is never included in the error.
+func checkPassword(password string, policy v2.PasswordPolicy) error {
+	var violations []string
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	}
+	hasUppercase := strings.ContainsAny(password, passUppercase)
+	hasLowercase := strings.ContainsAny(password, passLowercase)
+	hasDigits := strings.ContainsAny(password, passDigits)
+	hasSpecial := strings.IndexFunc(password, func(r rune) bool {
+		return !strings.ContainsRune(passUppercase+passLowercase+passDigits, r)
+	}) >= 0
+	for _, class := range []struct {
+		required, present bool
+		name              string
+	}{
+		{policy.RequireUppercase, hasUppercase, "an uppercase letter"},
+		{policy.RequireLowercase, hasLowercase, "a lowercase letter"},
+		{policy.RequireDigits, hasDigits, "a digit"},
+		{policy.RequireSpecial, hasSpecial, "a special character"},
+	} {
+		if class.required && !class.present {
+			violations = append(violations, "must contain "+class.name)
+		}
+	}
+	if policy.MinEntropyBits > 0 {
+		if bits := passwordEntropy(length, hasUppercase, hasLowercase, hasDigits, hasSpecial); bits < float64(policy.MinEntropyBits) {
+			violations = append(violations, fmt.Sprintf("is too weak, strength is %.0f bits, at least %d bits are required", bits, policy.MinEntropyBits))
+		}
+	}
+	if len(violations) > 0 {
+		return customEntity.NewInvalidArgumentError("The password " + strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// passwordEntropy is the strength estimation of the password: its length multiplied
+// by log2 of the size of the character classes which it uses.
+func passwordEntropy(length int, hasUppercase, hasLowercase, hasDigits, hasSpecial bool) float64 {
+	poolSize := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{
+		{hasUppercase, len(passUppercase)},
+		{hasLowercase, len(passLowercase)},
+		{hasDigits, len(passDigits)},
+		{hasSpecial, len(passSpecial)},
+	} {
+		if class.present {
+			poolSize += class.size
+		}
+	}
+	if poolSize == 0 {
+		return 0
+	}
+	return float64(length) * math.Log2(float64(poolSize))
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+)
+
+func TestNewPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *v2.PasswordPolicy
+		want    v2.PasswordPolicy
+		wantErr bool
+	}{
+		{
+			name: "Default lengths",
+			want: v2.PasswordPolicy{Length: defaultPasswordLength, MinLength: defaultPasswordMinLength},
+		},
+		{
+			name:   "Lengths from the CR",
+			policy: &v2.PasswordPolicy{Length: 16, MinLength: 16, RequireDigits: true},
+			want:   v2.PasswordPolicy{Length: 16, MinLength: 16, RequireDigits: true},
+		},
+		{
+			name:    "Length less than the minimal length",
+			policy:  &v2.PasswordPolicy{Length: 10},
+			wantErr: true,
+		},
+		{
+			name:    "Length less than the required classes",
+			policy:  &v2.PasswordPolicy{Length: 3, MinLength: 1, RequireUppercase: true, RequireLowercase: true, RequireDigits: true, RequireSpecial: true},
+			wantErr: true,
+		},
+		{
+			name:    "Length less than the generated classes",
+			policy:  &v2.PasswordPolicy{Length: 2, MinLength: 1},
+			wantErr: true,
+		},
+		{
+			name:    "Unreachable strength",
+			policy:  &v2.PasswordPolicy{Length: 12, MinEntropyBits: 100},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newPasswordPolicy(tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newPasswordPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("newPasswordPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  v2.PasswordPolicy
+		wantErr bool
+	}{
+		{
+			name:   "Default policy",
+			policy: v2.PasswordPolicy{Length: defaultPasswordLength, MinLength: defaultPasswordMinLength},
+		},
+		{
+			name: "All classes required",
+			policy: v2.PasswordPolicy{Length: 16, MinLength: 16, RequireUppercase: true, RequireLowercase: true,
+				RequireDigits: true, RequireSpecial: true, MinEntropyBits: 60},
+		},
+		{
+			name:   "Length equal to the required classes",
+			policy: v2.PasswordPolicy{Length: 4, MinLength: 4, RequireUppercase: true, RequireLowercase: true, RequireDigits: true, RequireSpecial: true},
+		},
+		{
+			name:   "Strength of all classes without the required ones",
+			policy: v2.PasswordPolicy{Length: 4, MinLength: 4, MinEntropyBits: 23},
+		},
+		{
+			name:    "Length less than the required classes",
+			policy:  v2.PasswordPolicy{Length: 2, MinLength: 1, RequireUppercase: true, RequireLowercase: true, RequireDigits: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the characters are random, so every policy is checked several times
+			for i := 0; i < 50; i++ {
+				got, err := generatePassword(tt.policy)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("generatePassword() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				if len(got) != tt.policy.Length {
+					t.Fatalf("generatePassword() length = %d, want %d", len(got), tt.policy.Length)
+				}
+				if strings.Trim(got, passUppercase+passLowercase+passDigits+passSpecial) != "" {
+					t.Fatalf("generatePassword() contains characters out of the character classes")
+				}
+				if err = checkPassword(got, tt.policy); err != nil {
+					t.Fatalf("generatePassword() violates the policy: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		policy   v2.PasswordPolicy
+		wantErr  bool
+	}{
+		{
+			name:     "Long enough",
+			password: "abcdefghijkl",
+			policy:   v2.PasswordPolicy{MinLength: 12},
+		},
+		{
+			name:     "Too short",
+			password: "abcdefghijk",
+			policy:   v2.PasswordPolicy{MinLength: 12},
+			wantErr:  true,
+		},
+		{
+			name:     "Length in characters, not bytes",
+			password: "пароль",
+			policy:   v2.PasswordPolicy{MinLength: 7},
+			wantErr:  true,
+		},
+		{
+			name:     "All required classes",
+			password: "Abcdef1!",
+			policy:   v2.PasswordPolicy{MinLength: 8, RequireUppercase: true, RequireLowercase: true, RequireDigits: true, RequireSpecial: true},
+		},
+		{
+			name:     "No uppercase letter",
+			password: "abcdef1!",
+			policy:   v2.PasswordPolicy{MinLength: 8, RequireUppercase: true},
+			wantErr:  true,
+		},
+		{
+			name:     "No lowercase letter",
+			password: "ABCDEF1!",
+			policy:   v2.PasswordPolicy{MinLength: 8, RequireLowercase: true},
+			wantErr:  true,
+		},
+		{
+			name:     "No digit",
+			password: "Abcdefg!",
+			policy:   v2.PasswordPolicy{MinLength: 8, RequireDigits: true},
+			wantErr:  true,
+		},
+		{
+			name:     "No special character",
+			password: "Abcdefg1",
+			policy:   v2.PasswordPolicy{MinLength: 8, RequireSpecial: true},
+			wantErr:  true,
+		},
+		{
+			name:     "Any other character is special",
+			password: "Abcdefg$",
+			policy:   v2.PasswordPolicy{MinLength: 8, RequireSpecial: true},
+		},
+		{
+			name:     "Strong enough",
+			password: "abcdefghijklm",
+			policy:   v2.PasswordPolicy{MinLength: 8, MinEntropyBits: 60},
+		},
+		{
+			name:     "Special characters are counted by their pool",
+			password: "!#%+,-.:=?@^_~!#",
+			policy:   v2.PasswordPolicy{MinLength: 8, MinEntropyBits: 61},
+			wantErr:  true,
+		},
+		{
+			name:     "Too weak",
+			password: "0123456789012345",
+			policy:   v2.PasswordPolicy{MinLength: 8, MinEntropyBits: 60},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPassword(tt.password, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var invalidArgument *customEntity.InvalidArgumentError
+			if !errors.As(err, &invalidArgument) {
+				t.Errorf("checkPassword() error = %T, want InvalidArgumentError", err)
+			}
+			if strings.Contains(err.Error(), tt.password) {
+				t.Errorf("checkPassword() error contains the password")
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// podCheckPeriodSecond is the period of the checks of the pod state while the database is starting
+const podCheckPeriodSecond = 5
+
+// podStartFailures are the reasons of the waiting Redis container which don't go away without a change of the database
+var podStartFailures = map[string]bool{
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+}
+
+// preflightCheck returns PreconditionFailedError if the pod of the new database can't be created or scheduled
+// because of the ResourceQuotas and LimitRanges of the namespace or the nodes. Nodes are checked only
+// if the operator is allowed to list them.
+func (adminService *AdministrationService) preflightCheck(ctx context.Context, resources v1.ResourceRequirements,
+	nodeSelector map[string]string, tolerations []v1.Toleration) error {
+	limitRanges := &v1.LimitRangeList{}
+	if err := adminService.kubeClient.List(ctx, limitRanges, client.InNamespace(adminService.namespace)); err != nil {
+		return typedError(err, "failed to list LimitRanges")
+	}
+	resources = effectiveResources(resources, limitRanges.Items)
+	if err := checkLimitRanges(resources, limitRanges.Items); err != nil {
+		return err
+	}
+	if err := adminService.checkResourceQuotas(ctx, resources); err != nil {
+		return err
+	}
+	return adminService.checkNodes(ctx, resources, nodeSelector, tolerations)
+}
+
+// effectiveResources returns the resources of the container after the defaults of the LimitRanges are applied,
+// the request is equal to the limit if it is not set.
+func effectiveResources(resources v1.ResourceRequirements, limitRanges []v1.LimitRange) v1.ResourceRequirements {
+	result := *resources.DeepCopy()
+	if result.Limits == nil {
+		result.Limits = v1.ResourceList{}
+	}
+	if result.Requests == nil {
+		result.Requests = v1.ResourceList{}
+	}
+	for _, limitRange := range limitRanges {
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != v1.LimitTypeContainer {
+				continue
+			}
+			for name, quantity := range item.Default {
+				if _, ok := result.Limits[name]; !ok {
+					result.Limits[name] = quantity.DeepCopy()
+				}
+			}
+			for name, quantity := range item.DefaultRequest {
+				if _, ok := result.Requests[name]; !ok {
+					result.Requests[name] = quantity.DeepCopy()
+				}
+			}
+		}
+	}
+	for name, quantity := range result.Limits {
+		if _, ok := result.Requests[name]; !ok {
+			result.Requests[name] = quantity.DeepCopy()
+		}
+	}
+	return result
+}
+
+// checkLimitRanges checks the container and the pod limits of the LimitRanges, the pod has only the Redis container.
+func checkLimitRanges(resources v1.ResourceRequirements, limitRanges []v1.LimitRange) error {
+	for _, limitRange := range limitRanges {
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != v1.LimitTypeContainer && item.Type != v1.LimitTypePod {
+				continue
+			}
+			for name, max := range item.Max {
+				limit, ok := resources.Limits[name]
+				if !ok {
+					return customEntity.NewPreconditionFailedError(fmt.Sprintf("LimitRange %s requires the %s limit of %s, it is not set",
+						limitRange.Name, strings.ToLower(string(item.Type)), name))
+				}
+				if limit.Cmp(max) > 0 {
+					return customEntity.NewPreconditionFailedError(fmt.Sprintf("LimitRange %s allows %s of %s per %s, %s is requested",
+						limitRange.Name, max.String(), name, strings.ToLower(string(item.Type)), limit.String()))
+				}
+			}
+			for name, min := range item.Min {
+				request, ok := resources.Requests[name]
+				if !ok || request.Cmp(min) < 0 {
+					return customEntity.NewPreconditionFailedError(fmt.Sprintf("LimitRange %s requires at least %s of %s per %s, %s is requested",
+						limitRange.Name, min.String(), name, strings.ToLower(string(item.Type)), request.String()))
+				}
+			}
+			for name, ratio := range item.MaxLimitRequestRatio {
+				limit, limited := resources.Limits[name]
+				request, requested := resources.Requests[name]
+				if !limited || !requested || request.IsZero() {
+					continue
+				}
+				if float64(limit.MilliValue())/float64(request.MilliValue()) > float64(ratio.MilliValue())/1000 {
+					return customEntity.NewPreconditionFailedError(fmt.Sprintf("LimitRange %s allows the %s limit to request ratio up to %s, %s to %s is requested",
+						limitRange.Name, name, ratio.String(), limit.String(), request.String()))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkResourceQuotas checks the headroom of the ResourceQuotas for the objects of the new database.
+// The quotas with scopes are skipped, they could not apply to the database.
+func (adminService *AdministrationService) checkResourceQuotas(ctx context.Context, resources v1.ResourceRequirements) error {
+	quotas := &v1.ResourceQuotaList{}
+	if err := adminService.kubeClient.List(ctx, quotas, client.InNamespace(adminService.namespace)); err != nil {
+		return typedError(err, "failed to list ResourceQuotas")
+	}
+	needed := v1.ResourceList{
+		v1.ResourcePods:           resource.MustParse("1"),
+		v1.ResourceServices:       resource.MustParse("1"),
+		v1.ResourceSecrets:        resource.MustParse("1"),
+		v1.ResourceConfigMaps:     resource.MustParse("1"),
+		"count/deployments.apps":  resource.MustParse("1"),
+		"count/services":          resource.MustParse("1"),
+		"count/secrets":           resource.MustParse("1"),
+		"count/configmaps":        resource.MustParse("1"),
+		v1.ResourceRequestsCPU:    resources.Requests[v1.ResourceCPU],
+		v1.ResourceCPU:            resources.Requests[v1.ResourceCPU],
+		v1.ResourceRequestsMemory: resources.Requests[v1.ResourceMemory],
+		v1.ResourceMemory:         resources.Requests[v1.ResourceMemory],
+		v1.ResourceLimitsCPU:      resources.Limits[v1.ResourceCPU],
+		v1.ResourceLimitsMemory:   resources.Limits[v1.ResourceMemory],
+	}
+	if adminService.softDelete.enabled {
+		needed[v1.ResourcePersistentVolumeClaims] = resource.MustParse("1")
+		needed["count/persistentvolumeclaims"] = resource.MustParse("1")
+		needed[v1.ResourceRequestsStorage] = adminService.softDelete.storageSize
+	}
+
+	for _, quota := range quotas.Items {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		names := make([]string, 0, len(quota.Status.Hard))
+		for name := range quota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			hard := quota.Status.Hard[v1.ResourceName(name)]
+			need, ok := needed[v1.ResourceName(name)]
+			if !ok || need.IsZero() {
+				continue
+			}
+			used := quota.Status.Used[v1.ResourceName(name)]
+			total := used.DeepCopy()
+			total.Add(need)
+			if total.Cmp(hard) > 0 {
+				return customEntity.NewPreconditionFailedError(fmt.Sprintf("ResourceQuota %s has no room for the database: %s of %s is used of %s, %s is needed",
+					quota.Name, used.String(), name, hard.String(), need.String()))
+			}
+		}
+	}
+	return nil
+}
+
+// checkNodes checks that at least one schedulable node matches the node selector, tolerates its taints
+// and has enough allocatable resources for the requests. The pods running on the nodes are not taken into account.
+func (adminService *AdministrationService) checkNodes(ctx context.Context, resources v1.ResourceRequirements,
+	nodeSelector map[string]string, tolerations []v1.Toleration) error {
+	nodes := &v1.NodeList{}
+	err := adminService.kubeClient.List(ctx, nodes)
+	if errors.IsForbidden(err) {
+		//nodes are cluster scoped, the operator can be installed without the access to them
+		utils.AddLoggerContext(adminService.logger, ctx).Debug(fmt.Sprintf("Nodes are not checked, err: %v", err))
+		return nil
+	}
+	if err != nil {
+		return typedError(err, "failed to list nodes")
+	}
+	if len(nodes.Items) == 0 {
+		return nil
+	}
+
+	selector := labels.SelectorFromSet(nodeSelector)
+	var unschedulable, notSelected, tainted, small int
+	for _, node := range nodes.Items {
+		switch {
+		case node.Spec.Unschedulable:
+			unschedulable++
+		case !selector.Matches(labels.Set(node.Labels)):
+			notSelected++
+		case !toleratesTaints(node.Spec.Taints, tolerations):
+			tainted++
+		case !fitsAllocatable(node.Status.Allocatable, resources.Requests):
+			small++
+		default:
+			return nil
+		}
+	}
+	return customEntity.NewPreconditionFailedError(fmt.Sprintf("No node can run the database with node selector %v: "+
+		"%d nodes are unschedulable, %d don't match the node selector, %d have taints which are not tolerated, %d have less allocatable resources than requested %v",
+		nodeSelector, unschedulable, notSelected, tainted, small, resources.Requests))
+}
+
+func toleratesTaints(taints []v1.Taint, tolerations []v1.Toleration) bool {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+func fitsAllocatable(allocatable v1.ResourceList, requests v1.ResourceList) bool {
+	for name, request := range requests {
+		if available, ok := allocatable[name]; ok && request.Cmp(available) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// podStartFailure returns the error if the pod of the database can't start, e.g. it can't be scheduled, its image
+// can't be pulled or Redis crashes. The events which occurred before since are ignored.
+func (adminService *AdministrationService) podStartFailure(ctx context.Context, dbName string, since time.Time) error {
+	pods := &v1.PodList{}
+	err := adminService.kubeClient.List(ctx, pods, client.InNamespace(adminService.namespace), client.MatchingLabels{constants.Name: dbName})
+	if err != nil {
+		return nil
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			waiting := status.State.Waiting
+			if waiting == nil || !podStartFailures[waiting.Reason] {
+				continue
+			}
+			message := fmt.Sprintf("Database %s can't start, pod %s is in %s: %s", dbName, pod.Name, waiting.Reason, waiting.Message)
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				message += fmt.Sprintf(", the last run ended with %s (exit code %d) %s", terminated.Reason, terminated.ExitCode, terminated.Message)
+			}
+			return customEntity.NewPreconditionFailedError(message)
+		}
+	}
+
+	events := &v1.EventList{}
+	if err = adminService.kubeClient.List(ctx, events, client.InNamespace(adminService.namespace),
+		client.MatchingFields{"type": v1.EventTypeWarning}); err != nil {
+		return nil
+	}
+	podNames := map[string]bool{}
+	for _, pod := range pods.Items {
+		podNames[pod.Name] = true
+	}
+	scaleUp := map[string]bool{}
+	for _, event := range events.Items {
+		if event.Reason == "TriggeredScaleUp" {
+			scaleUp[event.InvolvedObject.Name] = true
+		}
+	}
+	for _, event := range events.Items {
+		if eventTime(event).Before(since) {
+			continue
+		}
+		object := event.InvolvedObject
+		switch {
+		case event.Reason == "FailedScheduling" && object.Kind == "Pod" && podNames[object.Name] && !scaleUp[object.Name]:
+			return customEntity.NewPreconditionFailedError(fmt.Sprintf("Database %s can't be scheduled: %s", dbName, event.Message))
+		case event.Reason == "FailedCreate" && object.Kind == "ReplicaSet" && isReplicaSetOf(object.Name, dbName):
+			message := fmt.Sprintf("Pod of database %s can't be created: %s", dbName, event.Message)
+			if strings.Contains(event.Message, "exceeded quota") {
+				return customEntity.NewQuotaExceededError(message)
+			}
+			return customEntity.NewPreconditionFailedError(message)
+		}
+	}
+	return nil
+}
+
+// isReplicaSetOf is true if the ReplicaSet name is the deployment name with the pod template hash.
+func isReplicaSetOf(replicaSet string, deployment string) bool {
+	hash, ok := strings.CutPrefix(replicaSet, deployment+"-")
+	return ok && hash != "" && !strings.Contains(hash, "-")
+}
+
+func eventTime(event v1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.FirstTimestamp.Time
+}
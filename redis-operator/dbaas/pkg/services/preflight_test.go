@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func resourceQuota(name string, hard, used v1.ResourceList, scopes ...v1.ResourceQuotaScope) *v1.ResourceQuota {
+	return &v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       v1.ResourceQuotaSpec{Hard: hard, Scopes: scopes},
+		Status:     v1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func testNode(name string, labels map[string]string, taints []v1.Taint, allocatableMemory string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       v1.NodeSpec{Taints: taints},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceMemory: resource.MustParse(allocatableMemory),
+			v1.ResourceCPU:    resource.MustParse("2"),
+		}},
+	}
+}
+
+func TestPreflightCheck(t *testing.T) {
+	resources := v1.ResourceRequirements{Limits: v1.ResourceList{
+		v1.ResourceMemory: resource.MustParse("256Mi"),
+		v1.ResourceCPU:    resource.MustParse("100m"),
+	}}
+	taint := v1.Taint{Key: "dedicated", Value: "redis", Effect: v1.TaintEffectNoSchedule}
+	toleration := v1.Toleration{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "redis", Effect: v1.TaintEffectNoSchedule}
+	pool := map[string]string{"pool": "redis"}
+	tests := []struct {
+		name         string
+		objects      []client.Object
+		nodeSelector map[string]string
+		tolerations  []v1.Toleration
+		wantFailed   bool
+	}{
+		{name: "No quotas, limit ranges and nodes"},
+		{
+			name: "Quota with headroom",
+			objects: []client.Object{resourceQuota("memory",
+				v1.ResourceList{v1.ResourceLimitsMemory: resource.MustParse("1Gi")},
+				v1.ResourceList{v1.ResourceLimitsMemory: resource.MustParse("768Mi")})},
+		},
+		{
+			name: "Quota without memory headroom",
+			objects: []client.Object{resourceQuota("memory",
+				v1.ResourceList{v1.ResourceLimitsMemory: resource.MustParse("1Gi")},
+				v1.ResourceList{v1.ResourceLimitsMemory: resource.MustParse("800Mi")})},
+			wantFailed: true,
+		},
+		{
+			name: "Quota without CPU request headroom, the request is equal to the limit",
+			objects: []client.Object{resourceQuota("cpu",
+				v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("1")},
+				v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("950m")})},
+			wantFailed: true,
+		},
+		{
+			name: "Quota of pods is used",
+			objects: []client.Object{resourceQuota("pods",
+				v1.ResourceList{v1.ResourcePods: resource.MustParse("5")},
+				v1.ResourceList{v1.ResourcePods: resource.MustParse("5")})},
+			wantFailed: true,
+		},
+		{
+			name: "Quota with scopes is skipped",
+			objects: []client.Object{resourceQuota("best-effort",
+				v1.ResourceList{v1.ResourcePods: resource.MustParse("5")},
+				v1.ResourceList{v1.ResourcePods: resource.MustParse("5")},
+				v1.ResourceQuotaScopeBestEffort)},
+		},
+		{
+			name: "Limit range below the limit",
+			objects: []client.Object{&v1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: testNamespace},
+				Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{{
+					Type: v1.LimitTypeContainer,
+					Max:  v1.ResourceList{v1.ResourceMemory: resource.MustParse("128Mi")},
+				}}},
+			}},
+			wantFailed: true,
+		},
+		{
+			name:    "Schedulable node",
+			objects: []client.Object{testNode("node", nil, nil, "4Gi")},
+		},
+		{
+			name:         "Node selector matches no node",
+			objects:      []client.Object{testNode("node", nil, nil, "4Gi")},
+			nodeSelector: pool,
+			wantFailed:   true,
+		},
+		{
+			name:         "Node selector matches a node",
+			objects:      []client.Object{testNode("node", nil, nil, "4Gi"), testNode("redis-node", pool, nil, "4Gi")},
+			nodeSelector: pool,
+		},
+		{
+			name:       "Taint is not tolerated",
+			objects:    []client.Object{testNode("node", nil, []v1.Taint{taint}, "4Gi")},
+			wantFailed: true,
+		},
+		{
+			name:        "Taint is tolerated",
+			objects:     []client.Object{testNode("node", nil, []v1.Taint{taint}, "4Gi")},
+			tolerations: []v1.Toleration{toleration},
+		},
+		{
+			name:    "Preferred taint is not required to be tolerated",
+			objects: []client.Object{testNode("node", nil, []v1.Taint{{Key: "dedicated", Effect: v1.TaintEffectPreferNoSchedule}}, "4Gi")},
+		},
+		{
+			name:       "Node is too small",
+			objects:    []client.Object{testNode("node", nil, nil, "128Mi")},
+			wantFailed: true,
+		},
+		{
+			name: "Node is unschedulable",
+			objects: []client.Object{func() client.Object {
+				node := testNode("node", nil, nil, "4Gi")
+				node.Spec.Unschedulable = true
+				return node
+			}()},
+			wantFailed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := newTestAdministrationService(t, tt.objects...)
+			err := adminService.preflightCheck(context.Background(), resources, tt.nodeSelector, tt.tolerations)
+			if tt.wantFailed {
+				if _, ok := err.(*customEntity.PreconditionFailedError); !ok {
+					t.Errorf("preflightCheck() error = %v, want PreconditionFailedError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("preflightCheck() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestEffectiveResources(t *testing.T) {
+	limitRanges := []v1.LimitRange{{Spec: v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{{
+		Type:           v1.LimitTypeContainer,
+		Default:        v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+		DefaultRequest: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+	}}}}}
+	resources := effectiveResources(v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("256Mi")}}, limitRanges)
+
+	want := map[string]v1.ResourceList{
+		"limits":   {v1.ResourceMemory: resource.MustParse("256Mi"), v1.ResourceCPU: resource.MustParse("500m")},
+		"requests": {v1.ResourceMemory: resource.MustParse("256Mi"), v1.ResourceCPU: resource.MustParse("100m")},
+	}
+	if !quantitiesEqual(resources.Limits, want["limits"]) || !quantitiesEqual(resources.Requests, want["requests"]) {
+		t.Errorf("effectiveResources() = %v, want %v", resources, want)
+	}
+}
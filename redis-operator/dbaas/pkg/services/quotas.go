@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/helper"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// QuotaUsage.Scope values
+const (
+	QuotaScopeAdapter      = "adapter"
+	QuotaScopeNamespace    = "namespace"
+	QuotaScopeMicroservice = "microservice"
+)
+
+type quotaKey struct {
+	scope        string
+	namespace    string
+	microservice string
+}
+
+// quotas of logical databases from the DbaasRedisAdapter spec. The checks are serialized and the checked database
+// is counted as pending until its objects are created, so the concurrent requests can't exceed the quota together.
+type quotas struct {
+	adapter      *v2.Quota
+	namespace    *v2.Quota
+	microservice *v2.Quota
+	overrides    map[quotaKey]v2.Quota
+	mutex        sync.Mutex
+	pending      map[string]databaseUsage
+}
+
+// databaseUsage is the resources of the logical database counted in the quotas.
+type databaseUsage struct {
+	name         string
+	namespace    string
+	microservice string
+	memory       resource.Quantity
+	cpu          resource.Quantity
+}
+
+func newQuotas(spec *v2.Quotas) (*quotas, error) {
+	result := &quotas{overrides: map[quotaKey]v2.Quota{}, pending: map[string]databaseUsage{}}
+	if spec == nil {
+		return result, nil
+	}
+	result.adapter = spec.Adapter
+	result.namespace = spec.Namespace
+	result.microservice = spec.Microservice
+	for _, override := range spec.Overrides {
+		if override.Namespace == "" {
+			return result, fmt.Errorf("quota override namespace is empty")
+		}
+		//the classifier is stored in the labels of the database objects
+		key := quotaKey{scope: QuotaScopeNamespace, namespace: helper.LabelValue(override.Namespace)}
+		if override.Microservice != "" {
+			key = quotaKey{scope: QuotaScopeMicroservice, namespace: key.namespace, microservice: helper.LabelValue(override.Microservice)}
+		}
+		if _, ok := result.overrides[key]; ok {
+			return result, fmt.Errorf("quota of %s is overridden twice", key)
+		}
+		result.overrides[key] = override.Quota
+	}
+	return result, nil
+}
+
+func (q *quotas) configured() bool {
+	return q.adapter != nil || q.namespace != nil || q.microservice != nil || len(q.overrides) > 0
+}
+
+// quota returns the quota of the scope, nil is returned if it is not limited.
+func (q *quotas) quota(key quotaKey) *v2.Quota {
+	if override, ok := q.overrides[key]; ok {
+		return &override
+	}
+	switch key.scope {
+	case QuotaScopeAdapter:
+		return q.adapter
+	case QuotaScopeNamespace:
+		return q.namespace
+	case QuotaScopeMicroservice:
+		return q.microservice
+	}
+	return nil
+}
+
+// quotaKeys returns the scopes the database with the classifier labels is counted in.
+func quotaKeys(labels map[string]string) []quotaKey {
+	keys := []quotaKey{{scope: QuotaScopeAdapter}}
+	namespace := labels[templates.ClassifierNamespace]
+	if namespace != "" {
+		keys = append(keys, quotaKey{scope: QuotaScopeNamespace, namespace: namespace})
+	}
+	if microservice := labels[templates.ClassifierMicroserviceName]; microservice != "" {
+		keys = append(keys, quotaKey{scope: QuotaScopeMicroservice, namespace: namespace, microservice: microservice})
+	}
+	return keys
+}
+
+func (k quotaKey) String() string {
+	switch k.scope {
+	case QuotaScopeNamespace:
+		return fmt.Sprintf("namespace %s", k.namespace)
+	case QuotaScopeMicroservice:
+		return fmt.Sprintf("microservice %s of namespace %s", k.microservice, k.namespace)
+	}
+	return k.scope
+}
+
+func (k quotaKey) covers(database databaseUsage) bool {
+	switch k.scope {
+	case QuotaScopeNamespace:
+		return database.namespace == k.namespace
+	case QuotaScopeMicroservice:
+		return database.namespace == k.namespace && database.microservice == k.microservice
+	}
+	return true
+}
+
+// usage sums the databases counted in the quota of the scope.
+func (k quotaKey) usage(databases []databaseUsage, quota *v2.Quota) v2.QuotaUsage {
+	usage := v2.QuotaUsage{Scope: k.scope, Namespace: k.namespace, Microservice: k.microservice}
+	if quota != nil {
+		usage.Quota = *quota
+	}
+	for _, database := range databases {
+		if k.covers(database) {
+			usage.Databases++
+			usage.Memory.Add(database.memory)
+			usage.CPU.Add(database.cpu)
+		}
+	}
+	return usage
+}
+
+// checkQuotas returns QuotaExceededError if the new database with the classifier labels and the resources doesn't fit
+// in any of its quotas. The database is counted as pending until the returned release is called,
+// it must be called when the database objects are created or removed.
+func (adminService *AdministrationService) checkQuotas(ctx context.Context, name string, labels map[string]string,
+	resources v1.ResourceRequirements) (func(), error) {
+	q := adminService.quotas
+	if !q.configured() {
+		return func() {}, nil
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	databases, err := adminService.databasesUsage(ctx)
+	if err != nil {
+		return nil, typedError(err, "failed to read the usage of quotas")
+	}
+	databases = q.withPending(databases)
+	requested := databaseResources(resources)
+	requested.name = name
+	requested.namespace = labels[templates.ClassifierNamespace]
+	requested.microservice = labels[templates.ClassifierMicroserviceName]
+	for _, key := range quotaKeys(labels) {
+		quota := q.quota(key)
+		if quota == nil {
+			continue
+		}
+		if err = quotaExceeded(key, key.usage(databases, quota), requested); err != nil {
+			utils.AddLoggerContext(adminService.logger, ctx).Warn(err.Error())
+			return nil, err
+		}
+	}
+	q.pending[name] = requested
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+			delete(q.pending, name)
+		})
+	}, nil
+}
+
+// withPending adds the databases which are being created to the listed ones, their objects may be listed already.
+func (q *quotas) withPending(databases []databaseUsage) []databaseUsage {
+	var result []databaseUsage
+	for _, database := range databases {
+		if _, ok := q.pending[database.name]; !ok {
+			result = append(result, database)
+		}
+	}
+	for _, database := range q.pending {
+		result = append(result, database)
+	}
+	return result
+}
+
+func quotaExceeded(key quotaKey, usage v2.QuotaUsage, requested databaseUsage) error {
+	quota := usage.Quota
+	if quota.MaxDatabases != nil && usage.Databases+1 > *quota.MaxDatabases {
+		return customEntity.NewQuotaExceededError(fmt.Sprintf("Quota of %s is exceeded: %d databases of %d are created",
+			key, usage.Databases, *quota.MaxDatabases))
+	}
+	if quota.Memory != nil {
+		if requested.memory.IsZero() {
+			return customEntity.NewInvalidArgumentError(fmt.Sprintf("Memory limit of the database must be set, the memory quota of %s is set", key))
+		}
+		total := usage.Memory.DeepCopy()
+		total.Add(requested.memory)
+		if total.Cmp(*quota.Memory) > 0 {
+			return customEntity.NewQuotaExceededError(fmt.Sprintf("Quota of %s is exceeded: %s of memory %s is used, %s is requested",
+				key, usage.Memory.String(), quota.Memory.String(), requested.memory.String()))
+		}
+	}
+	if quota.CPU != nil {
+		if requested.cpu.IsZero() {
+			return customEntity.NewInvalidArgumentError(fmt.Sprintf("CPU limit of the database must be set, the CPU quota of %s is set", key))
+		}
+		total := usage.CPU.DeepCopy()
+		total.Add(requested.cpu)
+		if total.Cmp(*quota.CPU) > 0 {
+			return customEntity.NewQuotaExceededError(fmt.Sprintf("Quota of %s is exceeded: %s of CPU %s is used, %s is requested",
+				key, usage.CPU.String(), quota.CPU.String(), requested.cpu.String()))
+		}
+	}
+	return nil
+}
+
+// GetQuotaUsage returns the usage of the adapter quota and of the quotas of the namespaces and the microservices
+// which have databases or an override.
+func (adminService *AdministrationService) GetQuotaUsage(ctx context.Context) ([]v2.QuotaUsage, error) {
+	q := adminService.quotas
+	result := []v2.QuotaUsage{}
+	if !q.configured() {
+		return result, nil
+	}
+	databases, err := adminService.databasesUsage(ctx)
+	if err != nil {
+		return nil, typedError(err, "failed to read the usage of quotas")
+	}
+
+	keys := map[quotaKey]bool{{scope: QuotaScopeAdapter}: true}
+	for key := range q.overrides {
+		keys[key] = true
+	}
+	for _, database := range databases {
+		for _, key := range quotaKeys(map[string]string{
+			templates.ClassifierNamespace:        database.namespace,
+			templates.ClassifierMicroserviceName: database.microservice,
+		}) {
+			keys[key] = true
+		}
+	}
+	for key := range keys {
+		if quota := q.quota(key); quota != nil {
+			result = append(result, key.usage(databases, quota))
+		}
+	}
+	scopeOrder := map[string]int{QuotaScopeAdapter: 0, QuotaScopeNamespace: 1, QuotaScopeMicroservice: 2}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Scope != result[j].Scope {
+			return scopeOrder[result[i].Scope] < scopeOrder[result[j].Scope]
+		}
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Microservice < result[j].Microservice
+	})
+	return result, nil
+}
+
+// QuotasStatus returns the usage of the quotas for the CR status, nil is returned if no quota is set.
+func (adminService *AdministrationService) QuotasStatus(ctx context.Context) (*v2.QuotasStatus, error) {
+	if !adminService.quotas.configured() {
+		return nil, nil
+	}
+	usage, err := adminService.GetQuotaUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &v2.QuotasStatus{Usage: usage}, nil
+}
+
+// databasesUsage returns the resources of the running logical databases, the soft deleted databases are not counted.
+func (adminService *AdministrationService) databasesUsage(ctx context.Context) ([]databaseUsage, error) {
+	deployments := &appsv1.DeploymentList{}
+	err := adminService.kubeClient.List(ctx, deployments, client.InNamespace(adminService.namespace),
+		client.MatchingLabels{adminService.redisLabel: adminService.redisLabel})
+	if err != nil {
+		return nil, err
+	}
+	var databases []databaseUsage
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if !usesCredentialsSecret(deployment) || len(deployment.Spec.Template.Spec.Containers) == 0 {
+			//not created by the adapter, e.g. the standalone Redis
+			continue
+		}
+		database := databaseResources(deployment.Spec.Template.Spec.Containers[0].Resources)
+		database.name = deployment.Name
+		database.namespace = deployment.Labels[templates.ClassifierNamespace]
+		database.microservice = deployment.Labels[templates.ClassifierMicroserviceName]
+		databases = append(databases, database)
+	}
+	return databases, nil
+}
+
+// databaseResources returns the memory and CPU counted in the quotas, the limits or the requests if the limits are not set.
+func databaseResources(resources v1.ResourceRequirements) databaseUsage {
+	usage := databaseUsage{}
+	for name, quantity := range map[v1.ResourceName]*resource.Quantity{v1.ResourceMemory: &usage.memory, v1.ResourceCPU: &usage.cpu} {
+		if limit, ok := resources.Limits[name]; ok {
+			*quantity = limit.DeepCopy()
+		} else if request, ok := resources.Requests[name]; ok {
+			*quantity = request.DeepCopy()
+		}
+	}
+	return usage
+}
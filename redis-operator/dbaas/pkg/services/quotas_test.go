@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestQuotaKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []quotaKey
+	}{
+		{
+			name: "No classifier",
+			want: []quotaKey{{scope: QuotaScopeAdapter}},
+		},
+		{
+			name:   "Namespace",
+			labels: map[string]string{templates.ClassifierNamespace: "app"},
+			want:   []quotaKey{{scope: QuotaScopeAdapter}, {scope: QuotaScopeNamespace, namespace: "app"}},
+		},
+		{
+			name:   "Namespace and microservice",
+			labels: map[string]string{templates.ClassifierNamespace: "app", templates.ClassifierMicroserviceName: "service"},
+			want: []quotaKey{
+				{scope: QuotaScopeAdapter},
+				{scope: QuotaScopeNamespace, namespace: "app"},
+				{scope: QuotaScopeMicroservice, namespace: "app", microservice: "service"},
+			},
+		},
+		{
+			name:   "Microservice without namespace",
+			labels: map[string]string{templates.ClassifierMicroserviceName: "service"},
+			want:   []quotaKey{{scope: QuotaScopeAdapter}, {scope: QuotaScopeMicroservice, microservice: "service"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quotaKeys(tt.labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("quotaKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	two := 2
+	memory := resource.MustParse("1Gi")
+	cpu := resource.MustParse("1")
+	usage := func(databases int, memory, cpu string) v2.QuotaUsage {
+		return v2.QuotaUsage{Databases: databases, Memory: resource.MustParse(memory), CPU: resource.MustParse(cpu)}
+	}
+	requested := databaseUsage{memory: resource.MustParse("256Mi"), cpu: resource.MustParse("250m")}
+	tests := []struct {
+		name      string
+		quota     v2.Quota
+		usage     v2.QuotaUsage
+		requested databaseUsage
+		want      interface{}
+	}{
+		{
+			name:      "Not limited",
+			usage:     usage(10, "10Gi", "10"),
+			requested: requested,
+		},
+		{
+			name:      "Databases fit",
+			quota:     v2.Quota{MaxDatabases: &two},
+			usage:     usage(1, "0", "0"),
+			requested: requested,
+		},
+		{
+			name:      "Databases exceeded",
+			quota:     v2.Quota{MaxDatabases: &two},
+			usage:     usage(2, "0", "0"),
+			requested: requested,
+			want:      &customEntity.QuotaExceededError{},
+		},
+		{
+			name:      "Memory fits exactly",
+			quota:     v2.Quota{Memory: &memory},
+			usage:     usage(3, "768Mi", "0"),
+			requested: requested,
+		},
+		{
+			name:      "Memory exceeded",
+			quota:     v2.Quota{Memory: &memory},
+			usage:     usage(3, "800Mi", "0"),
+			requested: requested,
+			want:      &customEntity.QuotaExceededError{},
+		},
+		{
+			name:      "Memory limit not set",
+			quota:     v2.Quota{Memory: &memory},
+			usage:     usage(0, "0", "0"),
+			requested: databaseUsage{cpu: resource.MustParse("250m")},
+			want:      &customEntity.InvalidArgumentError{},
+		},
+		{
+			name:      "CPU exceeded",
+			quota:     v2.Quota{CPU: &cpu},
+			usage:     usage(3, "0", "800m"),
+			requested: requested,
+			want:      &customEntity.QuotaExceededError{},
+		},
+		{
+			name:      "CPU limit not set",
+			quota:     v2.Quota{CPU: &cpu},
+			usage:     usage(0, "0", "0"),
+			requested: databaseUsage{memory: resource.MustParse("256Mi")},
+			want:      &customEntity.InvalidArgumentError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.usage.Quota = tt.quota
+			err := quotaExceeded(quotaKey{scope: QuotaScopeAdapter}, tt.usage, tt.requested)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("quotaExceeded() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || reflect.TypeOf(err) != reflect.TypeOf(tt.want) {
+				t.Errorf("quotaExceeded() error = %v, want %T", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckQuotasCountsPendingDatabases(t *testing.T) {
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	adminService := newTestAdministrationService(t, secret, deployment)
+	maxDatabases := 3
+	adminService.quotas.namespace = &v2.Quota{MaxDatabases: &maxDatabases}
+	labels := map[string]string{templates.ClassifierNamespace: "app"}
+	resources := v1.ResourceRequirements{}
+	ctx := context.Background()
+
+	releaseFirst, err := adminService.checkQuotas(ctx, "first", labels, resources)
+	if err != nil {
+		t.Fatalf("checkQuotas() error = %v", err)
+	}
+	releaseSecond, err := adminService.checkQuotas(ctx, "second", labels, resources)
+	if err != nil {
+		t.Fatalf("checkQuotas() error = %v", err)
+	}
+	var quotaExceeded *customEntity.QuotaExceededError
+	if _, err = adminService.checkQuotas(ctx, "third", labels, resources); !errors.As(err, &quotaExceeded) {
+		t.Fatalf("checkQuotas() error = %v with 2 pending databases, want QuotaExceededError", err)
+	}
+	if _, err = adminService.checkQuotas(ctx, "other", map[string]string{templates.ClassifierNamespace: "other"}, resources); err != nil {
+		t.Fatalf("checkQuotas() error = %v for another namespace, want nil", err)
+	}
+
+	releaseFirst()
+	releaseFirst()
+	releaseThird, err := adminService.checkQuotas(ctx, "third", labels, resources)
+	if err != nil {
+		t.Fatalf("checkQuotas() error = %v after the release, want nil", err)
+	}
+	releaseSecond()
+	releaseThird()
+}
@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RedisVersionAnnotation keeps the Redis version the database is pinned to, the image of the database
+	// is not changed with the Redis image of the CR
+	RedisVersionAnnotation = "netcracker.com/redis-version"
+
+	versionLabelSyncInterval = 10 * time.Minute
+)
+
+var versionRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+
+// redisVersions are the allowed Redis images of logical databases from the DbaasRedisAdapter spec.
+type redisVersions struct {
+	versions  []v2.RedisVersion
+	byVersion map[string]v2.RedisVersion
+	// upgrading are the databases being upgraded, a database is upgraded by one request at a time
+	upgrading map[string]bool
+	mutex     sync.Mutex
+}
+
+// UpgradeRequest is the body of the upgrade of a logical database.
+type UpgradeRequest struct {
+	// Version from the allowed Redis versions, it must be newer than the running one
+	Version string `json:"version"`
+	// WaitSeconds is the time for the upgraded database to become ready, the create timeout by default
+	WaitSeconds int `json:"waitSeconds,omitempty"`
+	// Force allows the upgrade of the database without a data volume claim, its data is lost
+	Force bool `json:"force,omitempty"`
+}
+
+// UpgradeResult is the upgraded logical database.
+type UpgradeResult struct {
+	Name string `json:"name"`
+	// PreviousVersion and Version are reported by INFO server before and after the upgrade
+	PreviousVersion string `json:"previousVersion"`
+	Version         string `json:"version"`
+	Image           string `json:"image"`
+	// DataKept is true if the data is kept in the data volume claim, otherwise the upgraded database is empty
+	DataKept bool `json:"dataKept"`
+}
+
+func newRedisVersions(spec []v2.RedisVersion) (*redisVersions, error) {
+	result := &redisVersions{byVersion: map[string]v2.RedisVersion{}, upgrading: map[string]bool{}}
+	for _, version := range spec {
+		if !versionRegexp.MatchString(version.Version) {
+			return result, fmt.Errorf("version '%s' must be numbers separated by dots, e.g. 7.2.4", version.Version)
+		}
+		if version.Image == "" {
+			return result, fmt.Errorf("image of version %s is empty", version.Version)
+		}
+		if _, ok := result.byVersion[version.Version]; ok {
+			return result, fmt.Errorf("version %s is defined twice", version.Version)
+		}
+		result.byVersion[version.Version] = version
+	}
+	result.versions = append(result.versions, spec...)
+	return result, nil
+}
+
+func (r *redisVersions) configured() bool {
+	return len(r.versions) > 0
+}
+
+func (r *redisVersions) names() []string {
+	var names []string
+	for _, version := range r.versions {
+		names = append(names, version.Version)
+	}
+	return names
+}
+
+// selected returns the version pinned by the redisVersion or redisImage setting, nil is returned if neither is set.
+func (r *redisVersions) selected(settings *customEntity.DbCreateRequestSettings) (*v2.RedisVersion, error) {
+	if settings.RedisVersion == "" && settings.RedisImage == "" {
+		return nil, nil
+	}
+	if !r.configured() {
+		return nil, customEntity.NewInvalidArgumentError("Redis versions are not configured, redisVersion and redisImage settings are not supported")
+	}
+	var selected *v2.RedisVersion
+	if settings.RedisVersion != "" {
+		version, ok := r.byVersion[settings.RedisVersion]
+		if !ok {
+			return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Unknown Redis version %s, available versions: %s",
+				settings.RedisVersion, strings.Join(r.names(), ", ")))
+		}
+		selected = &version
+	}
+	if settings.RedisImage != "" {
+		for i := range r.versions {
+			if r.versions[i].Image != settings.RedisImage {
+				continue
+			}
+			if selected != nil && selected.Version != r.versions[i].Version {
+				return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Redis image %s is not the image of version %s",
+					settings.RedisImage, selected.Version))
+			}
+			selected = &r.versions[i]
+			break
+		}
+		if selected == nil || selected.Image != settings.RedisImage {
+			return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Redis image %s is not allowed, available versions: %s",
+				settings.RedisImage, strings.Join(r.names(), ", ")))
+		}
+	}
+	return selected, nil
+}
+
+func (r *redisVersions) startUpgrade(dbName string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.upgrading[dbName] {
+		return false
+	}
+	r.upgrading[dbName] = true
+	return true
+}
+
+func (r *redisVersions) finishUpgrade(dbName string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.upgrading, dbName)
+}
+
+func (r *redisVersions) isUpgrading(dbName string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.upgrading[dbName]
+}
+
+// compareVersions compares the numbers of the versions up to the shorter one, so 7.2 is the same as 7.2.4.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumber, _ := strconv.Atoi(aParts[i])
+		bNumber, _ := strconv.Atoi(bParts[i])
+		if aNumber != bNumber {
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// PinnedRedisImage returns the image of the database pinned to a Redis version, it is the image of the version
+// from the allowed versions or the current image if the version is not allowed any more.
+func PinnedRedisImage(versions []v2.RedisVersion, deployment *appsv1.Deployment) (string, bool) {
+	pinned, ok := deployment.Annotations[RedisVersionAnnotation]
+	if !ok || len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return "", false
+	}
+	for _, version := range versions {
+		if version.Version == pinned {
+			return version.Image, true
+		}
+	}
+	return deployment.Spec.Template.Spec.Containers[0].Image, true
+}
+
+// setRedisVersion pins the deployment to the version and changes its image.
+func setRedisVersion(deployment *appsv1.Deployment, version v2.RedisVersion) {
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[RedisVersionAnnotation] = version.Version
+	deployment.Spec.Template.Spec.Containers[0].Image = version.Image
+	if deployment.Spec.Template.Labels == nil {
+		deployment.Spec.Template.Labels = map[string]string{}
+	}
+	deployment.Spec.Template.Labels[templates.AppVersion] = templates.ImageVersion(version.Image)
+}
+
+// UpgradeDatabase changes the image of the database to the newer allowed version. The snapshot is saved before
+// the upgrade, the database is rolled back to the previous image if it doesn't become ready with the new one.
+func (adminService *AdministrationService) UpgradeDatabase(ctx context.Context, dbName string, request UpgradeRequest) (*UpgradeResult, error) {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	if request.Version == "" {
+		return nil, customEntity.NewInvalidArgumentError("The version of the upgrade is empty")
+	}
+	target, ok := adminService.redisVersions.byVersion[request.Version]
+	if !ok {
+		return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Unknown Redis version %s, available versions: %s",
+			request.Version, strings.Join(adminService.redisVersions.names(), ", ")))
+	}
+	if !adminService.redisVersions.startUpgrade(dbName) {
+		return nil, customEntity.NewTooManyRequestsError(fmt.Sprintf("Upgrade of database %s is in progress", dbName))
+	}
+	defer adminService.redisVersions.finishUpgrade(dbName)
+
+	deployment := &appsv1.Deployment{}
+	err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: dbName, Namespace: adminService.namespace}, deployment)
+	if errors.IsNotFound(err) || (err == nil && (!usesCredentialsSecret(deployment) || deployment.Labels[templates.PendingDeletion] == "true")) {
+		return nil, customEntity.NewNotFoundError(fmt.Sprintf("Database %s is not found", dbName))
+	}
+	if err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to read deployment of database %s", dbName))
+	}
+	previousVersion, err := adminService.runningRedisVersion(ctx, dbName)
+	if err != nil {
+		return nil, customEntity.NewPreconditionFailedError(fmt.Sprintf("Database %s must be running to be upgraded: %v", dbName, err))
+	}
+	if compareVersions(target.Version, previousVersion) <= 0 {
+		return nil, customEntity.NewPreconditionFailedError(fmt.Sprintf("Version %s is not newer than the running version %s of database %s",
+			target.Version, previousVersion, dbName))
+	}
+
+	dataKept := templates.DataVolumeClaim(deployment) != ""
+	if !dataKept {
+		if !request.Force {
+			return nil, customEntity.NewPreconditionFailedError(fmt.Sprintf("Database %s has no data volume claim, "+
+				"its data is lost after the upgrade, the upgrade must be forced", dbName))
+		}
+		logger.Warn(fmt.Sprintf("Database %s has no data volume claim, it is empty after the forced upgrade", dbName))
+	}
+	if err = adminService.saveSnapshot(ctx, dbName); err != nil {
+		return nil, err
+	}
+	previous := deployment.DeepCopy()
+	waitSeconds := adminService.defaultRedisDbStartWait
+	if request.WaitSeconds > 0 {
+		waitSeconds = request.WaitSeconds
+	}
+
+	logger.Info(fmt.Sprintf("Upgrading database %s from version %s to %s with image %s", dbName, previousVersion, target.Version, target.Image))
+	startedAt := time.Now()
+	if err = adminService.patchObject(ctx, deployment, func() {
+		setRedisVersion(deployment, target)
+	}); err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to update deployment of database %s", dbName))
+	}
+	version, upgradeErr := adminService.waitForRollout(ctx, dbName, waitSeconds, startedAt)
+	if upgradeErr != nil {
+		logger.Warn(fmt.Sprintf("Upgrade of database %s to version %s failed, rolling back", dbName, target.Version), zap.Error(upgradeErr))
+		//the rollback is done even if the request is cancelled
+		if rollbackErr := adminService.rollbackUpgrade(context.WithoutCancel(ctx), previous, waitSeconds); rollbackErr != nil {
+			return nil, customEntity.NewBackendUnavailableError(fmt.Sprintf("Upgrade of database %s to version %s failed: %v, "+
+				"rollback to the previous image failed: %v", dbName, target.Version, upgradeErr, rollbackErr))
+		}
+		return nil, withMessage(upgradeErr, fmt.Sprintf("Upgrade of database %s to version %s is rolled back", dbName, target.Version))
+	}
+	if err = adminService.setVersionLabels(ctx, dbName, version); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to set version labels of database %s", dbName), zap.Error(err))
+	}
+	logger.Info(fmt.Sprintf("Database %s is upgraded from version %s to %s", dbName, previousVersion, version))
+	return &UpgradeResult{Name: dbName, PreviousVersion: previousVersion, Version: version, Image: target.Image, DataKept: dataKept}, nil
+}
+
+// rollbackUpgrade restores the pod template and the pinned version of the deployment before the upgrade and waits
+// for the database to become ready again.
+func (adminService *AdministrationService) rollbackUpgrade(ctx context.Context, previous *appsv1.Deployment, waitSeconds int) error {
+	deployment := &appsv1.Deployment{}
+	deployment.Name = previous.Name
+	startedAt := time.Now()
+	err := adminService.patchObject(ctx, deployment, func() {
+		deployment.Spec.Template = previous.Spec.Template
+		if version, ok := previous.Annotations[RedisVersionAnnotation]; ok {
+			deployment.Annotations[RedisVersionAnnotation] = version
+		} else {
+			delete(deployment.Annotations, RedisVersionAnnotation)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	version, err := adminService.waitForRollout(ctx, previous.Name, waitSeconds, startedAt)
+	if err != nil {
+		return err
+	}
+	return adminService.setVersionLabels(ctx, previous.Name, version)
+}
+
+// waitForRollout waits until all the pods of the database run the current pod template and Redis answers,
+// the running version is returned. The wait fails as soon as the new pod can't start or the request is cancelled.
+func (adminService *AdministrationService) waitForRollout(ctx context.Context, dbName string, waitSeconds int, since time.Time) (string, error) {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	var lastErr error
+	for elapsed := 0; ; elapsed++ {
+		deployment := &appsv1.Deployment{}
+		err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: dbName, Namespace: adminService.namespace}, deployment)
+		switch {
+		case err != nil:
+			lastErr = err
+		case !rolledOut(deployment):
+			lastErr = fmt.Errorf("%d of %d pods are updated and available", deployment.Status.AvailableReplicas, deployment.Status.Replicas)
+		default:
+			version, versionErr := adminService.runningRedisVersion(ctx, dbName)
+			if versionErr == nil {
+				return version, nil
+			}
+			lastErr = versionErr
+		}
+		if elapsed%podCheckPeriodSecond == 0 {
+			if failure := adminService.podStartFailure(ctx, dbName, since); failure != nil {
+				return "", failure
+			}
+		}
+		if elapsed >= waitSeconds {
+			return "", customEntity.NewTimeoutError(fmt.Sprintf("Database %s is not ready in %d seconds: %v", dbName, waitSeconds, lastErr))
+		}
+		logger.Debug(fmt.Sprintf("Waiting for database %s to become ready: %v", dbName, lastErr))
+		select {
+		case <-ctx.Done():
+			return "", customEntity.NewTimeoutError(fmt.Sprintf("Waiting for database %s is cancelled: %v, %v", dbName, ctx.Err(), lastErr))
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// rolledOut is true when the deployment controller has applied the current pod template and only its pods run.
+func rolledOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation && status.UpdatedReplicas == replicas &&
+		status.Replicas == replicas && status.AvailableReplicas == replicas
+}
+
+// runningRedisVersion returns the version of the running database reported by INFO server.
+func (adminService *AdministrationService) runningRedisVersion(ctx context.Context, dbName string) (string, error) {
+	redisdb, err := adminService.connectDatabase(ctx, dbName)
+	if err != nil {
+		return "", err
+	}
+	defer redisdb.Close()
+	info, err := redisdb.Info("server")
+	if err != nil {
+		return "", fmt.Errorf("failed to read INFO server: %v", err)
+	}
+	fields, _ := redis.ParseInfo(info)
+	version := fields["redis_version"]
+	if version == "" {
+		return "", fmt.Errorf("INFO server has no redis_version")
+	}
+	return version, nil
+}
+
+// setVersionLabels sets the version label of the deployment and its pods to the running version. The label
+// of the pod template is kept, changing it would recreate the pods.
+func (adminService *AdministrationService) setVersionLabels(ctx context.Context, dbName string, version string) error {
+	deployment := &appsv1.Deployment{}
+	deployment.Name = dbName
+	if err := adminService.patchObject(ctx, deployment, func() {
+		if deployment.Labels == nil {
+			deployment.Labels = map[string]string{}
+		}
+		deployment.Labels[templates.AppVersion] = version
+	}); err != nil {
+		return err
+	}
+	pods := &v1.PodList{}
+	if err := adminService.kubeClient.List(ctx, pods, client.InNamespace(adminService.namespace), client.MatchingLabels{constants.Name: dbName}); err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Labels[templates.AppVersion] == version || pod.DeletionTimestamp != nil {
+			continue
+		}
+		original := pod.DeepCopy()
+		pod.Labels[templates.AppVersion] = version
+		if err := adminService.kubeClient.Patch(ctx, pod, client.MergeFrom(original)); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncVersionLabels sets the version labels of the database to the running version, e.g. after its image is
+// changed with the Redis image of the CR.
+func (adminService *AdministrationService) syncVersionLabels(ctx context.Context, dbName string) error {
+	version, err := adminService.runningRedisVersion(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	return adminService.setVersionLabels(ctx, dbName, version)
+}
+
+// RunVersionLabelSync periodically sets the version labels of the ready databases to their running versions.
+func (adminService *AdministrationService) RunVersionLabelSync(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(versionLabelSyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			adminService.syncAllVersionLabels(ctx)
+		}
+	}()
+}
+
+func (adminService *AdministrationService) syncAllVersionLabels(ctx context.Context) {
+	deployments := &appsv1.DeploymentList{}
+	err := adminService.kubeClient.List(ctx, deployments, client.InNamespace(adminService.namespace),
+		client.MatchingLabels{adminService.redisLabel: adminService.redisLabel})
+	if err != nil {
+		adminService.logger.Warn("Failed to list databases to sync their version labels", zap.Error(err))
+		return
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if !usesCredentialsSecret(deployment) || !rolledOut(deployment) || adminService.redisVersions.isUpgrading(deployment.Name) {
+			continue
+		}
+		if err = adminService.syncVersionLabels(ctx, deployment.Name); err != nil {
+			adminService.logger.Debug(fmt.Sprintf("Failed to sync version labels of database %s", deployment.Name), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis/mocks"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	testPreviousImage = "redis:7.2.4"
+	testUpgradeImage  = "redis:7.4.1"
+)
+
+// testUpgradeDatabase returns the rolled out database with the data volume claim, its pod and the administration
+// service with the allowed versions 7.2.4 and 7.4.1.
+func testUpgradeDatabase(t *testing.T, podWaiting string) (*AdministrationService, *mocks.RedisClientInterface) {
+	t.Helper()
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	deployment.Spec.Template.Spec.Containers[0].Image = testPreviousImage
+	deployment.Spec.Template.Spec.Volumes = []v1.Volume{{
+		Name:         templates.DataVolumeName("redisdb"),
+		VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "redisdb-data"}},
+	}}
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1, AvailableReplicas: 1}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "redisdb-0", Namespace: testNamespace, Labels: map[string]string{constants.Name: "redisdb"}},
+	}
+	if podWaiting != "" {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+			Name:  "redis",
+			State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: podWaiting}},
+		}}
+	}
+	adminService := newTestAdministrationService(t, secret, deployment, pod)
+	redisVersions, err := newRedisVersions([]v2.RedisVersion{
+		{Version: "7.2.4", Image: testPreviousImage},
+		{Version: "7.4.1", Image: testUpgradeImage},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminService.redisVersions = redisVersions
+	redisClient := mocks.NewRedisClientInterface(t)
+	redisClient.On("InitRedisClient", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+	redisClient.On("Close").Return(nil)
+	adminService.redisClient = redisClient
+	return adminService, redisClient
+}
+
+func serverInfo(version string) string {
+	return "# Server\r\nredis_version:" + version + "\r\n"
+}
+
+func getTestDeployment(t *testing.T, adminService *AdministrationService) *appsv1.Deployment {
+	t.Helper()
+	deployment := &appsv1.Deployment{}
+	if err := adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: "redisdb", Namespace: testNamespace}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	return deployment
+}
+
+func TestUpgradeDatabase(t *testing.T) {
+	adminService, redisClient := testUpgradeDatabase(t, "")
+	redisClient.On("Info", "server").Return(serverInfo("7.2.4"), nil).Once()
+	redisClient.On("Save").Return(nil).Once().Run(func(mock.Arguments) {
+		if image := getTestDeployment(t, adminService).Spec.Template.Spec.Containers[0].Image; image != testPreviousImage {
+			t.Errorf("snapshot is saved with image %s, want it saved before the upgrade", image)
+		}
+	})
+	redisClient.On("Info", "server").Return(serverInfo("7.4.1"), nil)
+
+	result, err := adminService.UpgradeDatabase(context.Background(), "redisdb", UpgradeRequest{Version: "7.4.1", WaitSeconds: 5})
+	if err != nil {
+		t.Fatalf("UpgradeDatabase() error = %v", err)
+	}
+	want := UpgradeResult{Name: "redisdb", PreviousVersion: "7.2.4", Version: "7.4.1", Image: testUpgradeImage, DataKept: true}
+	if *result != want {
+		t.Errorf("UpgradeDatabase() = %+v, want %+v", *result, want)
+	}
+	deployment := getTestDeployment(t, adminService)
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != testUpgradeImage {
+		t.Errorf("image = %s, want %s", image, testUpgradeImage)
+	}
+	if version := deployment.Annotations[RedisVersionAnnotation]; version != "7.4.1" {
+		t.Errorf("pinned version = %s, want 7.4.1", version)
+	}
+	if version := deployment.Labels[templates.AppVersion]; version != "7.4.1" {
+		t.Errorf("version label = %s, want 7.4.1", version)
+	}
+	if adminService.redisVersions.isUpgrading("redisdb") {
+		t.Errorf("database is still upgrading after the upgrade")
+	}
+}
+
+func TestUpgradeDatabaseSnapshotFailed(t *testing.T) {
+	adminService, redisClient := testUpgradeDatabase(t, "")
+	redisClient.On("Info", "server").Return(serverInfo("7.2.4"), nil).Once()
+	redisClient.On("Save").Return(errors.New("MISCONF no space left on device")).Once()
+
+	if _, err := adminService.UpgradeDatabase(context.Background(), "redisdb", UpgradeRequest{Version: "7.4.1"}); err == nil {
+		t.Fatal("UpgradeDatabase() error = nil, want the snapshot error")
+	}
+	deployment := getTestDeployment(t, adminService)
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != testPreviousImage {
+		t.Errorf("image = %s, want the database not upgraded without the snapshot", image)
+	}
+	if _, pinned := deployment.Annotations[RedisVersionAnnotation]; pinned {
+		t.Errorf("database is pinned without the snapshot")
+	}
+}
+
+func TestUpgradeDatabaseRolledBack(t *testing.T) {
+	adminService, redisClient := testUpgradeDatabase(t, "ImagePullBackOff")
+	redisClient.On("Info", "server").Return(serverInfo("7.2.4"), nil).Once()
+	redisClient.On("Save").Return(nil).Once()
+	//the new image doesn't start, the previous one answers after the rollback
+	redisClient.On("Info", "server").Return("", errors.New("connection refused")).Once()
+	redisClient.On("Info", "server").Return(serverInfo("7.2.4"), nil)
+
+	_, err := adminService.UpgradeDatabase(context.Background(), "redisdb", UpgradeRequest{Version: "7.4.1", WaitSeconds: 5})
+	var preconditionFailed *customEntity.PreconditionFailedError
+	if !errors.As(err, &preconditionFailed) {
+		t.Fatalf("UpgradeDatabase() error = %v, want PreconditionFailedError of the failed rollout", err)
+	}
+	deployment := getTestDeployment(t, adminService)
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != testPreviousImage {
+		t.Errorf("image = %s, want the rolled back %s", image, testPreviousImage)
+	}
+	if _, pinned := deployment.Annotations[RedisVersionAnnotation]; pinned {
+		t.Errorf("database is pinned to %s after the rollback", deployment.Annotations[RedisVersionAnnotation])
+	}
+	if version := deployment.Labels[templates.AppVersion]; version != "7.2.4" {
+		t.Errorf("version label = %s, want 7.2.4", version)
+	}
+}
+
+func TestUpgradeDatabaseNotNewer(t *testing.T) {
+	adminService, redisClient := testUpgradeDatabase(t, "")
+	redisClient.On("Info", "server").Return(serverInfo("7.4.1"), nil).Once()
+
+	_, err := adminService.UpgradeDatabase(context.Background(), "redisdb", UpgradeRequest{Version: "7.4.1"})
+	var preconditionFailed *customEntity.PreconditionFailedError
+	if !errors.As(err, &preconditionFailed) {
+		t.Errorf("UpgradeDatabase() error = %v, want PreconditionFailedError of the same version", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "7.2.4", b: "7.2.4", want: 0},
+		{a: "7.2", b: "7.2.4", want: 0},
+		{a: "7.4.1", b: "7.2.4", want: 1},
+		{a: "7.2.4", b: "7.10.0", want: -1},
+		{a: "8", b: "7.4.1", want: 1},
+		{a: "6.2.14", b: "7", want: -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRedisVersionsSelected(t *testing.T) {
+	redisVersions, err := newRedisVersions([]v2.RedisVersion{
+		{Version: "7.2.4", Image: testPreviousImage},
+		{Version: "7.4.1", Image: testUpgradeImage},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		settings    customEntity.DbCreateRequestSettings
+		wantVersion string
+		wantErr     bool
+	}{
+		{name: "Not pinned", settings: customEntity.DbCreateRequestSettings{}},
+		{name: "Version", settings: customEntity.DbCreateRequestSettings{RedisVersion: "7.4.1"}, wantVersion: "7.4.1"},
+		{name: "Image", settings: customEntity.DbCreateRequestSettings{RedisImage: testPreviousImage}, wantVersion: "7.2.4"},
+		{name: "Version and its image", settings: customEntity.DbCreateRequestSettings{RedisVersion: "7.4.1", RedisImage: testUpgradeImage}, wantVersion: "7.4.1"},
+		{name: "Image of another version", settings: customEntity.DbCreateRequestSettings{RedisVersion: "7.4.1", RedisImage: testPreviousImage}, wantErr: true},
+		{name: "Unknown version", settings: customEntity.DbCreateRequestSettings{RedisVersion: "6.2"}, wantErr: true},
+		{name: "Unknown image", settings: customEntity.DbCreateRequestSettings{RedisImage: "redis:6.2"}, wantErr: true},
+		{name: "Unknown image of a version", settings: customEntity.DbCreateRequestSettings{RedisVersion: "7.4.1", RedisImage: "redis:6.2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := redisVersions.selected(&tt.settings)
+			if tt.wantErr {
+				var invalidArgument *customEntity.InvalidArgumentError
+				if !errors.As(err, &invalidArgument) {
+					t.Errorf("selected() error = %v, want InvalidArgumentError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selected() error = %v", err)
+			}
+			var version string
+			if selected != nil {
+				version = selected.Version
+			}
+			if version != tt.wantVersion {
+				t.Errorf("selected() version = %q, want %q", version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestRedisVersionsSelectedNotConfigured(t *testing.T) {
+	redisVersions, _ := newRedisVersions(nil)
+	_, err := redisVersions.selected(&customEntity.DbCreateRequestSettings{RedisVersion: "7.4.1"})
+	var invalidArgument *customEntity.InvalidArgumentError
+	if !errors.As(err, &invalidArgument) {
+		t.Errorf("selected() error = %v, want InvalidArgumentError without the configured versions", err)
+	}
+}
+
+func TestUpgradeDatabaseWithoutDataClaim(t *testing.T) {
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	adminService := newTestAdministrationService(t, secret, deployment)
+	redisVersions, err := newRedisVersions([]v2.RedisVersion{{Version: "7.4.1", Image: "redis:7.4.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminService.redisVersions = redisVersions
+	redisClient := mocks.NewRedisClientInterface(t)
+	redisClient.On("InitRedisClient", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+	redisClient.On("Info", "server").Return("# Server\r\nredis_version:7.2.4\r\n", nil)
+	redisClient.On("Close").Return(nil)
+	adminService.redisClient = redisClient
+
+	_, err = adminService.UpgradeDatabase(context.Background(), "redisdb", UpgradeRequest{Version: "7.4.1"})
+	var preconditionFailed *customEntity.PreconditionFailedError
+	if !errors.As(err, &preconditionFailed) {
+		t.Errorf("UpgradeDatabase() error = %v, want PreconditionFailedError without force", err)
+	}
+}
+
+func TestWaitForRolloutCancelled(t *testing.T) {
+	secret, deployment := testDatabase("redisdb", "app", "service", 0)
+	adminService := newTestAdministrationService(t, secret, deployment)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := adminService.waitForRollout(ctx, "redisdb", 60, deployment.CreationTimestamp.Time)
+	var timeout *customEntity.TimeoutError
+	if !errors.As(err, &timeout) {
+		t.Errorf("waitForRollout() error = %v, want TimeoutError of the cancelled request", err)
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/constants"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// databases are described in parallel, but not all at once
+	describeRuntimeParallelism = 10
+	// an unreachable database doesn't hold the description of the others
+	describeRuntimeTimeout = 5 * time.Second
+)
+
+// LogicalDatabaseRuntimeDescribed is the DescribeDatabases result with the optional runtime section.
+type LogicalDatabaseRuntimeDescribed struct {
+	dao.LogicalDatabaseDescribed
+	Runtime *DatabaseRuntime `json:"runtime,omitempty"`
+}
+
+// DatabaseRuntime tells whether the logical database is healthy and how full it is.
+type DatabaseRuntime struct {
+	Healthy          bool               `json:"healthy"`
+	Pods             []PodRuntime       `json:"pods"`
+	RedisVersion     string             `json:"redisVersion,omitempty"`
+	UptimeSeconds    int64              `json:"uptimeSeconds,omitempty"`
+	Memory           MemoryRuntime      `json:"memory"`
+	Keyspace         map[string]int64   `json:"keyspace,omitempty"`
+	ConnectedClients int64              `json:"connectedClients"`
+	EvictedKeys      int64              `json:"evictedKeys"`
+	Persistence      PersistenceRuntime `json:"persistence"`
+	// LastError is the first found problem: the connection error, the pod failure or the persistence failure
+	LastError string `json:"lastError,omitempty"`
+}
+
+type PodRuntime struct {
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	Ready     bool   `json:"ready"`
+	Restarts  int32  `json:"restarts"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+type MemoryRuntime struct {
+	UsedBytes      int64 `json:"usedBytes"`
+	PeakBytes      int64 `json:"peakBytes"`
+	MaxMemoryBytes int64 `json:"maxMemoryBytes"`
+	// Usage is used memory to maxmemory ratio, it is not set when maxmemory is not limited
+	Usage float64 `json:"usage,omitempty"`
+}
+
+type PersistenceRuntime struct {
+	RDBLastSaveStatus       string `json:"rdbLastSaveStatus,omitempty"`
+	RDBLastSaveTime         int64  `json:"rdbLastSaveTime,omitempty"`
+	RDBChangesSinceLastSave int64  `json:"rdbChangesSinceLastSave"`
+	AOFEnabled              bool   `json:"aofEnabled"`
+	AOFLastWriteStatus      string `json:"aofLastWriteStatus,omitempty"`
+}
+
+// DescribeDatabasesWithRuntime is DescribeLogicalDatabases with the runtime section, it is read from INFO and pod status.
+func (adminService *AdministrationService) DescribeDatabasesWithRuntime(ctx context.Context, logicalDatabases []string,
+	showResources bool, showConnections bool) (map[string]LogicalDatabaseRuntimeDescribed, error) {
+	described, err := adminService.DescribeLogicalDatabases(ctx, logicalDatabases, showResources, showConnections)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]LogicalDatabaseRuntimeDescribed, len(described))
+	mutex := &sync.Mutex{}
+	wg := sync.WaitGroup{}
+	limit := make(chan struct{}, describeRuntimeParallelism)
+	for name, database := range described {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(name string, database dao.LogicalDatabaseDescribed) {
+			defer wg.Done()
+			defer func() { <-limit }()
+			databaseCtx, cancel := context.WithTimeout(ctx, describeRuntimeTimeout)
+			defer cancel()
+			runtime := adminService.getDatabaseRuntime(databaseCtx, name)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			result[name] = LogicalDatabaseRuntimeDescribed{LogicalDatabaseDescribed: database, Runtime: runtime}
+		}(name, database)
+	}
+	wg.Wait()
+	return result, nil
+}
+
+func (adminService *AdministrationService) getDatabaseRuntime(ctx context.Context, dbName string) *DatabaseRuntime {
+	runtime := &DatabaseRuntime{Pods: []PodRuntime{}}
+
+	pods := &v1.PodList{}
+	err := adminService.kubeClient.List(ctx, pods, client.InNamespace(adminService.namespace), client.MatchingLabels{constants.Name: dbName})
+	if err != nil {
+		runtime.LastError = fmt.Sprintf("failed to list pods: %v", err)
+		return runtime
+	}
+	podsReady := false
+	for _, pod := range pods.Items {
+		podRuntime := getPodRuntime(pod)
+		podsReady = podsReady || podRuntime.Ready
+		if runtime.LastError == "" && podRuntime.LastError != "" {
+			runtime.LastError = podRuntime.LastError
+		}
+		runtime.Pods = append(runtime.Pods, podRuntime)
+	}
+
+	infoErr := adminService.readRuntimeInfo(ctx, dbName, runtime)
+	if infoErr != nil {
+		runtime.LastError = infoErr.Error()
+	}
+	persistenceOk := runtime.Persistence.RDBLastSaveStatus != "err" && runtime.Persistence.AOFLastWriteStatus != "err"
+	if runtime.LastError == "" && !persistenceOk {
+		runtime.LastError = fmt.Sprintf("persistence failed, rdb_last_bgsave_status: %s, aof_last_write_status: %s",
+			runtime.Persistence.RDBLastSaveStatus, runtime.Persistence.AOFLastWriteStatus)
+	}
+	runtime.Healthy = podsReady && infoErr == nil && persistenceOk
+	return runtime
+}
+
+// readRuntimeInfo connects to the database the same way as other operations and reads INFO.
+// The Redis client has no context, so INFO is not waited for after the context is done, closing the client stops it.
+func (adminService *AdministrationService) readRuntimeInfo(ctx context.Context, dbName string, runtime *DatabaseRuntime) error {
+	redisdb, err := adminService.connectDatabase(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	defer redisdb.Close()
+
+	type infoResult struct {
+		info string
+		err  error
+	}
+	read := make(chan infoResult, 1)
+	go func() {
+		info, err := redisdb.Info()
+		read <- infoResult{info: info, err: err}
+	}()
+	var info string
+	select {
+	case <-ctx.Done():
+		return customEntity.NewTimeoutError(fmt.Sprintf("failed to read INFO of database %s: %v", dbName, ctx.Err()))
+	case result := <-read:
+		if result.err != nil {
+			return fmt.Errorf("failed to read INFO: %v", result.err)
+		}
+		info = result.info
+	}
+	fields, keyspace := redis.ParseInfo(info)
+	number := func(name string) int64 {
+		value, _ := strconv.ParseInt(fields[name], 10, 64)
+		return value
+	}
+
+	runtime.RedisVersion = fields["redis_version"]
+	runtime.UptimeSeconds = number("uptime_in_seconds")
+	runtime.ConnectedClients = number("connected_clients")
+	runtime.EvictedKeys = number("evicted_keys")
+	runtime.Memory = MemoryRuntime{
+		UsedBytes:      number("used_memory"),
+		PeakBytes:      number("used_memory_peak"),
+		MaxMemoryBytes: number("maxmemory"),
+	}
+	if runtime.Memory.MaxMemoryBytes > 0 {
+		runtime.Memory.Usage = float64(runtime.Memory.UsedBytes) / float64(runtime.Memory.MaxMemoryBytes)
+	}
+	runtime.Keyspace = map[string]int64{}
+	for database, stats := range keyspace {
+		runtime.Keyspace[database] = stats["keys"]
+	}
+	runtime.Persistence = PersistenceRuntime{
+		RDBLastSaveStatus:       fields["rdb_last_bgsave_status"],
+		RDBLastSaveTime:         number("rdb_last_save_time"),
+		RDBChangesSinceLastSave: number("rdb_changes_since_last_save"),
+		AOFEnabled:              fields["aof_enabled"] == "1",
+		AOFLastWriteStatus:      fields["aof_last_write_status"],
+	}
+	return nil
+}
+
+// getPodRuntime returns the phase, readiness and restarts of the pod, the last error is the reason
+// why the container is waiting or was terminated last time, e.g. CrashLoopBackOff or OOMKilled.
+func getPodRuntime(pod v1.Pod) PodRuntime {
+	podRuntime := PodRuntime{Name: pod.Name, Phase: string(pod.Status.Phase)}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			podRuntime.Ready = condition.Status == v1.ConditionTrue
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		podRuntime.Restarts += status.RestartCount
+		switch {
+		case podRuntime.LastError != "":
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "ContainerCreating":
+			podRuntime.LastError = fmt.Sprintf("%s: %s", status.State.Waiting.Reason, status.State.Waiting.Message)
+		case status.LastTerminationState.Terminated != nil:
+			terminated := status.LastTerminationState.Terminated
+			podRuntime.LastError = fmt.Sprintf("%s (exit code %d) at %s", terminated.Reason, terminated.ExitCode, terminated.FinishedAt.UTC())
+		}
+	}
+	if podRuntime.LastError == "" && pod.Status.Phase == v1.PodPending {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+				podRuntime.LastError = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+			}
+		}
+	}
+	return podRuntime
+}
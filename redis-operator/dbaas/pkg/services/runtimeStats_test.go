@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/redis/mocks"
+	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodRuntime(t *testing.T) {
+	finishedAt := metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	ready := []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	tests := []struct {
+		name   string
+		status v1.PodStatus
+		want   PodRuntime
+	}{
+		{
+			name:   "ready",
+			status: v1.PodStatus{Phase: v1.PodRunning, Conditions: ready, ContainerStatuses: []v1.ContainerStatus{{RestartCount: 1}, {RestartCount: 2}}},
+			want:   PodRuntime{Name: "redisdb-0", Phase: "Running", Ready: true, Restarts: 3},
+		},
+		{
+			name: "crash loop",
+			status: v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{
+				RestartCount: 5,
+				State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s"}},
+			}}},
+			want: PodRuntime{Name: "redisdb-0", Phase: "Running", Restarts: 5, LastError: "CrashLoopBackOff: back-off 5m0s"},
+		},
+		{
+			name: "container creating",
+			status: v1.PodStatus{Phase: v1.PodPending, ContainerStatuses: []v1.ContainerStatus{{
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			}}},
+			want: PodRuntime{Name: "redisdb-0", Phase: "Pending"},
+		},
+		{
+			name: "last termination",
+			status: v1.PodStatus{Phase: v1.PodRunning, Conditions: ready, ContainerStatuses: []v1.ContainerStatus{{
+				RestartCount:         1,
+				LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137, FinishedAt: finishedAt}},
+			}}},
+			want: PodRuntime{Name: "redisdb-0", Phase: "Running", Ready: true, Restarts: 1,
+				LastError: "OOMKilled (exit code 137) at 2024-05-01 10:00:00 +0000 UTC"},
+		},
+		{
+			name: "not scheduled",
+			status: v1.PodStatus{Phase: v1.PodPending, Conditions: []v1.PodCondition{
+				{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: "Unschedulable", Message: "0/3 nodes are available"},
+			}},
+			want: PodRuntime{Name: "redisdb-0", Phase: "Pending", LastError: "Unschedulable: 0/3 nodes are available"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redisdb-0"}, Status: tt.status}
+			if got := getPodRuntime(pod); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPodRuntime() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRuntimeInfo(t *testing.T) {
+	info := "# Server\r\nredis_version:7.2.4\r\nuptime_in_seconds:3600\r\n" +
+		"# Clients\r\nconnected_clients:12\r\n" +
+		"# Memory\r\nused_memory:256\r\nused_memory_peak:512\r\nmaxmemory:1024\r\n" +
+		"# Persistence\r\nrdb_changes_since_last_save:7\r\nrdb_last_save_time:1714557600\r\nrdb_last_bgsave_status:ok\r\n" +
+		"aof_enabled:1\r\naof_last_write_status:err\r\n" +
+		"# Stats\r\nevicted_keys:3\r\n" +
+		"# Keyspace\r\ndb0:keys=42,expires=2,avg_ttl=0\r\n"
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	adminService := newTestAdministrationService(t, secret, deployment)
+	redisClient := mocks.NewRedisClientInterface(t)
+	redisClient.On("InitRedisClient", "redisdb.redis:6379", "password", mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+	redisClient.On("Info").Return(info, nil)
+	redisClient.On("Close").Return(nil)
+	adminService.redisClient = redisClient
+
+	runtime := &DatabaseRuntime{}
+	if err := adminService.readRuntimeInfo(context.Background(), "redisdb", runtime); err != nil {
+		t.Fatal(err)
+	}
+	want := &DatabaseRuntime{
+		RedisVersion:     "7.2.4",
+		UptimeSeconds:    3600,
+		ConnectedClients: 12,
+		EvictedKeys:      3,
+		Memory:           MemoryRuntime{UsedBytes: 256, PeakBytes: 512, MaxMemoryBytes: 1024, Usage: 0.25},
+		Keyspace:         map[string]int64{"db0": 42},
+		Persistence: PersistenceRuntime{RDBLastSaveStatus: "ok", RDBLastSaveTime: 1714557600, RDBChangesSinceLastSave: 7,
+			AOFEnabled: true, AOFLastWriteStatus: "err"},
+	}
+	if !reflect.DeepEqual(runtime, want) {
+		t.Errorf("readRuntimeInfo() = %+v, want %+v", runtime, want)
+	}
+}
+
+func TestReadRuntimeInfoErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		mock    func(redisClient *mocks.RedisClientInterface)
+		check   func(err error) bool
+	}{
+		{
+			name: "INFO failed",
+			mock: func(redisClient *mocks.RedisClientInterface) {
+				redisClient.On("Info").Return("", errors.New("connection refused"))
+			},
+			check: func(err error) bool {
+				return err != nil && strings.Contains(err.Error(), "connection refused")
+			},
+		},
+		{
+			name:    "INFO timed out",
+			timeout: 10 * time.Millisecond,
+			mock: func(redisClient *mocks.RedisClientInterface) {
+				redisClient.On("Info").After(300*time.Millisecond).Return("", errors.New("closed")).Maybe()
+			},
+			check: func(err error) bool {
+				var timeout *customEntity.TimeoutError
+				return errors.As(err, &timeout)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, deployment := testDatabase("redisdb", "app", "service", 1)
+			adminService := newTestAdministrationService(t, secret, deployment)
+			redisClient := mocks.NewRedisClientInterface(t)
+			redisClient.On("InitRedisClient", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(redisClient)
+			redisClient.On("Close").Return(nil)
+			tt.mock(redisClient)
+			adminService.redisClient = redisClient
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			err := adminService.readRuntimeInfo(ctx, "redisdb", &DatabaseRuntime{})
+			if !tt.check(err) {
+				t.Errorf("readRuntimeInfo() error = %v", err)
+			}
+			if time.Since(start) > 200*time.Millisecond {
+				t.Errorf("readRuntimeInfo() took %v, it must not wait for INFO after the timeout", time.Since(start))
+			}
+		})
+	}
+}
+
+func TestReadRuntimeInfoWithoutCredentials(t *testing.T) {
+	_, deployment := testDatabase("redisdb", "app", "service", 1)
+	adminService := newTestAdministrationService(t, deployment)
+
+	err := adminService.readRuntimeInfo(context.Background(), "redisdb", &DatabaseRuntime{})
+	var notFound *customEntity.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("readRuntimeInfo() error = %v, want NotFoundError of the credentials secret", err)
+	}
+}
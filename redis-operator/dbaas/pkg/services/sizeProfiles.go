@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/config"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	PersistenceRDB    = "rdb"
+	PersistenceAOF    = "aof"
+	PersistenceRDBAOF = "rdb-aof"
+	PersistenceNone   = "none"
+
+	// ProfileAnnotation keeps the size profile the database is created with
+	ProfileAnnotation = "netcracker.com/profile"
+)
+
+// sizeProfiles are the named settings of logical databases from the DbaasRedisAdapter spec.
+type sizeProfiles struct {
+	profiles       map[string]v2.SizeProfile
+	ordered        []v2.SizeProfile
+	defaultProfile string
+}
+
+func newSizeProfiles(profiles []v2.SizeProfile, defaultProfile string) (sizeProfiles, error) {
+	result := sizeProfiles{profiles: map[string]v2.SizeProfile{}, defaultProfile: defaultProfile}
+	for _, profile := range profiles {
+		if profile.Name == "" {
+			return result, fmt.Errorf("profile name is empty")
+		}
+		if _, ok := result.profiles[profile.Name]; ok {
+			return result, fmt.Errorf("profile %s is defined twice", profile.Name)
+		}
+		directives, err := profileDirectives(profile)
+		if err != nil {
+			return result, fmt.Errorf("profile %s is invalid: %v", profile.Name, err)
+		}
+		redisConfig, _ := config.Parse("")
+		if err = redisConfig.Merge(directives); err == nil {
+			err = redisConfig.Validate()
+		}
+		if err == nil && profile.Resources != nil {
+			err = checkDatabaseMaxmemory(directives, *profile.Resources)
+		}
+		if err != nil {
+			return result, fmt.Errorf("profile %s is invalid: %v", profile.Name, err)
+		}
+		result.profiles[profile.Name] = profile
+	}
+	if defaultProfile != "" {
+		if _, ok := result.profiles[defaultProfile]; !ok {
+			return result, fmt.Errorf("default profile %s is not defined", defaultProfile)
+		}
+	}
+	result.ordered = append(result.ordered, profiles...)
+	return result, nil
+}
+
+func (p sizeProfiles) configured() bool {
+	return len(p.profiles) > 0
+}
+
+func (p sizeProfiles) names() []string {
+	var names []string
+	for name := range p.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selected returns the profile named by the profile setting of the request or the default profile,
+// nil is returned if neither is set.
+func (p sizeProfiles) selected(requestSettings map[string]interface{}) (*v2.SizeProfile, error) {
+	name := p.defaultProfile
+	if value, ok := requestSettings["profile"]; ok && value != nil {
+		requested, isString := value.(string)
+		if !isString {
+			return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("The profile must be a string, got %v", value))
+		}
+		if requested != "" {
+			name = requested
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := p.profiles[name]
+	if !ok {
+		return nil, customEntity.NewInvalidArgumentError(fmt.Sprintf("Unknown profile %s, available profiles: %s", name, strings.Join(p.names(), ", ")))
+	}
+	return &profile, nil
+}
+
+// applyProfile sets the fields of the profile as the defaults of the request settings, the request overrides them.
+func applyProfile(settings *customEntity.DbCreateRequestSettings, profile *v2.SizeProfile) error {
+	if profile.Resources != nil {
+		settings.RedisDbResources.Limits = mergeResourceList(settings.RedisDbResources.Limits, profile.Resources.Limits)
+		settings.RedisDbResources.Requests = mergeResourceList(settings.RedisDbResources.Requests, profile.Resources.Requests)
+	}
+	if settings.RedisDbNodeSelector == nil {
+		settings.RedisDbNodeSelector = map[string]string{}
+	}
+	for key, value := range profile.NodeSelector {
+		settings.RedisDbNodeSelector[key] = value
+	}
+	settings.RedisDbTolerations = append([]v1.Toleration{}, profile.Tolerations...)
+	directives, err := profileDirectives(*profile)
+	if err != nil {
+		return err
+	}
+	settings.RedisDbSettings = directives
+	settings.Profile = profile.Name
+	return nil
+}
+
+// profileDirectives returns the Redis configuration of the profile.
+func profileDirectives(profile v2.SizeProfile) (map[string]interface{}, error) {
+	directives := map[string]interface{}{}
+	for name, value := range profile.Settings {
+		directives[name] = value
+	}
+	switch profile.Persistence {
+	case "":
+	case PersistenceRDB:
+		directives["appendonly"] = "no"
+	case PersistenceAOF:
+		directives["save"] = `""`
+		directives["appendonly"] = "yes"
+	case PersistenceRDBAOF:
+		directives["appendonly"] = "yes"
+	case PersistenceNone:
+		directives["save"] = `""`
+		directives["appendonly"] = "no"
+	default:
+		return nil, fmt.Errorf("unknown persistence %s", profile.Persistence)
+	}
+	if profile.Maxmemory != "" {
+		directives["maxmemory"] = profile.Maxmemory
+	}
+	if profile.MaxmemoryPolicy != "" {
+		directives["maxmemory-policy"] = profile.MaxmemoryPolicy
+	}
+	return directives, nil
+}
+
+func mergeResourceList(base v1.ResourceList, overrides v1.ResourceList) v1.ResourceList {
+	if len(overrides) == 0 {
+		return base
+	}
+	result := v1.ResourceList{}
+	for name, quantity := range base {
+		result[name] = quantity
+	}
+	for name, quantity := range overrides {
+		result[name] = quantity
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestNewSizeProfiles(t *testing.T) {
+	limits := &v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("256Mi")}}
+	tests := []struct {
+		name           string
+		profiles       []v2.SizeProfile
+		defaultProfile string
+		wantErr        bool
+	}{
+		{name: "No profiles"},
+		{name: "Valid profiles", profiles: []v2.SizeProfile{{Name: "small", Resources: limits, Maxmemory: "200mb"}, {Name: "large"}}, defaultProfile: "small"},
+		{name: "Empty name", profiles: []v2.SizeProfile{{}}, wantErr: true},
+		{name: "Defined twice", profiles: []v2.SizeProfile{{Name: "small"}, {Name: "small"}}, wantErr: true},
+		{name: "Unknown persistence", profiles: []v2.SizeProfile{{Name: "small", Persistence: "disk"}}, wantErr: true},
+		{name: "Invalid setting", profiles: []v2.SizeProfile{{Name: "small", MaxmemoryPolicy: "unknown"}}, wantErr: true},
+		{name: "Maxmemory above the limit", profiles: []v2.SizeProfile{{Name: "small", Resources: limits, Maxmemory: "1gb"}}, wantErr: true},
+		{name: "Unknown default profile", profiles: []v2.SizeProfile{{Name: "small"}}, defaultProfile: "large", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSizeProfiles(tt.profiles, tt.defaultProfile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newSizeProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertSettingsWithProfile(t *testing.T) {
+	toleration := v1.Toleration{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "redis", Effect: v1.TaintEffectNoSchedule}
+	profiles, err := newSizeProfiles([]v2.SizeProfile{{
+		Name: "small",
+		Resources: &v1.ResourceRequirements{
+			Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("256Mi")},
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("50m")},
+		},
+		Maxmemory:    "200mb",
+		Persistence:  PersistenceNone,
+		NodeSelector: map[string]string{"pool": "small"},
+		Tolerations:  []v1.Toleration{toleration},
+		Settings:     map[string]string{"timeout": "300"},
+	}}, "small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name             string
+		requestSettings  map[string]interface{}
+		wantLimits       v1.ResourceList
+		wantNodeSelector map[string]string
+		wantTolerations  []v1.Toleration
+		wantDirectives   map[string]interface{}
+	}{
+		{
+			name: "Default profile",
+			wantLimits: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("256Mi"),
+				v1.ResourceCPU:    resource.MustParse("500m"),
+			},
+			wantNodeSelector: map[string]string{"zone": "a", "pool": "small"},
+			wantTolerations:  []v1.Toleration{toleration},
+			wantDirectives:   map[string]interface{}{"maxmemory": "200mb", "save": `""`, "appendonly": "no", "timeout": "300"},
+		},
+		{
+			name: "Request overrides the profile",
+			requestSettings: map[string]interface{}{
+				"profile":             "small",
+				"redisDbResources":    map[string]interface{}{"limits": map[string]interface{}{"memory": "512Mi"}},
+				"redisDbNodeSelector": map[string]interface{}{"pool": "custom"},
+				"redisDbTolerations":  []interface{}{},
+				"redisDbSettings":     map[string]interface{}{"maxmemory": "400mb"},
+			},
+			wantLimits: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("512Mi"),
+				v1.ResourceCPU:    resource.MustParse("500m"),
+			},
+			wantNodeSelector: map[string]string{"zone": "a", "pool": "custom"},
+			wantTolerations:  []v1.Toleration{},
+			wantDirectives:   map[string]interface{}{"maxmemory": "400mb", "save": `""`, "appendonly": "no", "timeout": "300"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := newTestAdministrationService(t)
+			adminService.sizeProfiles = profiles
+			adminService.redisResources = v1.ResourceRequirements{Limits: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+				v1.ResourceCPU:    resource.MustParse("500m"),
+			}}
+			adminService.nodeSelector = map[string]string{"zone": "a"}
+
+			settings, err := adminService.convertSettings(tt.requestSettings)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if settings.Profile != "small" {
+				t.Errorf("convertSettings() profile = %s, want small", settings.Profile)
+			}
+			if !quantitiesEqual(settings.RedisDbResources.Limits, tt.wantLimits) {
+				t.Errorf("convertSettings() limits = %v, want %v", settings.RedisDbResources.Limits, tt.wantLimits)
+			}
+			if cpu := settings.RedisDbResources.Requests[v1.ResourceCPU]; cpu.String() != "50m" {
+				t.Errorf("convertSettings() CPU request = %s, want 50m of the profile", cpu.String())
+			}
+			if !reflect.DeepEqual(settings.RedisDbNodeSelector, tt.wantNodeSelector) {
+				t.Errorf("convertSettings() node selector = %v, want %v", settings.RedisDbNodeSelector, tt.wantNodeSelector)
+			}
+			if !reflect.DeepEqual(settings.RedisDbTolerations, tt.wantTolerations) {
+				t.Errorf("convertSettings() tolerations = %v, want %v", settings.RedisDbTolerations, tt.wantTolerations)
+			}
+			if !reflect.DeepEqual(settings.RedisDbSettings, tt.wantDirectives) {
+				t.Errorf("convertSettings() directives = %v, want %v", settings.RedisDbSettings, tt.wantDirectives)
+			}
+		})
+	}
+}
+
+func TestSelectedProfile(t *testing.T) {
+	profiles, err := newSizeProfiles([]v2.SizeProfile{{Name: "small"}, {Name: "large"}}, "small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name            string
+		requestSettings map[string]interface{}
+		want            string
+		wantInvalid     bool
+	}{
+		{name: "Default profile", want: "small"},
+		{name: "Empty profile", requestSettings: map[string]interface{}{"profile": ""}, want: "small"},
+		{name: "Requested profile", requestSettings: map[string]interface{}{"profile": "large"}, want: "large"},
+		{name: "Unknown profile", requestSettings: map[string]interface{}{"profile": "huge"}, wantInvalid: true},
+		{name: "Not a string", requestSettings: map[string]interface{}{"profile": 1}, wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := profiles.selected(tt.requestSettings)
+			if _, invalid := err.(*customEntity.InvalidArgumentError); invalid != tt.wantInvalid {
+				t.Fatalf("selected() error = %v, want InvalidArgumentError %t", err, tt.wantInvalid)
+			}
+			if !tt.wantInvalid && (profile == nil || profile.Name != tt.want) {
+				t.Errorf("selected() = %v, want %s", profile, tt.want)
+			}
+		})
+	}
+}
+
+func quantitiesEqual(got, want v1.ResourceList) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for name, quantity := range want {
+		if gotQuantity, ok := got[name]; !ok || gotQuantity.Cmp(quantity) != 0 {
+			return false
+		}
+	}
+	return true
+}
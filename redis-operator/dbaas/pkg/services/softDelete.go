@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
+	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/utils"
+	"github.com/Netcracker/qubership-nosqldb-operator-core/pkg/core"
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultSoftDeleteRetention = 72 * time.Hour
+	defaultDataStorageSize     = "1Gi"
+	softDeletePurgeInterval    = 10 * time.Minute
+
+	// DeleteAfterAnnotation is the time when the soft deleted database is purged
+	DeleteAfterAnnotation = "netcracker.com/delete-after"
+	// ReplicasAnnotation keeps the replicas of the soft deleted Deployment to restore them
+	ReplicasAnnotation = "netcracker.com/replicas-before-delete"
+)
+
+type softDeleteConfig struct {
+	enabled      bool
+	retention    time.Duration
+	storageClass string
+	storageSize  resource.Quantity
+}
+
+// DeletedDatabase is a soft deleted logical database which can be restored until DeleteAfter.
+type DeletedDatabase struct {
+	Name        string      `json:"name"`
+	DeleteAfter metav1.Time `json:"deleteAfter"`
+	// DataKept is true if the data is kept in the data volume claim
+	DataKept bool `json:"dataKept"`
+}
+
+func newSoftDeleteConfig(spec *v2.SoftDelete) (softDeleteConfig, error) {
+	config := softDeleteConfig{retention: defaultSoftDeleteRetention, storageSize: resource.MustParse(defaultDataStorageSize)}
+	if spec == nil || !spec.Enabled {
+		return config, nil
+	}
+	config.enabled = true
+	config.storageClass = spec.StorageClass
+	var err error
+	if config.retention, err = parseDurationOrDefault(spec.Retention, defaultSoftDeleteRetention); err != nil {
+		return config, fmt.Errorf("invalid soft delete retention: %v", err)
+	}
+	if spec.StorageSize != "" {
+		if config.storageSize, err = resource.ParseQuantity(spec.StorageSize); err != nil {
+			return config, fmt.Errorf("invalid soft delete storage size: %v", err)
+		}
+	}
+	return config, nil
+}
+
+// SoftDeleteEnabled tells whether the dropped databases are kept for the retention period.
+func (adminService *AdministrationService) SoftDeleteEnabled() bool {
+	return adminService.softDelete.enabled
+}
+
+// dropDatabasesSoftly keeps the databases of the resources which have a Deployment: the final RDB snapshot
+// is saved to the data volume, the Deployment is scaled to zero and all the objects are marked as pending deletion.
+// The resources of the databases without a Deployment are returned to be deleted.
+func (adminService *AdministrationService) dropDatabasesSoftly(ctx context.Context, resources []dao.DbResource) (kept []dao.DbResource, toDelete []dao.DbResource) {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	results := map[string]error{}
+	for _, resource := range resources {
+		dbName := strings.TrimSuffix(resource.Name, credsSuffix)
+		err, processed := results[dbName]
+		if !processed {
+			err = adminService.softDeleteDatabase(ctx, dbName)
+			results[dbName] = err
+		}
+		if errors.IsNotFound(err) {
+			toDelete = append(toDelete, resource)
+			continue
+		}
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to soft delete %s %s", resource.Kind, resource.Name), zap.Error(err))
+			resource.Status = dao.DELETE_FAILED
+			resource.ErrorMessage = err.Error()
+		} else {
+			resource.Status = dao.DELETED
+		}
+		kept = append(kept, resource)
+	}
+	return kept, toDelete
+}
+
+func (adminService *AdministrationService) softDeleteDatabase(ctx context.Context, dbName string) error {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	deployment := &appsv1.Deployment{}
+	err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: dbName, Namespace: adminService.namespace}, deployment)
+	if err != nil {
+		return err
+	}
+	if deployment.Labels[templates.PendingDeletion] == "true" {
+		return nil
+	}
+
+	if templates.DataVolumeClaim(deployment) != "" && deployment.Status.ReadyReplicas > 0 {
+		if saveErr := adminService.saveSnapshot(ctx, dbName); saveErr != nil {
+			logger.Warn(fmt.Sprintf("Failed to save the final snapshot of %s, the last saved one is kept", dbName), zap.Error(saveErr))
+		}
+	} else {
+		logger.Warn(fmt.Sprintf("Database %s has no data volume claim, only its credentials and configuration are kept", dbName))
+	}
+
+	deleteAfter := time.Now().Add(adminService.softDelete.retention).UTC().Format(time.RFC3339)
+	for _, object := range adminService.databaseObjects(dbName) {
+		if _, ok := object.(*appsv1.Deployment); ok {
+			continue
+		}
+		if err = adminService.patchObject(ctx, object, func() {
+			setPendingDeletion(object, deleteAfter)
+		}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	err = adminService.patchObject(ctx, deployment, func() {
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		setPendingDeletion(deployment, deleteAfter)
+		deployment.Annotations[ReplicasAnnotation] = strconv.Itoa(int(replicas))
+		//the database disappears from the inventory of the adapter
+		delete(deployment.Labels, adminService.redisLabel)
+		zero := int32(0)
+		deployment.Spec.Replicas = &zero
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Database %s is soft deleted, it can be restored until %s", dbName, deleteAfter))
+	return nil
+}
+
+func (adminService *AdministrationService) saveSnapshot(ctx context.Context, dbName string) error {
+	redisdb, err := adminService.connectDatabase(ctx, dbName)
+	if err != nil {
+		return err
+	}
+	defer redisdb.Close()
+	return typedError(redisdb.Save(), fmt.Sprintf("failed to save snapshot of %s", dbName))
+}
+
+// RestoreDatabase brings back the soft deleted database with its data and credentials.
+func (adminService *AdministrationService) RestoreDatabase(ctx context.Context, dbName string) (*dao.LogicalDatabaseDescribed, error) {
+	logger := utils.AddLoggerContext(adminService.logger, ctx)
+	deployment := &appsv1.Deployment{}
+	err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: dbName, Namespace: adminService.namespace}, deployment)
+	if errors.IsNotFound(err) {
+		return nil, customEntity.NewNotFoundError(fmt.Sprintf("Database %s is not found", dbName))
+	}
+	if err != nil {
+		return nil, typedError(err, fmt.Sprintf("failed to read deployment of database %s", dbName))
+	}
+	if deployment.Labels[templates.PendingDeletion] != "true" {
+		return nil, customEntity.NewResourceAlreadyExistsError(fmt.Sprintf("Database %s is not deleted", dbName))
+	}
+	//the database is purged by the next run of the purge, the same as the databases with an invalid annotation
+	deleteAfter, parseErr := time.Parse(time.RFC3339, deployment.Annotations[DeleteAfterAnnotation])
+	if parseErr != nil || !time.Now().Before(deleteAfter) {
+		return nil, customEntity.NewNotFoundError(fmt.Sprintf("Database %s is not found, its retention has expired", dbName))
+	}
+	password, err := adminService.getRedisDBPassword(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+	var resources v1.ResourceRequirements
+	if len(deployment.Spec.Template.Spec.Containers) > 0 {
+		resources = deployment.Spec.Template.Spec.Containers[0].Resources
+	}
+	releaseQuotas, err := adminService.checkQuotas(ctx, dbName, deployment.Labels, resources)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseQuotas()
+
+	for _, object := range adminService.databaseObjects(dbName) {
+		if _, ok := object.(*appsv1.Deployment); ok {
+			continue
+		}
+		if err = adminService.patchObject(ctx, object, func() {
+			clearPendingDeletion(object)
+		}); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	err = adminService.patchObject(ctx, deployment, func() {
+		replicas, parseErr := strconv.Atoi(deployment.Annotations[ReplicasAnnotation])
+		if parseErr != nil || replicas < 1 {
+			replicas = 1
+		}
+		restored := int32(replicas)
+		deployment.Spec.Replicas = &restored
+		delete(deployment.Annotations, ReplicasAnnotation)
+		clearPendingDeletion(deployment)
+		deployment.Labels[adminService.redisLabel] = adminService.redisLabel
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Database %s is restored", dbName))
+	adminService.notifyInventoryChanged()
+
+	return &dao.LogicalDatabaseDescribed{
+		ConnectionProperties: createConnectionProperties(dbName, password, adminService.namespace, adminService.redisServicePort),
+		Resources:            adminService.getDBResources(dbName),
+	}, nil
+}
+
+// GetDeletedDatabases returns the soft deleted databases which are not purged yet.
+func (adminService *AdministrationService) GetDeletedDatabases(ctx context.Context) ([]DeletedDatabase, error) {
+	deployments, err := adminService.listDeletedDeployments(ctx)
+	if err != nil {
+		return nil, typedError(err, "failed to list deleted databases")
+	}
+	result := []DeletedDatabase{}
+	for _, deployment := range deployments.Items {
+		deleteAfter, _ := time.Parse(time.RFC3339, deployment.Annotations[DeleteAfterAnnotation])
+		result = append(result, DeletedDatabase{
+			Name:        deployment.Name,
+			DeleteAfter: metav1.NewTime(deleteAfter),
+			DataKept:    templates.DataVolumeClaim(&deployment) != "",
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (adminService *AdministrationService) listDeletedDeployments(ctx context.Context) (*appsv1.DeploymentList, error) {
+	deployments := &appsv1.DeploymentList{}
+	err := adminService.kubeClient.List(ctx, deployments, client.InNamespace(adminService.namespace),
+		client.MatchingLabels{templates.PendingDeletion: "true"})
+	return deployments, err
+}
+
+// RunSoftDeletePurge periodically deletes the soft deleted databases whose retention has expired.
+func (adminService *AdministrationService) RunSoftDeletePurge(ctx context.Context) {
+	if !adminService.softDelete.enabled {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(softDeletePurgeInterval)
+		defer ticker.Stop()
+		for {
+			adminService.purgeDeletedDatabases(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (adminService *AdministrationService) purgeDeletedDatabases(ctx context.Context) {
+	deployments, err := adminService.listDeletedDeployments(ctx)
+	if err != nil {
+		adminService.logger.Warn("Failed to list soft deleted databases", zap.Error(err))
+		return
+	}
+	for _, deployment := range deployments.Items {
+		deleteAfter, parseErr := time.Parse(time.RFC3339, deployment.Annotations[DeleteAfterAnnotation])
+		if parseErr == nil && time.Now().Before(deleteAfter) {
+			continue
+		}
+		for _, object := range adminService.databaseObjects(deployment.Name) {
+			if err = core.DeleteRuntimeObject(adminService.kubeClient, object); err != nil {
+				adminService.logger.Warn(fmt.Sprintf("Failed to purge %s of soft deleted database %s", object.GetName(), deployment.Name), zap.Error(err))
+			}
+		}
+		adminService.logger.Info(fmt.Sprintf("Soft deleted database %s is purged", deployment.Name))
+	}
+}
+
+// databaseObjects are the objects dropped with the logical database and its data volume claim.
+func (adminService *AdministrationService) databaseObjects(dbName string) []client.Object {
+	var objects []client.Object
+	for _, kind := range []string{"Secret", "ConfigMap", "Service", "Deployment"} {
+		objects = append(objects, adminService.getResourcesMapping(dbName)[kind].object)
+	}
+	return append(objects, &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: templates.DataVolumeName(dbName), Namespace: adminService.namespace},
+	})
+}
+
+// patchObject reads the object, changes it and patches the difference.
+func (adminService *AdministrationService) patchObject(ctx context.Context, object client.Object, change func()) error {
+	err := adminService.kubeClient.Get(ctx, types.NamespacedName{Name: object.GetName(), Namespace: adminService.namespace}, object)
+	if err != nil {
+		return err
+	}
+	original := object.DeepCopyObject().(client.Object)
+	change()
+	return adminService.kubeClient.Patch(ctx, object, client.MergeFrom(original))
+}
+
+func setPendingDeletion(object client.Object, deleteAfter string) {
+	labels := object.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[templates.PendingDeletion] = "true"
+	object.SetLabels(labels)
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[DeleteAfterAnnotation] = deleteAfter
+	object.SetAnnotations(annotations)
+}
+
+func clearPendingDeletion(object client.Object) {
+	labels := object.GetLabels()
+	delete(labels, templates.PendingDeletion)
+	object.SetLabels(labels)
+	annotations := object.GetAnnotations()
+	delete(annotations, DeleteAfterAnnotation)
+	object.SetAnnotations(annotations)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v2 "github.com/Netcracker/qubership-redis/redis-operator/api/v2"
+	customEntity "github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/entity"
+	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/templates"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deletedDatabase returns the objects of the database soft deleted with 2 replicas, it is purged after deleteAfter.
+func deletedDatabase(name string, deleteAfter time.Time) (*v1.Secret, *appsv1.Deployment) {
+	secret, deployment := testDatabase(name, "app", "service", 0)
+	setPendingDeletion(secret, deleteAfter.UTC().Format(time.RFC3339))
+	setPendingDeletion(deployment, deleteAfter.UTC().Format(time.RFC3339))
+	deployment.Annotations[ReplicasAnnotation] = "2"
+	delete(deployment.Labels, testLabel)
+	zero := int32(0)
+	deployment.Spec.Replicas = &zero
+	return secret, deployment
+}
+
+func TestRestoreDatabase(t *testing.T) {
+	tests := []struct {
+		name      string
+		objects   func() []client.Object
+		wantError interface{}
+	}{
+		{
+			name: "Before retention",
+			objects: func() []client.Object {
+				secret, deployment := deletedDatabase("redisdb", time.Now().Add(time.Hour))
+				return []client.Object{secret, deployment}
+			},
+		},
+		{
+			name: "After retention",
+			objects: func() []client.Object {
+				secret, deployment := deletedDatabase("redisdb", time.Now().Add(-time.Minute))
+				return []client.Object{secret, deployment}
+			},
+			wantError: &customEntity.NotFoundError{},
+		},
+		{
+			name: "Invalid retention",
+			objects: func() []client.Object {
+				secret, deployment := deletedDatabase("redisdb", time.Now().Add(time.Hour))
+				deployment.Annotations[DeleteAfterAnnotation] = "tomorrow"
+				return []client.Object{secret, deployment}
+			},
+			wantError: &customEntity.NotFoundError{},
+		},
+		{
+			name: "Not deleted",
+			objects: func() []client.Object {
+				secret, deployment := testDatabase("redisdb", "app", "service", 1)
+				return []client.Object{secret, deployment}
+			},
+			wantError: &customEntity.ResourceAlreadyExistsError{},
+		},
+		{
+			name:      "Purged",
+			objects:   func() []client.Object { return nil },
+			wantError: &customEntity.NotFoundError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := newTestAdministrationService(t, tt.objects()...)
+			described, err := adminService.RestoreDatabase(context.Background(), "redisdb")
+			if tt.wantError != nil {
+				if err == nil || reflect.TypeOf(err) != reflect.TypeOf(tt.wantError) {
+					t.Errorf("RestoreDatabase() error = %v, want %T", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RestoreDatabase() error = %v", err)
+			}
+			if len(described.ConnectionProperties) != 1 || described.ConnectionProperties[0]["password"] != "password" {
+				t.Errorf("RestoreDatabase() = %+v, want the connection properties with the password of the database", described)
+			}
+
+			deployment := &appsv1.Deployment{}
+			if err = adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: "redisdb", Namespace: testNamespace}, deployment); err != nil {
+				t.Fatal(err)
+			}
+			if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas != 2 {
+				t.Errorf("restored replicas = %v, want 2 replicas before the deletion", deployment.Spec.Replicas)
+			}
+			if deployment.Labels[testLabel] != testLabel || deployment.Labels[templates.PendingDeletion] != "" ||
+				deployment.Annotations[DeleteAfterAnnotation] != "" || deployment.Annotations[ReplicasAnnotation] != "" {
+				t.Errorf("restored Deployment labels = %v, annotations = %v, want the deletion marks removed", deployment.Labels, deployment.Annotations)
+			}
+			secret := &v1.Secret{}
+			if err = adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: credsName("redisdb"), Namespace: testNamespace}, secret); err != nil {
+				t.Fatal(err)
+			}
+			if secret.Labels[templates.PendingDeletion] != "" || secret.Annotations[DeleteAfterAnnotation] != "" {
+				t.Errorf("restored Secret labels = %v, annotations = %v, want the deletion marks removed", secret.Labels, secret.Annotations)
+			}
+		})
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	secret, deployment := testDatabase("redisdb", "app", "service", 1)
+	replicas := int32(3)
+	deployment.Spec.Replicas = &replicas
+	adminService := newTestAdministrationService(t, secret, deployment)
+	softDelete, err := newSoftDeleteConfig(&v2.SoftDelete{Enabled: true, Retention: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminService.softDelete = softDelete
+
+	//the database has no data volume claim, so the snapshot isn't saved
+	if err = adminService.softDeleteDatabase(context.Background(), "redisdb"); err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := adminService.GetDeletedDatabases(context.Background())
+	if err != nil || len(deleted) != 1 || deleted[0].Name != "redisdb" || deleted[0].DataKept {
+		t.Fatalf("GetDeletedDatabases() = %v, %v, want redisdb without the data", deleted, err)
+	}
+	if until := time.Until(deleted[0].DeleteAfter.Time); until <= 0 || until > time.Hour {
+		t.Errorf("GetDeletedDatabases() deleteAfter = %v, want in the retention of 1h", deleted[0].DeleteAfter)
+	}
+
+	if _, err = adminService.RestoreDatabase(context.Background(), "redisdb"); err != nil {
+		t.Fatal(err)
+	}
+	restored := &appsv1.Deployment{}
+	if err = adminService.kubeClient.Get(context.Background(), types.NamespacedName{Name: "redisdb", Namespace: testNamespace}, restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Spec.Replicas == nil || *restored.Spec.Replicas != replicas {
+		t.Errorf("restored replicas = %v, want %d", restored.Spec.Replicas, replicas)
+	}
+	if deleted, err = adminService.GetDeletedDatabases(context.Background()); err != nil || len(deleted) != 0 {
+		t.Errorf("GetDeletedDatabases() = %v, %v, want no deleted databases after the restore", deleted, err)
+	}
+}
+
+func TestPurgeDeletedDatabases(t *testing.T) {
+	expiredSecret, expired := deletedDatabase("expired", time.Now().Add(-time.Minute))
+	keptSecret, kept := deletedDatabase("kept", time.Now().Add(time.Hour))
+	adminService := newTestAdministrationService(t, expiredSecret, expired, keptSecret, kept)
+
+	adminService.purgeDeletedDatabases(context.Background())
+
+	deleted, err := adminService.GetDeletedDatabases(context.Background())
+	if err != nil || len(deleted) != 1 || deleted[0].Name != "kept" {
+		t.Errorf("GetDeletedDatabases() = %v, %v, want only the database within the retention", deleted, err)
+	}
+}
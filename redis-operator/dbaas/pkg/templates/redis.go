@@ -9,6 +9,7 @@ import (
 	"github.com/Netcracker/qubership-redis/redis-operator/dbaas/pkg/core"
 	v1 "k8s.io/api/apps/v1"
 	v13 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -25,8 +26,25 @@ const (
 	AppTechnology        = "app.kubernetes.io/technology"
 	AppPartOf            = "app.kubernetes.io/part-of"
 	DeploymentSessionId  = "deployment.netcracker.com/sessionId"
+
+	// classifier of the logical database, set at creation and used by the monitoring agent as metric tags
+	ClassifierNamespace        = "dbaas.netcracker.com/namespace"
+	ClassifierMicroserviceName = "dbaas.netcracker.com/microservice-name"
+	// name of the logical database on all its objects, the orphan collector finds the objects left without the database by it
+	LogicalDatabase = "dbaas.netcracker.com/logical-database"
+	// set on the objects of a dropped logical database which are kept until the soft delete retention expires
+	PendingDeletion = "dbaas.netcracker.com/pending-deletion"
 )
 
+var ClassifierLabels = []string{ClassifierNamespace, ClassifierMicroserviceName}
+
+var imageVersionRegexp = regexp.MustCompile(`([0-9]+\.[0-9]+\.[0-9]+)`)
+
+// ImageVersion is the version in the image tag, the pod template is labeled with it
+func ImageVersion(image string) string {
+	return imageVersionRegexp.FindString(image)
+}
+
 func GetRedisDeploymentTemplate(
 	name string,
 	namespace string,
@@ -56,17 +74,13 @@ func GetRedisDeploymentTemplate(
 		FailureThreshold:    10,
 	}
 
-	reg, err := regexp.Compile(`([0-9]+\.[0-9]+\.[0-9]+)`)
-	if err != nil {
-		panic(err)
-	}
 	labels := map[string]string{
 		constants.Name: name,
 		constants.App:  name,
 		label:          label,
 		AppName:        name,
 		AppInstance:    fmt.Sprintf("redis-%s", namespace),
-		AppVersion:     reg.FindString(image),
+		AppVersion:     ImageVersion(image),
 		AppComponent:   "operator",
 		AppPartOf:      partOf,
 		AppManagedBy:   managedBy,
@@ -91,7 +105,7 @@ func GetRedisDeploymentTemplate(
 			},
 		},
 		{
-			Name: name + "-data",
+			Name: DataVolumeName(name),
 			VolumeSource: v13.VolumeSource{
 				EmptyDir: &v13.EmptyDirVolumeSource{
 					Medium: "",
@@ -106,7 +120,7 @@ func GetRedisDeploymentTemplate(
 			Name:      "config",
 		},
 		{
-			Name:      name + "-data",
+			Name:      DataVolumeName(name),
 			MountPath: "/var/lib/redis/data",
 		},
 	}
@@ -270,3 +284,53 @@ func GetRedisConfigTemplate(name string, namespace string, config string) *v13.C
 		},
 	}
 }
+
+// DataVolumeName is the name of the Redis data volume and of its claim
+func DataVolumeName(name string) string {
+	return name + "-data"
+}
+
+func GetRedisDataClaimTemplate(name string, namespace string, storageClass string, size resource.Quantity) *v13.PersistentVolumeClaim {
+	claim := &v13.PersistentVolumeClaim{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      DataVolumeName(name),
+			Namespace: namespace,
+			Labels: map[string]string{
+				constants.Name: name,
+			},
+		},
+		Spec: v13.PersistentVolumeClaimSpec{
+			AccessModes: []v13.PersistentVolumeAccessMode{v13.ReadWriteOnce},
+			Resources: v13.VolumeResourceRequirements{
+				Requests: v13.ResourceList{v13.ResourceStorage: size},
+			},
+		},
+	}
+	if storageClass != "" {
+		claim.Spec.StorageClassName = &storageClass
+	}
+	return claim
+}
+
+// SetDataVolumeClaim mounts the claim as the Redis data volume instead of emptyDir.
+// The pod is recreated on update, because the claim can't be attached to two pods on different nodes.
+func SetDataVolumeClaim(deployment *v1.Deployment, claimName string) {
+	for i, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == DataVolumeName(deployment.Name) {
+			deployment.Spec.Template.Spec.Volumes[i].VolumeSource = v13.VolumeSource{
+				PersistentVolumeClaim: &v13.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+			}
+		}
+	}
+	deployment.Spec.Strategy = v1.DeploymentStrategy{Type: v1.RecreateDeploymentStrategyType}
+}
+
+// DataVolumeClaim returns the claim of the Redis data volume, it is empty if the data is kept in emptyDir.
+func DataVolumeClaim(deployment *v1.Deployment) string {
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == DataVolumeName(deployment.Name) && volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
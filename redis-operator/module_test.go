@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"testing"
 
 	"github.com/Netcracker/qubership-dbaas-adapter-core/pkg/dao"
@@ -347,8 +346,12 @@ func Test_FullFeaturedConfigV2(t *testing.T) {
 	redisClient.On("Ping").Return("PONG", nil)
 	redisClient.On("Set", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	redisClient.On("Addr").Return("")
+	redisClient.On("Info", "server").Return("# Server\r\nredis_version:7.2.4\r\n", nil)
 	redisClient.On("Close").Return(nil)
-	dbAdmin := adapter.PrepareAdminService(spec, redisClient, fake.NewFakeClient(GetRuntimeObjects(nameSpace)...), &runtime.Scheme{}, logger, nil, nameSpace, apiVersion)
+	dbAdmin, err := adapter.PrepareAdminService(spec, redisClient, fake.NewFakeClient(GetRuntimeObjects(nameSpace)...), &runtime.Scheme{}, logger, nil, nameSpace, apiVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	appCredentials := coreTest.AppCredentials{
 		AppName:           coreTest.Simplstr(),
@@ -365,7 +368,7 @@ func Test_FullFeaturedConfigV2(t *testing.T) {
 	defer aggregatorServer.Close()
 	aggAddress := aggregatorServer.URL
 
-	dbaasClient, err := dbaas.NewDbaasClient(aggAddress, &dao.BasicAuth{appCredentials.AggregatorApiUser, appCredentials.AggregatorApiPass}, nil)
+	dbaasClient, err := dbaas.NewDbaasClient(aggAddress, &dao.BasicAuth{Username: appCredentials.AggregatorApiUser, Password: appCredentials.AggregatorApiPass}, nil)
 	if err != nil {
 		assert.Fail(t, "Failed to create Dbaas Client", err)
 	}